@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+)
+
+const (
+	// APIKeyHeader é o cabeçalho em que integrações enviam a chave de API
+	APIKeyHeader = "X-API-Key"
+	// apiKeyPrefix identifica visualmente as chaves do Mais Criança (ex.: em scanners de segredos)
+	apiKeyPrefix = "mck_"
+)
+
+// GenerateAPIKey gera uma chave de API no formato mck_<id>_<segredo>.
+// Retorna a chave para entregar uma única vez, o prefixo público (mck_<id>) para
+// identificá-la em listagens e o hash que deve ser armazenado.
+func GenerateAPIKey() (string, string, string, error) {
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", "", err
+	}
+	secret, _, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", "", err
+	}
+
+	prefix := apiKeyPrefix + hex.EncodeToString(id)
+	key := prefix + "_" + secret
+	return key, prefix, HashToken(key), nil
+}
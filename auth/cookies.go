@@ -0,0 +1,15 @@
+package auth
+
+// Nomes dos cookies e do cabeçalho usados no modo de sessão por cookie
+const (
+	// AccessTokenCookie guarda o token de acesso (HttpOnly)
+	AccessTokenCookie = "mc_access"
+	// RefreshTokenCookie guarda o token de atualização (HttpOnly, enviado só para /api/auth)
+	RefreshTokenCookie = "mc_refresh"
+	// CSRFCookie guarda o token CSRF que o cliente deve repetir em CSRFHeader (double-submit)
+	CSRFCookie = "mc_csrf"
+	// OIDCStateCookie liga o login social ao navegador que o iniciou (HttpOnly, hash do state)
+	OIDCStateCookie = "mc_oidc_state"
+	// CSRFHeader é o cabeçalho em que o cliente envia o token CSRF
+	CSRFHeader = "X-CSRF-Token"
+)
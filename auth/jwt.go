@@ -8,31 +8,80 @@ import (
 	"github.com/WBianchi/maiscrianca/configs"
 	"github.com/WBianchi/maiscrianca/models"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // JWTClaims representa os claims do token JWT
 type JWTClaims struct {
-	UserID string      `json:"userId"`
-	Role   models.Role `json:"role"`
+	UserID    string      `json:"userId"`
+	Role      models.Role `json:"role"`
+	EspacoID  string      `json:"espacoId,omitempty"`
+	SessionID string      `json:"sid,omitempty"`
+	// ChildProfileID identifica o perfil infantil selecionado; leitura e favoritos são registrados para ele
+	ChildProfileID string `json:"childProfileId,omitempty"`
+	// Purpose identifica tokens de uso restrito (ex.: desafio de 2FA); tokens de acesso não o definem
+	Purpose string `json:"purpose,omitempty"`
+	// Act identifica o administrador que age em nome do usuário em tokens de personificação (RFC 8693)
+	Act *ActorClaim `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// ActorClaim representa o claim act: quem de fato está agindo com o token
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// ImpersonatorID retorna o ID do administrador que personifica o usuário, ou vazio
+func (c *JWTClaims) ImpersonatorID() string {
+	if c.Act == nil {
+		return ""
+	}
+	return c.Act.Subject
+}
+
 // GenerateToken gera um novo token JWT para o usuário
 func GenerateToken(user *models.User, config *configs.Config) (string, error) {
-	expirationTime := time.Now().Add(time.Hour * config.JWTExpirationHours)
-	
-	claims := &JWTClaims{
+	return SignClaims(NewClaims(user), config)
+}
+
+// NewClaims monta os claims básicos do usuário, que podem ser complementados antes da assinatura
+func NewClaims(user *models.User) *JWTClaims {
+	return &JWTClaims{
 		UserID: user.ID,
 		Role:   user.Role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Subject:   user.Email,
+			Subject: user.Email,
 		},
 	}
-	
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(config.JWTSecret))
+}
+
+// PurposeMFA identifica o token de desafio emitido entre a senha e o código de 2FA
+const PurposeMFA = "mfa"
+
+// SignClaims define o identificador (jti) e as datas de emissão e expiração e assina os claims
+func SignClaims(claims *JWTClaims, config *configs.Config) (string, error) {
+	return SignClaimsWithTTL(claims, config, config.AccessTokenTTL)
+}
+
+// SignClaimsWithTTL assina os claims com uma validade específica
+func SignClaimsWithTTL(claims *JWTClaims, config *configs.Config, ttl time.Duration) (string, error) {
+	expirationTime := time.Now().Add(ttl)
+	claims.ID = uuid.New().String()
+	claims.ExpiresAt = jwt.NewNumericDate(expirationTime)
+	claims.IssuedAt = jwt.NewNumericDate(time.Now())
+
+	// Com chaves carregadas por LoadKeys, assina com a chave ativa e identifica-a no cabeçalho kid
+	var token *jwt.Token
+	var signingKey interface{}
+	if keys != nil {
+		token = jwt.NewWithClaims(keys.signingMethod, claims)
+		token.Header["kid"] = keys.signingKID
+		signingKey = keys.signingKey
+	} else {
+		token = jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		signingKey = []byte(config.JWTSecret)
+	}
+	tokenString, err := token.SignedString(signingKey)
 	
 	if err != nil {
 		return "", err
@@ -46,10 +95,7 @@ func ValidateToken(tokenString string, config *configs.Config) (*JWTClaims, erro
 	claims := &JWTClaims{}
 	
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
-		}
-		return []byte(config.JWTSecret), nil
+		return verificationKeyFor(token, config)
 	})
 	
 	if err != nil {
@@ -62,3 +108,29 @@ func ValidateToken(tokenString string, config *configs.Config) (*JWTClaims, erro
 	
 	return claims, nil
 }
+
+
+// verificationKeyFor escolhe a chave de verificação pelo kid do cabeçalho, exigindo que o
+// algoritmo do token seja o da chave. HS256 só é aceito sem chaves assimétricas ou com JWT_ACCEPT_HS256.
+func verificationKeyFor(token *jwt.Token, config *configs.Config) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if token.Method != jwt.SigningMethodHS256 || (keys != nil && !config.JWTAcceptHS256) {
+			return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+		}
+		return []byte(config.JWTSecret), nil
+	}
+
+	if keys == nil {
+		return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := keys.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("chave de assinatura desconhecida: %q", kid)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("método de assinatura inesperado: %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits é o tamanho mínimo aceito para chaves RSA
+const minRSAKeyBits = 2048
+
+// verificationKey é uma chave pública aceita na validação de tokens
+type verificationKey struct {
+	kid    string
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// keySet reúne a chave de assinatura ativa e todas as chaves de verificação
+type keySet struct {
+	signingKID    string
+	signingMethod jwt.SigningMethod
+	signingKey    crypto.PrivateKey
+	verification  map[string]verificationKey
+}
+
+// keys é o conjunto carregado por LoadKeys; nil mantém a assinatura HS256 com JWTSecret
+var keys *keySet
+
+// LoadKeys carrega as chaves PEM de config.JWTKeysDir.
+// Arquivos <kid>.pem com chave privada podem assinar e verificar; arquivos <kid>.pub.pem
+// só verificam, o que permite aposentar uma chave sem invalidar os tokens já emitidos.
+// A chave de assinatura é a de config.JWTActiveKID, ou a única chave privada do diretório.
+func LoadKeys(config *configs.Config) error {
+	if config.JWTKeysDir == "" {
+		keys = nil
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(config.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return err
+	}
+
+	set := &keySet{verification: map[string]verificationKey{}}
+	private := map[string]crypto.PrivateKey{}
+	for _, file := range files {
+		kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(file), ".pem"), ".pub")
+
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		privateKey, publicKey, err := parsePEMKey(data)
+		if err != nil {
+			return fmt.Errorf("chave %s: %w", file, err)
+		}
+		method, err := signingMethodFor(publicKey)
+		if err != nil {
+			return fmt.Errorf("chave %s: %w", file, err)
+		}
+
+		if _, exists := set.verification[kid]; exists {
+			return fmt.Errorf("kid duplicado nas chaves JWT: %s", kid)
+		}
+		set.verification[kid] = verificationKey{kid: kid, method: method, public: publicKey}
+		if privateKey != nil {
+			private[kid] = privateKey
+		}
+	}
+
+	set.signingKID = config.JWTActiveKID
+	if set.signingKID == "" {
+		if len(private) != 1 {
+			return errors.New("defina JWT_ACTIVE_KID: é preciso exatamente uma chave privada ativa")
+		}
+		for kid := range private {
+			set.signingKID = kid
+		}
+	}
+
+	signingKey, ok := private[set.signingKID]
+	if !ok {
+		return fmt.Errorf("chave privada não encontrada para JWT_ACTIVE_KID=%s", set.signingKID)
+	}
+	set.signingKey = signingKey
+	set.signingMethod = set.verification[set.signingKID].method
+
+	keys = set
+	return nil
+}
+
+// parsePEMKey lê uma chave privada (PKCS#8 ou PKCS#1) ou pública (PKIX) em PEM
+func parsePEMKey(data []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("arquivo PEM inválido")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, &k.PublicKey, nil
+		case ed25519.PrivateKey:
+			return k, k.Public(), nil
+		}
+		return nil, nil, errors.New("tipo de chave não suportado")
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, key, nil
+	}
+
+	return nil, nil, fmt.Errorf("bloco PEM não suportado: %s", block.Type)
+}
+
+// signingMethodFor escolhe o algoritmo pelo tipo da chave: RS256 para RSA e EdDSA para Ed25519
+func signingMethodFor(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("chave RSA menor que %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	}
+	return nil, errors.New("tipo de chave não suportado: use RSA ou Ed25519")
+}
+
+// JWK representa uma chave pública no formato JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS retorna as chaves públicas de verificação ordenadas por kid.
+// Com assinatura HS256 a lista é vazia, pois o segredo não pode ser publicado.
+func JWKS() []JWK {
+	jwks := []JWK{}
+	if keys == nil {
+		return jwks
+	}
+
+	for _, key := range keys.verification {
+		jwk := JWK{Kid: key.kid, Use: "sig", Alg: key.method.Alg()}
+		switch k := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(k.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(k)
+		}
+		jwks = append(jwks, jwk)
+	}
+
+	sort.Slice(jwks, func(i, j int) bool { return jwks[i].Kid < jwks[j].Kid })
+	return jwks
+}
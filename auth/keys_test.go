@@ -0,0 +1,302 @@
+package auth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testKeys gera uma chave RSA e uma Ed25519 uma única vez para todos os testes do pacote
+var testKeys = struct {
+	rsa     *rsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}{}
+
+func init() {
+	var err error
+	if testKeys.rsa, err = rsa.GenerateKey(rand.Reader, 2048); err != nil {
+		panic(err)
+	}
+	if _, testKeys.ed25519, err = ed25519.GenerateKey(rand.Reader); err != nil {
+		panic(err)
+	}
+}
+
+// writePrivateKey grava a chave privada em PKCS#8 como <kid>.pem
+func writePrivateKey(t *testing.T, dir string, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, kid+".pem"), "PRIVATE KEY", der)
+}
+
+// writePublicKey grava a chave pública em PKIX como <kid>.pub.pem
+func writePublicKey(t *testing.T, dir string, kid string, key interface{}) {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writePEM(t, filepath.Join(dir, kid+".pub.pem"), "PUBLIC KEY", der)
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// loadTestKeys carrega as chaves do diretório e restaura a assinatura HS256 ao fim do teste
+func loadTestKeys(t *testing.T, config *configs.Config) error {
+	t.Helper()
+	t.Cleanup(func() { keys = nil })
+	return LoadKeys(config)
+}
+
+// signWith assina claims de acesso com o método, a chave e o kid informados
+func signWith(t *testing.T, method jwt.SigningMethod, key interface{}, kid string) string {
+	t.Helper()
+	claims := NewClaims(&models.User{ID: "user-1", Email: "user@example.com", Role: models.CLIENT})
+	claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestLoadKeysActiveKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, dir string)
+		activeKID string
+		wantKID   string
+		wantAlg   string
+		wantErr   bool
+	}{
+		{
+			name: "única chave privada RSA",
+			setup: func(t *testing.T, dir string) {
+				writePrivateKey(t, dir, "rsa-1", testKeys.rsa)
+			},
+			wantKID: "rsa-1",
+			wantAlg: "RS256",
+		},
+		{
+			name: "única chave privada Ed25519 e uma pública aposentada",
+			setup: func(t *testing.T, dir string) {
+				writePrivateKey(t, dir, "ed-2", testKeys.ed25519)
+				writePublicKey(t, dir, "rsa-1", &testKeys.rsa.PublicKey)
+			},
+			wantKID: "ed-2",
+			wantAlg: "EdDSA",
+		},
+		{
+			name: "duas chaves privadas com JWT_ACTIVE_KID",
+			setup: func(t *testing.T, dir string) {
+				writePrivateKey(t, dir, "rsa-1", testKeys.rsa)
+				writePrivateKey(t, dir, "ed-2", testKeys.ed25519)
+			},
+			activeKID: "rsa-1",
+			wantKID:   "rsa-1",
+			wantAlg:   "RS256",
+		},
+		{
+			name: "duas chaves privadas sem JWT_ACTIVE_KID",
+			setup: func(t *testing.T, dir string) {
+				writePrivateKey(t, dir, "rsa-1", testKeys.rsa)
+				writePrivateKey(t, dir, "ed-2", testKeys.ed25519)
+			},
+			wantErr: true,
+		},
+		{
+			name: "JWT_ACTIVE_KID de uma chave só pública",
+			setup: func(t *testing.T, dir string) {
+				writePrivateKey(t, dir, "ed-2", testKeys.ed25519)
+				writePublicKey(t, dir, "rsa-1", &testKeys.rsa.PublicKey)
+			},
+			activeKID: "rsa-1",
+			wantErr:   true,
+		},
+		{
+			name: "kid duplicado entre privada e pública",
+			setup: func(t *testing.T, dir string) {
+				writePrivateKey(t, dir, "rsa-1", testKeys.rsa)
+				writePublicKey(t, dir, "rsa-1", &testKeys.rsa.PublicKey)
+			},
+			wantErr: true,
+		},
+		{
+			name: "arquivo PEM inválido",
+			setup: func(t *testing.T, dir string) {
+				if err := os.WriteFile(filepath.Join(dir, "bad.pem"), []byte("não é PEM"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.setup(t, dir)
+
+			err := loadTestKeys(t, &configs.Config{JWTKeysDir: dir, JWTActiveKID: tt.activeKID})
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadKeys aceitou a configuração inválida")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadKeys: %v", err)
+			}
+			if keys.signingKID != tt.wantKID || keys.signingMethod.Alg() != tt.wantAlg {
+				t.Errorf("chave ativa %s/%s, esperado %s/%s", keys.signingKID, keys.signingMethod.Alg(), tt.wantKID, tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestSigningMethodFor(t *testing.T) {
+	small, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		key     interface{}
+		wantAlg string
+	}{
+		{"RSA 2048", &testKeys.rsa.PublicKey, "RS256"},
+		{"Ed25519", testKeys.ed25519.Public(), "EdDSA"},
+		{"RSA abaixo do mínimo", &small.PublicKey, ""},
+		{"tipo não suportado", []byte("segredo"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, err := signingMethodFor(tt.key)
+			if tt.wantAlg == "" {
+				if err == nil {
+					t.Fatalf("signingMethodFor aceitou a chave com %s", method.Alg())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if method.Alg() != tt.wantAlg {
+				t.Errorf("algoritmo %s, esperado %s", method.Alg(), tt.wantAlg)
+			}
+		})
+	}
+}
+
+func TestValidateTokenKeyMatching(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "ed-2", testKeys.ed25519)
+	writePublicKey(t, dir, "rsa-1", &testKeys.rsa.PublicKey)
+	config := &configs.Config{JWTKeysDir: dir, JWTSecret: "segredo-de-teste"}
+	if err := loadTestKeys(t, config); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		acceptHS256 bool
+		valid       bool
+	}{
+		{"EdDSA com a chave ativa", signWith(t, jwt.SigningMethodEdDSA, testKeys.ed25519, "ed-2"), false, true},
+		{"RS256 com a chave aposentada", signWith(t, jwt.SigningMethodRS256, testKeys.rsa, "rsa-1"), false, true},
+		{"RS256 com o kid da chave Ed25519", signWith(t, jwt.SigningMethodRS256, testKeys.rsa, "ed-2"), false, false},
+		{"EdDSA com o kid da chave RSA", signWith(t, jwt.SigningMethodEdDSA, testKeys.ed25519, "rsa-1"), false, false},
+		{"kid desconhecido", signWith(t, jwt.SigningMethodEdDSA, testKeys.ed25519, "outro"), false, false},
+		{"sem kid", signWith(t, jwt.SigningMethodEdDSA, testKeys.ed25519, ""), false, false},
+		{"HS256 sem JWT_ACCEPT_HS256", signWith(t, jwt.SigningMethodHS256, []byte(config.JWTSecret), ""), false, false},
+		{"HS256 com JWT_ACCEPT_HS256", signWith(t, jwt.SigningMethodHS256, []byte(config.JWTSecret), ""), true, true},
+		{"HS384 com JWT_ACCEPT_HS256", signWith(t, jwt.SigningMethodHS384, []byte(config.JWTSecret), ""), true, false},
+		{"HS256 com outro segredo", signWith(t, jwt.SigningMethodHS256, []byte("outro"), ""), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.JWTAcceptHS256 = tt.acceptHS256
+			claims, err := ValidateToken(tt.token, config)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("token recusado: %v", err)
+				}
+				if claims.UserID != "user-1" {
+					t.Errorf("userId %q, esperado user-1", claims.UserID)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("token aceito")
+			}
+		})
+	}
+}
+
+func TestSignClaimsWithKeys(t *testing.T) {
+	dir := t.TempDir()
+	writePrivateKey(t, dir, "rsa-1", testKeys.rsa)
+	config := &configs.Config{JWTKeysDir: dir, AccessTokenTTL: 15 * time.Minute}
+	if err := loadTestKeys(t, config); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := GenerateToken(&models.User{ID: "user-1", Email: "user@example.com", Role: models.ADMIN}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, _, err := jwt.NewParser().ParseUnverified(signed, &JWTClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Method.Alg() != "RS256" || token.Header["kid"] != "rsa-1" {
+		t.Errorf("cabeçalho alg=%v kid=%v, esperado RS256 e rsa-1", token.Method.Alg(), token.Header["kid"])
+	}
+
+	claims, err := ValidateToken(signed, config)
+	if err != nil {
+		t.Fatalf("token emitido recusado: %v", err)
+	}
+	if claims.ID == "" || claims.Role != models.ADMIN {
+		t.Errorf("claims jti=%q role=%q", claims.ID, claims.Role)
+	}
+
+	jwks := JWKS()
+	if len(jwks) != 1 || jwks[0].Kid != "rsa-1" || jwks[0].Kty != "RSA" || jwks[0].Alg != "RS256" || jwks[0].N == "" {
+		t.Errorf("JWKS inesperado: %+v", jwks)
+	}
+}
+
+func TestJWKSWithHMAC(t *testing.T) {
+	keys = nil
+	if jwks := JWKS(); len(jwks) != 0 {
+		t.Errorf("JWKS com HS256 deveria ser vazio, veio %+v", jwks)
+	}
+}
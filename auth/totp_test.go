@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret é o segredo ASCII "12345678901234567890" dos vetores de teste da RFC 6238, em base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPVectors(t *testing.T) {
+	// Códigos SHA-1 da RFC 6238 (apêndice B), com os 6 últimos dígitos dos valores de 8 dígitos
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("ValidateTOTP(%d, %s) recusou o código da RFC", tt.unix, tt.code)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP(%d, %s) step = %d, esperado %d", tt.unix, tt.code, step, want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	current := now.Unix() / totpPeriod
+
+	tests := []struct {
+		name   string
+		offset int64
+		ok     bool
+	}{
+		{"intervalo atual", 0, true},
+		{"intervalo anterior", -1, true},
+		{"intervalo seguinte", 1, true},
+		{"dois intervalos antes", -2, false},
+		{"dois intervalos depois", 2, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code := totpCode(key, uint64(current+tt.offset))
+			step, ok := ValidateTOTP(rfc6238Secret, code, now)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, esperado %v", ok, tt.ok)
+			}
+			if ok && step != current+tt.offset {
+				t.Errorf("step = %d, esperado %d", step, current+tt.offset)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPReplayStep(t *testing.T) {
+	// O step retornado identifica o código: reenviar o mesmo código, mesmo no intervalo seguinte,
+	// devolve o mesmo step, que o chamador recusa por não ser maior que o último usado
+	key, err := totpEncoding.DecodeString(rfc6238Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	code := totpCode(key, uint64(now.Unix()/totpPeriod))
+
+	first, ok := ValidateTOTP(rfc6238Secret, code, now)
+	if !ok {
+		t.Fatal("código atual recusado")
+	}
+	again, ok := ValidateTOTP(rfc6238Secret, code, now.Add(totpPeriod*time.Second))
+	if !ok {
+		t.Fatal("código do intervalo anterior recusado dentro da tolerância")
+	}
+	if again != first {
+		t.Errorf("step do mesmo código mudou: %d e %d", first, again)
+	}
+}
+
+func TestValidateTOTPInput(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		ok     bool
+	}{
+		{"espaços no código", rfc6238Secret, " 287 082 ", true},
+		{"segredo em minúsculas", strings.ToLower(rfc6238Secret), "287082", true},
+		{"código curto", rfc6238Secret, "28708", false},
+		{"código longo", rfc6238Secret, "2870820", false},
+		{"código errado", rfc6238Secret, "287083", false},
+		{"código vazio", rfc6238Secret, "", false},
+		{"segredo inválido", "não é base32", "287082", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok != tt.ok {
+				t.Errorf("ok = %v, esperado %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatalf("segredo não é base32: %v", err)
+	}
+	if len(key) != 20 {
+		t.Errorf("segredo com %d bytes, esperado 20", len(key))
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, hashes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 || len(hashes) != 10 {
+		t.Fatalf("gerados %d códigos e %d hashes, esperado 10", len(codes), len(hashes))
+	}
+
+	seen := map[string]bool{}
+	for i, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("código %q fora do formato xxxxx-xxxxx", code)
+		}
+		if seen[code] {
+			t.Errorf("código repetido: %q", code)
+		}
+		seen[code] = true
+
+		// O código digitado sem hífen, em maiúsculas ou com espaços corresponde ao mesmo hash
+		for _, typed := range []string{code, strings.ReplaceAll(code, "-", ""), strings.ToUpper(code), " " + code[:5] + " " + code[6:] + " "} {
+			if HashRecoveryCode(typed) != hashes[i] {
+				t.Errorf("HashRecoveryCode(%q) não corresponde ao hash de %q", typed, code)
+			}
+		}
+	}
+}
@@ -0,0 +1,149 @@
+// Package avatar prepara as imagens de perfil enviadas pelos usuários: confere o tipo real do
+// arquivo pelo conteúdo, descarta os metadados (EXIF, GPS) e gera miniaturas quadradas em JPEG.
+package avatar
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/draw"
+	_ "image/gif" // decodificador GIF
+	"image/jpeg"
+	_ "image/png" // decodificador PNG
+	"net/http"
+)
+
+const (
+	// MaxUploadSize limita o tamanho do arquivo enviado, dentro do limite de corpo do servidor
+	MaxUploadSize = 4 << 20
+	// ContentType é o tipo das miniaturas geradas
+	ContentType = "image/jpeg"
+	// DefaultSize é o lado da miniatura usada como avatar do perfil
+	DefaultSize = 256
+
+	// maxPixels e maxSide recusam imagens que ocupariam memória demais ao decodificar
+	maxPixels = 40_000_000
+	maxSide   = 10_000
+	// minSide é o menor lado aceito, para que as miniaturas não fiquem borradas
+	minSide     = 128
+	jpegQuality = 85
+)
+
+// Sizes são os lados, em pixels, das miniaturas geradas, da maior para a menor
+var Sizes = []int{512, 256, 128}
+
+var (
+	// ErrUnsupportedType indica um arquivo que não é JPEG, PNG nem GIF
+	ErrUnsupportedType = errors.New("formato de imagem não suportado: use JPEG, PNG ou GIF")
+	// ErrInvalidImage indica um arquivo com o tipo certo que não pôde ser decodificado
+	ErrInvalidImage = errors.New("imagem inválida ou corrompida")
+	// ErrTooLarge indica uma imagem com dimensões acima do limite
+	ErrTooLarge = errors.New("imagem grande demais: o limite é de 40 megapixels")
+	// ErrTooSmall indica uma imagem com um lado menor que o mínimo
+	ErrTooSmall = errors.New("imagem pequena demais: envie uma imagem com pelo menos 128 pixels de lado")
+)
+
+// Thumbnail é uma miniatura quadrada em JPEG. Size é o tamanho nominal (um de Sizes); a imagem
+// só é menor que ele quando o original não tem resolução suficiente, pois nunca é ampliada.
+type Thumbnail struct {
+	Size int
+	Data []byte
+}
+
+// Process valida a imagem enviada e gera as miniaturas de Sizes, recortadas ao centro.
+// A orientação EXIF é aplicada aos pixels e a imagem é recodificada, de modo que nenhum
+// metadado do arquivo original chega às miniaturas. Transparências ficam sobre fundo branco.
+func Process(data []byte) ([]Thumbnail, error) {
+	switch http.DetectContentType(data) {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, ErrUnsupportedType
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if config.Width > maxSide || config.Height > maxSide || config.Width*config.Height > maxPixels {
+		return nil, ErrTooLarge
+	}
+	if config.Width < minSide || config.Height < minSide {
+		return nil, ErrTooSmall
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	orientation := 1
+	if format == "jpeg" {
+		orientation = exifOrientation(data)
+	}
+
+	square := cropSquare(img)
+	thumbnails := make([]Thumbnail, 0, len(Sizes))
+	for _, size := range Sizes {
+		thumb := orient(resize(square, size), orientation)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		thumbnails = append(thumbnails, Thumbnail{Size: size, Data: buf.Bytes()})
+	}
+
+	return thumbnails, nil
+}
+
+// cropSquare copia o maior quadrado central da imagem sobre um fundo branco
+func cropSquare(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	side := bounds.Dx()
+	if bounds.Dy() < side {
+		side = bounds.Dy()
+	}
+	origin := image.Pt(bounds.Min.X+(bounds.Dx()-side)/2, bounds.Min.Y+(bounds.Dy()-side)/2)
+
+	square := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(square, square.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(square, square.Bounds(), img, origin, draw.Over)
+	return square
+}
+
+// resize reduz a imagem quadrada para size pixels de lado pela média de cada bloco de pixels.
+// Imagens menores que size mantêm o tamanho original.
+func resize(src *image.RGBA, size int) *image.RGBA {
+	n := src.Bounds().Dx()
+	if n <= size {
+		return src
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for y := 0; y < size; y++ {
+		y0, y1 := y*n/size, (y+1)*n/size
+		for x := 0; x < size; x++ {
+			x0, x1 := x*n/size, (x+1)*n/size
+
+			var r, g, b, a int
+			for sy := y0; sy < y1; sy++ {
+				i := src.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[i])
+					g += int(src.Pix[i+1])
+					b += int(src.Pix[i+2])
+					a += int(src.Pix[i+3])
+					i += 4
+				}
+			}
+
+			count := (x1 - x0) * (y1 - y0)
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / count)
+			dst.Pix[j+1] = uint8(g / count)
+			dst.Pix[j+2] = uint8(b / count)
+			dst.Pix[j+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
@@ -0,0 +1,115 @@
+package avatar
+
+import (
+	"encoding/binary"
+	"image"
+)
+
+// exifOrientationTag é a tag EXIF que indica como a câmera estava girada
+const exifOrientationTag = 0x0112
+
+// exifOrientation lê a orientação EXIF (1 a 8) de um JPEG; sem EXIF ou com dados inválidos retorna 1
+func exifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	// Percorre os segmentos do cabeçalho até o APP1 com os dados EXIF
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			// Início dos dados da imagem: não há mais cabeçalhos
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return 1
+		}
+		segment := data[i+4 : i+2+length]
+		if marker == 0xE1 && len(segment) >= 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return tiffOrientation(segment[6:])
+		}
+		i += 2 + length
+	}
+	return 1
+}
+
+// tiffOrientation procura a tag de orientação no primeiro diretório (IFD0) do bloco TIFF do EXIF
+func tiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd < 8 || ifd+2 > len(tiff) {
+		return 1
+	}
+
+	count := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < count; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			if orientation := int(order.Uint16(tiff[entry+8:])); orientation >= 1 && orientation <= 8 {
+				return orientation
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// orient gira ou espelha a imagem conforme a orientação EXIF, para que ela apareça em pé
+// depois que os metadados forem descartados
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	if orientation >= 5 {
+		// Orientações 5 a 8 trocam largura e altura
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // espelhada na horizontal
+				dx, dy = w-1-x, y
+			case 3: // girada 180°
+				dx, dy = w-1-x, h-1-y
+			case 4: // espelhada na vertical
+				dx, dy = x, h-1-y
+			case 5: // transposta
+				dx, dy = y, x
+			case 6: // girada 90° no sentido horário
+				dx, dy = h-1-y, x
+			case 7: // transversa
+				dx, dy = h-1-y, w-1-x
+			case 8: // girada 90° no sentido anti-horário
+				dx, dy = y, w-1-x
+			}
+			dst.SetRGBA(dx, dy, src.RGBAAt(x, y))
+		}
+	}
+	return dst
+}
@@ -1,17 +1,66 @@
 package configs
 
 import (
+	"errors"
+	"fmt"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
+// defaultJWTSecret é o segredo usado apenas em desenvolvimento quando JWT_SECRET não é definido
+const defaultJWTSecret = "maiscrianca_secret_key"
+
+// OIDCProvider armazena a configuração de um provedor OpenID Connect (login social)
+type OIDCProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL é a página do frontend que recebe code e state e os envia ao backend
+	RedirectURL  string
+	Scopes       []string
+}
+
 // Config armazena todas as configurações da aplicação
 type Config struct {
+	Environment         string
 	JWTSecret           string
-	JWTExpirationHours  time.Duration
+	// Diretório com as chaves PEM de assinatura (<kid>.pem) e de verificação (<kid>.pub.pem)
+	JWTKeysDir          string
+	// kid da chave privada usada para assinar novos tokens
+	JWTActiveKID        string
+	// Aceita tokens HS256 assinados com JWTSecret mesmo com chaves assimétricas configuradas (migração)
+	JWTAcceptHS256      bool
+	AccessTokenTTL      time.Duration
+	RefreshTokenTTL     time.Duration
 	DatabaseURL         string
 	AllowedOrigins      string
 	Port                string
+	AppURL              string
+	MailDriver          string
+	MailFrom            string
+	MailOutboxDir       string
+	SMTPHost            string
+	SMTPPort            string
+	SMTPUsername        string
+	SMTPPassword        string
+	// Atributos dos cookies de sessão (modo cookie do login)
+	CookieDomain        string
+	CookieSecure        bool
+	CookieSameSite      string
+	// Provedores de login social habilitados, pelo nome usado nas rotas
+	OIDCProviders       map[string]*OIDCProvider
+	// Ações que exigem email verificado, como "purchase" e "download"
+	VerifiedEmailRequiredFor []string
+	// Consulta de senhas vazadas: "local", "api" ou vazio (desativada)
+	PasswordBreachCheck  string
+	// Arquivo com hashes SHA-1 de senhas vazadas; vazio usa a lista embutida de senhas comuns
+	PasswordBreachList   string
+	PasswordBreachAPIURL string
+	// Versão vigente dos termos de uso e da política de privacidade, gravada com cada aceite
+	TermsVersion string
 }
 
 // LoadConfig carrega as configurações do ambiente
@@ -23,14 +72,138 @@ func LoadConfig() *Config {
 
 	jwtSecret := os.Getenv("JWT_SECRET")
 	if jwtSecret == "" {
-		jwtSecret = "maiscrianca_secret_key" // Valor padrão, deve ser substituído em produção
+		jwtSecret = defaultJWTSecret // Recusado em produção por Validate
 	}
 
+	appURL := getEnv("APP_URL", "http://localhost:3000")
+
 	return &Config{
+		Environment:        getEnv("APP_ENV", "development"),
 		JWTSecret:          jwtSecret,
-		JWTExpirationHours: 24, // Token válido por 24 horas
+		JWTKeysDir:         os.Getenv("JWT_KEYS_DIR"),
+		JWTActiveKID:       os.Getenv("JWT_ACTIVE_KID"),
+		JWTAcceptHS256:     os.Getenv("JWT_ACCEPT_HS256") == "true",
+		AccessTokenTTL:     time.Duration(getEnvInt("ACCESS_TOKEN_TTL_MINUTES", 15)) * time.Minute,
+		RefreshTokenTTL:    time.Duration(getEnvInt("REFRESH_TOKEN_TTL_DAYS", 30)) * 24 * time.Hour,
 		DatabaseURL:        os.Getenv("DATABASE_URL"),
 		AllowedOrigins:     os.Getenv("ALLOWED_ORIGINS"),
 		Port:               port,
+		AppURL:             appURL,
+		MailDriver:         getEnv("MAIL_DRIVER", "outbox"),
+		MailFrom:           getEnv("MAIL_FROM", "Mais Criança <nao-responda@maiscrianca.com.br>"),
+		MailOutboxDir:      os.Getenv("MAIL_OUTBOX_DIR"),
+		SMTPHost:           os.Getenv("SMTP_HOST"),
+		SMTPPort:           getEnv("SMTP_PORT", "587"),
+		SMTPUsername:       os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:       os.Getenv("SMTP_PASSWORD"),
+		CookieDomain:       os.Getenv("COOKIE_DOMAIN"),
+		CookieSecure:       os.Getenv("COOKIE_SECURE") != "false",
+		CookieSameSite:     getEnv("COOKIE_SAMESITE", "Lax"),
+		OIDCProviders:      loadOIDCProviders(appURL),
+		VerifiedEmailRequiredFor: getEnvList("REQUIRE_VERIFIED_EMAIL_FOR", "purchase,download"),
+		PasswordBreachCheck:  os.Getenv("PASSWORD_BREACH_CHECK"),
+		PasswordBreachList:   os.Getenv("PASSWORD_BREACH_LIST"),
+		PasswordBreachAPIURL: getEnv("PASSWORD_BREACH_API_URL", "https://api.pwnedpasswords.com/range/"),
+		TermsVersion:         getEnv("TERMS_VERSION", "1"),
+	}
+}
+
+// IsProduction informa se a aplicação está rodando em produção (APP_ENV=production)
+func (c *Config) IsProduction() bool {
+	return c.Environment == "production"
+}
+
+// UsesHMAC informa se tokens HS256 assinados com JWTSecret são emitidos ou aceitos
+func (c *Config) UsesHMAC() bool {
+	return c.JWTKeysDir == "" || c.JWTAcceptHS256
+}
+
+// Validate recusa configurações inseguras para produção
+func (c *Config) Validate() error {
+	if c.IsProduction() && c.UsesHMAC() && c.JWTSecret == defaultJWTSecret {
+		return errors.New("JWT_SECRET padrão não é permitido em produção: defina JWT_SECRET ou configure JWT_KEYS_DIR")
+	}
+	if c.IsProduction() && !c.CookieSecure {
+		return errors.New("COOKIE_SECURE=false não é permitido em produção")
+	}
+	for name, provider := range c.OIDCProviders {
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			return fmt.Errorf("provedor OIDC %s sem OIDC_%s_ISSUER ou OIDC_%s_CLIENT_ID", name, strings.ToUpper(name), strings.ToUpper(name))
+		}
+	}
+	switch c.PasswordBreachCheck {
+	case "", "local", "api":
+	default:
+		return fmt.Errorf("PASSWORD_BREACH_CHECK inválido: %s (use local ou api)", c.PasswordBreachCheck)
+	}
+	// Navegadores recusam cookies SameSite=None sem o atributo Secure
+	if strings.EqualFold(c.CookieSameSite, "None") && !c.CookieSecure {
+		return errors.New("COOKIE_SAMESITE=None exige COOKIE_SECURE")
+	}
+	return nil
+}
+
+// RequiresVerifiedEmail informa se a política exige email verificado para a ação
+func (c *Config) RequiresVerifiedEmail(action string) bool {
+	for _, required := range c.VerifiedEmailRequiredFor {
+		if required == action {
+			return true
+		}
+	}
+	return false
+}
+
+// loadOIDCProviders lê os provedores listados em OIDC_PROVIDERS (ex.: "google,apple").
+// Cada provedor usa as variáveis OIDC_<NOME>_ISSUER, _CLIENT_ID, _CLIENT_SECRET, _REDIRECT_URL e _SCOPES.
+func loadOIDCProviders(appURL string) map[string]*OIDCProvider {
+	providers := map[string]*OIDCProvider{}
+	for _, name := range getEnvList("OIDC_PROVIDERS", "none") {
+		name = strings.ToLower(name)
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+
+		provider := &OIDCProvider{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  getEnv(prefix+"REDIRECT_URL", appURL+"/auth/callback/"+name),
+			Scopes:       getEnvList(prefix+"SCOPES", "openid,email,profile"),
+		}
+		providers[name] = provider
+	}
+	return providers
+}
+
+// getEnv lê uma variável de ambiente, usando o valor padrão quando ausente
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// getEnvInt lê uma variável de ambiente inteira, usando o valor padrão quando ausente ou inválida
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// getEnvList lê uma variável de ambiente com valores separados por vírgula.
+// Definida como "none", resulta em uma lista vazia.
+func getEnvList(key string, fallback string) []string {
+	value := getEnv(key, fallback)
+	if value == "none" {
+		return nil
+	}
+
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
 	}
+	return list
 }
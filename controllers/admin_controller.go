@@ -0,0 +1,776 @@
+package controllers
+
+import (
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultCodigoConviteHours define a validade padrão de um código de convite
+	defaultCodigoConviteHours = 72
+	// impersonationExpiration define a validade do token de personificação, que não pode ser renovado
+	impersonationExpiration = 15 * time.Minute
+	// defaultUsersPageSize e maxUsersPageSize definem a paginação da listagem de usuários
+	defaultUsersPageSize = 20
+	maxUsersPageSize     = 100
+	// userFilterDateLayout é o formato das datas aceitas nos filtros da listagem de usuários
+	userFilterDateLayout = "2006-01-02"
+)
+
+// AdminController gerencia operações administrativas sobre usuários
+type AdminController struct {
+	Config *configs.Config
+	// Auth envia os emails de redefinição de senha forçada
+	Auth *AuthController
+}
+
+// NewAdminController cria uma nova instância de AdminController
+func NewAdminController(config *configs.Config, authController *AuthController) *AdminController {
+	return &AdminController{
+		Config: config,
+		Auth:   authController,
+	}
+}
+
+// CreateCodigoConvite emite um código de uso único para cadastro de EMPLOYEE ou AFFILIATE
+func (c *AdminController) CreateCodigoConvite(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Role           models.Role `json:"role"`
+		ExpiresInHours int         `json:"expiresInHours"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos",
+		})
+	}
+
+	// ADMIN só é concedido pela troca de role; CLIENT não precisa de convite
+	if req.Role != models.EMPLOYEE && req.Role != models.AFFILIATE {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Códigos de convite só podem ser emitidos para EMPLOYEE ou AFFILIATE",
+		})
+	}
+	if req.ExpiresInHours <= 0 {
+		req.ExpiresInHours = defaultCodigoConviteHours
+	}
+
+	code, codeHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar código de convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar código de convite",
+		})
+	}
+
+	codigo := &models.CodigoConvite{
+		Role:        req.Role,
+		CreatedById: userId,
+		ExpiresAt:   time.Now().Add(time.Duration(req.ExpiresInHours) * time.Hour),
+	}
+	if err := repository.CreateCodigoConvite(codigo, codeHash); err != nil {
+		log.Printf("Erro ao criar código de convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar código de convite",
+		})
+	}
+
+	c.audit(ctx, models.AuditInviteCodeIssued, "", map[string]interface{}{
+		"codigoId":  codigo.ID,
+		"role":      codigo.Role,
+		"expiresAt": codigo.ExpiresAt,
+	})
+
+	// O código só é exibido nesta resposta; no banco fica apenas o hash
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"data":    codigo,
+		"code":    code,
+	})
+}
+
+// ListCodigosConvite retorna os códigos de convite ainda disponíveis
+func (c *AdminController) ListCodigosConvite(ctx *fiber.Ctx) error {
+	codigos, err := repository.GetCodigosConvitePendentes()
+	if err != nil {
+		log.Printf("Erro ao buscar códigos de convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar códigos de convite",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    codigos,
+	})
+}
+
+// RevokeCodigoConvite invalida um código de convite ainda não usado
+func (c *AdminController) RevokeCodigoConvite(ctx *fiber.Ctx) error {
+	id := ctx.Params("id")
+
+	if err := repository.RevokeCodigoConvite(id); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Código de convite pendente não encontrado",
+			})
+		}
+		log.Printf("Erro ao revogar código de convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao revogar código de convite",
+		})
+	}
+
+	c.audit(ctx, models.AuditInviteCodeRevoked, "", map[string]interface{}{
+		"codigoId": id,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Código de convite revogado com sucesso",
+	})
+}
+
+// ChangeUserRole altera a role global de um usuário e registra a alteração na auditoria
+func (c *AdminController) ChangeUserRole(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	targetId := ctx.Params("id")
+
+	var req struct {
+		Role   models.Role `json:"role"`
+		Reason string      `json:"reason"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || !req.Role.IsValid() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role inválida",
+		})
+	}
+
+	if targetId == adminId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não é possível alterar a própria role",
+		})
+	}
+
+	target, err := repository.GetUserById(targetId)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if target.DeletedAt != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+
+	if target.Role == req.Role {
+		return ctx.JSON(fiber.Map{
+			"success": true,
+			"message": "O usuário já possui esta role",
+		})
+	}
+
+	entry := &models.AuditLog{
+		ActorId:      adminId,
+		Action:       models.AuditUserRoleChanged,
+		TargetUserId: targetId,
+		Details: map[string]interface{}{
+			"from":   target.Role,
+			"to":     req.Role,
+			"reason": req.Reason,
+		},
+		IP: ctx.IP(),
+	}
+	if err := repository.UpdateUserRole(targetId, req.Role, entry); err != nil {
+		log.Printf("Erro ao alterar role do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao alterar role do usuário",
+		})
+	}
+
+	// Os tokens atuais carregam a role antiga; forçar novo login
+	if _, err := repository.RevokeUserSessions(targetId, ""); err != nil {
+		log.Printf("Erro ao encerrar sessões do usuário: %v", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Role do usuário alterada com sucesso",
+	})
+}
+
+// UnlockUser remove o bloqueio de login da conta do usuário
+func (c *AdminController) UnlockUser(ctx *fiber.Ctx) error {
+	targetId := ctx.Params("id")
+
+	target, err := repository.GetUserById(targetId)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if err := repository.ClearLoginFailures(accountThrottleKey(target.Email)); err != nil {
+		log.Printf("Erro ao desbloquear conta: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao desbloquear conta",
+		})
+	}
+
+	c.audit(ctx, models.AuditAccountUnlocked, targetId, nil)
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Conta desbloqueada com sucesso",
+	})
+}
+
+// RevokeUserSessions encerra todas as sessões de um usuário, forçando novo login em todos os dispositivos
+func (c *AdminController) RevokeUserSessions(ctx *fiber.Ctx) error {
+	targetId := ctx.Params("id")
+
+	if _, err := repository.GetUserById(targetId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Usuário não encontrado",
+			})
+		}
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	count, err := repository.RevokeUserSessions(targetId, "")
+	if err != nil {
+		log.Printf("Erro ao encerrar sessões do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao encerrar sessões do usuário",
+		})
+	}
+
+	c.audit(ctx, models.AuditSessionsRevoked, targetId, map[string]interface{}{
+		"sessions": count,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Sessões do usuário encerradas com sucesso",
+		"revoked": count,
+	})
+}
+
+// ImpersonateUser emite um token de curta duração para o administrador ver o sistema como o usuário.
+// O token leva o claim act com o ID do administrador, não tem token de atualização nem sessão,
+// e cada requisição feita com ele é registrada na auditoria.
+func (c *AdminController) ImpersonateUser(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	targetId := ctx.Params("id")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = ctx.BodyParser(&req)
+
+	if targetId == adminId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não é possível personificar a própria conta",
+		})
+	}
+
+	target, err := repository.GetUserById(targetId)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	// Personificar outro administrador não ajuda o suporte e só ampliaria o alcance do token
+	if target.Role == models.ADMIN {
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Não é possível personificar um administrador",
+		})
+	}
+	if !target.IsActive() {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Não é possível personificar uma conta suspensa ou excluída",
+		})
+	}
+
+	claims := auth.NewClaims(target)
+	claims.Act = &auth.ActorClaim{Subject: adminId}
+	token, err := auth.SignClaimsWithTTL(claims, c.Config, impersonationExpiration)
+	if err != nil {
+		log.Printf("Erro ao gerar token de personificação: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar token de personificação",
+		})
+	}
+
+	c.audit(ctx, models.AuditImpersonationStarted, targetId, map[string]interface{}{
+		"tokenId":   claims.ID,
+		"expiresAt": claims.ExpiresAt.Time,
+		"reason":    req.Reason,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success":        true,
+		"token":          token,
+		"expiresAt":      claims.ExpiresAt.Time,
+		"impersonatorId": adminId,
+		"user": fiber.Map{
+			"id":    target.ID,
+			"email": target.Email,
+			"name":  target.Name,
+			"role":  target.Role,
+		},
+		"redirectUrl": policy.LandingPage(target.Role),
+	})
+}
+
+// ListUsers retorna a lista paginada de usuários, com busca por nome ou email e filtros
+// por role, situação da conta (status) e data de cadastro (createdFrom e createdTo, inclusivos)
+func (c *AdminController) ListUsers(ctx *fiber.Ctx) error {
+	filter := models.UserFilter{
+		Search:   strings.TrimSpace(ctx.Query("q")),
+		Role:     models.Role(ctx.Query("role")),
+		Status:   ctx.Query("status"),
+		Page:     ctx.QueryInt("page", 1),
+		PageSize: ctx.QueryInt("pageSize", defaultUsersPageSize),
+	}
+
+	if filter.Role != "" && !filter.Role.IsValid() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role inválida",
+		})
+	}
+	switch filter.Status {
+	case "", models.UserStatusActive, models.UserStatusSuspended, models.UserStatusDeleted:
+	default:
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Situação inválida. Use active, suspended ou deleted",
+		})
+	}
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.PageSize < 1 || filter.PageSize > maxUsersPageSize {
+		filter.PageSize = defaultUsersPageSize
+	}
+
+	if value := ctx.Query("createdFrom"); value != "" {
+		from, err := time.Parse(userFilterDateLayout, value)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "createdFrom deve estar no formato AAAA-MM-DD",
+			})
+		}
+		filter.CreatedFrom = &from
+	}
+	if value := ctx.Query("createdTo"); value != "" {
+		to, err := time.Parse(userFilterDateLayout, value)
+		if err != nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "createdTo deve estar no formato AAAA-MM-DD",
+			})
+		}
+		// O dia informado entra inteiro no filtro
+		to = to.AddDate(0, 0, 1)
+		filter.CreatedTo = &to
+	}
+
+	users, total, err := repository.ListUsers(filter)
+	if err != nil {
+		log.Printf("Erro ao listar usuários: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao listar usuários",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    users,
+		"pagination": fiber.Map{
+			"page":       filter.Page,
+			"pageSize":   filter.PageSize,
+			"total":      total,
+			"totalPages": int(math.Ceil(float64(total) / float64(filter.PageSize))),
+		},
+	})
+}
+
+// GetUser retorna os dados do usuário com seus espaços, contas vinculadas, 2FA, sessões ativas
+// e bloqueio de login, reunindo o que o suporte precisa para atender a conta
+func (c *AdminController) GetUser(ctx *fiber.Ctx) error {
+	targetId := ctx.Params("id")
+
+	target, err := repository.GetUserById(targetId)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	espacos, err := repository.GetEspacosByUserId(targetId)
+	if err != nil {
+		log.Printf("Erro ao buscar espaços do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar detalhes do usuário",
+		})
+	}
+	identities, err := repository.GetUserIdentitiesByUserId(targetId)
+	if err != nil {
+		log.Printf("Erro ao buscar contas vinculadas do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar detalhes do usuário",
+		})
+	}
+	totp, err := repository.GetUserTOTP(targetId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar detalhes do usuário",
+		})
+	}
+	sessions, err := repository.GetActiveSessionsByUserId(targetId)
+	if err != nil {
+		log.Printf("Erro ao buscar sessões do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar detalhes do usuário",
+		})
+	}
+	lockedUntil, err := repository.GetLoginLock(accountThrottleKey(target.Email))
+	if err != nil {
+		log.Printf("Erro ao verificar bloqueio de login: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar detalhes do usuário",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"user":           target,
+			"espacos":        espacos,
+			"identities":     identities,
+			"mfaEnabled":     totp.EnabledAt != nil,
+			"activeSessions": len(sessions),
+			"lockedUntil":    lockedUntil,
+		},
+	})
+}
+
+// SuspendUser suspende a conta do usuário e encerra suas sessões. Enquanto suspensa,
+// a conta não entra por nenhum meio de login e suas chaves de API deixam de valer.
+func (c *AdminController) SuspendUser(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	targetId := ctx.Params("id")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = ctx.BodyParser(&req)
+
+	if targetId == adminId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não é possível suspender a própria conta",
+		})
+	}
+
+	target, err := repository.GetUserById(targetId)
+	if err == nil && target.DeletedAt != nil {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if target.SuspendedAt != nil {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A conta já está suspensa",
+		})
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	entry := &models.AuditLog{
+		ActorId:      adminId,
+		Action:       models.AuditUserSuspended,
+		TargetUserId: targetId,
+		Details: map[string]interface{}{
+			"reason": reason,
+		},
+		IP: ctx.IP(),
+	}
+	if err := repository.SuspendUser(targetId, reason, entry); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "A conta já está suspensa",
+			})
+		}
+		log.Printf("Erro ao suspender usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao suspender usuário",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Conta suspensa com sucesso",
+	})
+}
+
+// ReactivateUser remove a suspensão da conta do usuário
+func (c *AdminController) ReactivateUser(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	targetId := ctx.Params("id")
+
+	target, err := repository.GetUserById(targetId)
+	if err == nil && target.DeletedAt != nil {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if target.SuspendedAt == nil {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A conta não está suspensa",
+		})
+	}
+
+	entry := &models.AuditLog{
+		ActorId:      adminId,
+		Action:       models.AuditUserReactivated,
+		TargetUserId: targetId,
+		Details: map[string]interface{}{
+			"suspendedAt":     target.SuspendedAt,
+			"suspendedReason": target.SuspendedReason,
+		},
+		IP: ctx.IP(),
+	}
+	if err := repository.ReactivateUser(targetId, entry); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "A conta não está suspensa",
+			})
+		}
+		log.Printf("Erro ao reativar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao reativar usuário",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Conta reativada com sucesso",
+	})
+}
+
+// ForcePasswordReset encerra as sessões do usuário, bloqueia o login com a senha atual
+// e envia um link de redefinição para o email da conta
+func (c *AdminController) ForcePasswordReset(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	targetId := ctx.Params("id")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = ctx.BodyParser(&req)
+
+	if targetId == adminId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Use a troca de senha da própria conta",
+		})
+	}
+
+	target, err := repository.GetUserById(targetId)
+	if err == nil && target.DeletedAt != nil {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	entry := &models.AuditLog{
+		ActorId:      adminId,
+		Action:       models.AuditPasswordResetForced,
+		TargetUserId: targetId,
+		Details: map[string]interface{}{
+			"reason": strings.TrimSpace(req.Reason),
+		},
+		IP: ctx.IP(),
+	}
+	if err := repository.RequirePasswordReset(targetId, entry); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Usuário não encontrado",
+			})
+		}
+		log.Printf("Erro ao forçar redefinição de senha: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao forçar redefinição de senha",
+		})
+	}
+
+	err = c.Auth.sendPasswordResetEmail(target,
+		"Por segurança, nossa equipe solicitou a redefinição da senha da sua conta.",
+		"Até lá, o login com a senha atual ficará bloqueado.",
+	)
+	if err != nil {
+		// A conta já exige a nova senha; o usuário ainda pode pedir outro link em "esqueci minha senha"
+		log.Printf("Erro ao enviar link de redefinição forçada: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "A redefinição foi exigida, mas o link não pôde ser enviado. O usuário pode pedir um novo link.",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Redefinição de senha exigida e link enviado ao usuário",
+	})
+}
+
+// DeleteUser exclui logicamente a conta do usuário: o login deixa de funcionar, as sessões
+// são encerradas e as chaves de API revogadas, mas os dados são mantidos para auditoria
+func (c *AdminController) DeleteUser(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	targetId := ctx.Params("id")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = ctx.BodyParser(&req)
+
+	if targetId == adminId {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Não é possível excluir a própria conta",
+		})
+	}
+
+	target, err := repository.GetUserById(targetId)
+	if err == nil && target.DeletedAt != nil {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Usuário não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	entry := &models.AuditLog{
+		ActorId:      adminId,
+		Action:       models.AuditUserDeleted,
+		TargetUserId: targetId,
+		Details: map[string]interface{}{
+			"email":  target.Email,
+			"role":   target.Role,
+			"reason": strings.TrimSpace(req.Reason),
+		},
+		IP: ctx.IP(),
+	}
+	if err := repository.SoftDeleteUser(targetId, entry); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Usuário não encontrado",
+			})
+		}
+		log.Printf("Erro ao excluir usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao excluir usuário",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Conta excluída com sucesso",
+	})
+}
+
+// GetDashboardData retorna os totais de usuários do painel administrativo
+func (c *AdminController) GetDashboardData(ctx *fiber.Ctx) error {
+	stats, err := repository.GetUserStats()
+	if err != nil {
+		log.Printf("Erro ao calcular totais de usuários: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar dados do dashboard",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"users": stats,
+		},
+	})
+}
+
+// audit registra uma ação administrativa feita pelo usuário autenticado
+func (c *AdminController) audit(ctx *fiber.Ctx, action string, targetUserId string, details map[string]interface{}) {
+	recordAudit(ctx, ctx.Locals("userId").(string), action, targetUserId, details)
+}
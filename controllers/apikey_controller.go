@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultAPIKeyDays define a validade padrão de uma chave de API
+	defaultAPIKeyDays = 90
+	// maxAPIKeyDays limita a validade de uma chave de API, forçando a rotação periódica
+	maxAPIKeyDays = 365
+)
+
+// APIKeyController gerencia as chaves de API de integrações
+type APIKeyController struct{}
+
+// NewAPIKeyController cria uma nova instância de APIKeyController
+func NewAPIKeyController() *APIKeyController {
+	return &APIKeyController{}
+}
+
+// ListAPIKeys retorna todas as chaves de API, sem o segredo
+func (c *APIKeyController) ListAPIKeys(ctx *fiber.Ctx) error {
+	keys, err := repository.GetAPIKeys()
+	if err != nil {
+		log.Printf("Erro ao buscar chaves de API: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar chaves de API",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    keys,
+		"scopes":  policy.APIKeyPermissions(),
+	})
+}
+
+// CreateAPIKey cria uma chave de API vinculada a um espaço. A chave age como o usuário
+// informado (por padrão, o administrador que a cria), que precisa ser membro do espaço e ter
+// nele todas as permissões pedidas como escopo.
+// O valor da chave é devolvido apenas nesta resposta.
+func (c *APIKeyController) CreateAPIKey(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Name          string   `json:"name"`
+		EspacoId      string   `json:"espacoId"`
+		UserId        string   `json:"userId"`
+		Scopes        []string `json:"scopes"`
+		ExpiresInDays int      `json:"expiresInDays"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || req.EspacoId == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Nome e espaço são obrigatórios",
+		})
+	}
+	if req.UserId == "" {
+		req.UserId = adminId
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultAPIKeyDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > maxAPIKeyDays {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A validade deve ser de 1 a 365 dias",
+		})
+	}
+
+	scopes := []string{}
+	seen := map[string]bool{}
+	for _, scope := range req.Scopes {
+		if !policy.IsAPIKeyPermission(policy.Permission(scope)) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Escopo inválido: " + scope,
+			})
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Informe ao menos um escopo",
+		})
+	}
+
+	membro, err := repository.GetEspacoMembro(req.EspacoId, req.UserId)
+	if err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "O usuário da chave precisa ser membro do espaço",
+			})
+		}
+		log.Printf("Erro ao verificar vínculo com o espaço: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	keyUser, err := repository.GetUserById(req.UserId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário da chave: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	// A chave não pode receber escopos que o próprio usuário não tem no espaço
+	granted := policy.Resolve(keyUser.Role, membro)
+	for _, scope := range scopes {
+		if !granted.Has(policy.Permission(scope)) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "O usuário da chave não tem a permissão " + scope + " no espaço",
+			})
+		}
+	}
+
+	rawKey, prefix, keyHash, err := auth.GenerateAPIKey()
+	if err != nil {
+		log.Printf("Erro ao gerar chave de API: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar chave de API",
+		})
+	}
+
+	key := &models.APIKey{
+		Name:        req.Name,
+		Prefix:      prefix,
+		UserId:      req.UserId,
+		EspacoId:    req.EspacoId,
+		Scopes:      scopes,
+		ExpiresAt:   time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour),
+		CreatedById: adminId,
+	}
+	if err := repository.CreateAPIKey(key, keyHash); err != nil {
+		log.Printf("Erro ao salvar chave de API: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar chave de API",
+		})
+	}
+
+	recordAudit(ctx, adminId, models.AuditAPIKeyCreated, key.UserId, map[string]interface{}{
+		"apiKeyId": key.ID,
+		"prefix":   key.Prefix,
+		"espacoId": key.EspacoId,
+		"scopes":   key.Scopes,
+	})
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Chave de API criada. Guarde-a agora: ela não será exibida novamente.",
+		"key":     rawKey,
+		"data":    key,
+	})
+}
+
+// RevokeAPIKey revoga uma chave de API imediatamente
+func (c *APIKeyController) RevokeAPIKey(ctx *fiber.Ctx) error {
+	adminId := ctx.Locals("userId").(string)
+	keyId := ctx.Params("id")
+
+	if err := repository.RevokeAPIKey(keyId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Chave de API não encontrada",
+			})
+		}
+		log.Printf("Erro ao revogar chave de API: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao revogar chave de API",
+		})
+	}
+
+	recordAudit(ctx, adminId, models.AuditAPIKeyRevoked, "", map[string]interface{}{
+		"apiKeyId": keyId,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Chave de API revogada com sucesso",
+	})
+}
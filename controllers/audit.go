@@ -0,0 +1,23 @@
+package controllers
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// recordAudit grava um registro de auditoria com o IP da requisição; falhas apenas vão para o log
+func recordAudit(ctx *fiber.Ctx, actorId string, action string, targetUserId string, details map[string]interface{}) {
+	entry := &models.AuditLog{
+		ActorId:      actorId,
+		Action:       action,
+		TargetUserId: targetUserId,
+		Details:      details,
+		IP:           ctx.IP(),
+	}
+	if err := repository.CreateAuditLog(entry); err != nil {
+		log.Printf("Erro ao registrar auditoria (%s): %v", action, err)
+	}
+}
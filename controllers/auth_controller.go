@@ -3,26 +3,58 @@ package controllers
 import (
 	"database/sql"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/WBianchi/maiscrianca/auth"
 	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/mail"
 	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/password"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/WBianchi/maiscrianca/repository"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	// passwordResetExpiration define por quanto tempo o link de redefinição de senha é válido
+	passwordResetExpiration = time.Hour
+	// emailVerificationExpiration define por quanto tempo o link de verificação de email é válido
+	emailVerificationExpiration = 24 * time.Hour
+	// verificationResendInterval define o intervalo mínimo entre reenvios do email de verificação
+	verificationResendInterval = time.Minute
+	// magicLinkExpiration define por quanto tempo o link de acesso sem senha é válido
+	magicLinkExpiration = 15 * time.Minute
+	// maxMagicLinkRequests limita quantos links de acesso um email pode pedir dentro da janela
+	maxMagicLinkRequests = 3
+	// magicLinkRequestWindow define após quanto tempo sem pedidos o limite de links recomeça
+	magicLinkRequestWindow = 15 * time.Minute
+	// emailChangeExpiration define por quanto tempo o link de confirmação do novo email é válido
+	emailChangeExpiration = 24 * time.Hour
+	// emailChangeUndoExpiration define por quanto tempo o email anterior pode desfazer a troca
+	emailChangeUndoExpiration = 7 * 24 * time.Hour
+	// emailChangeInterval define o intervalo mínimo entre pedidos de troca de email
+	emailChangeInterval = time.Minute
+)
+
 // AuthController gerencia a autenticação de usuários
 type AuthController struct {
-	DB     *sql.DB
-	Config *configs.Config
+	DB        *sql.DB
+	Config    *configs.Config
+	Mailer    mail.Mailer
+	Passwords *password.Policy
 }
 
 // NewAuthController cria uma nova instância de AuthController
-func NewAuthController(db *sql.DB, config *configs.Config) *AuthController {
+func NewAuthController(db *sql.DB, config *configs.Config, mailer mail.Mailer, passwords *password.Policy) *AuthController {
 	return &AuthController{
-		DB:     db,
-		Config: config,
+		DB:        db,
+		Config:    config,
+		Mailer:    mailer,
+		Passwords: passwords,
 	}
 }
 
@@ -58,26 +90,44 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 
 	log.Println("Conexão com o banco de dados verificada com sucesso")
 
+	// Recusar tentativas enquanto o IP ou a conta estiverem bloqueados
+	lockedUntil, err := repository.GetLoginLock(ipThrottleKey(ctx.IP()), accountThrottleKey(loginRequest.Email))
+	if err != nil {
+		log.Printf("Erro ao verificar bloqueio de login: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if lockedUntil != nil {
+		return loginLockedResponse(ctx, *lockedUntil)
+	}
+
 	// Buscar usuário pelo email
 	var user models.User
 	var hashedPassword string
 	// Usamos NullString para o campo que pode ser nulo
 	var profileAvatar sql.NullString
+	var emailVerifiedAt, suspendedAt sql.NullTime
 
-	query := `SELECT id, email, password, name, role, "profileAvatar", "createdAt", "updatedAt" 
-              FROM "User" WHERE email = $1`
+	// Contas excluídas se comportam como inexistentes
+	query := `SELECT id, email, password, name, role, "profileAvatar", "emailVerifiedAt", "createdAt", "updatedAt",
+              "suspendedAt", "passwordResetRequired"
+              FROM "User" WHERE email = $1 AND "deletedAt" IS NULL`
 	
 	log.Printf("Executando query: %s com email: %s", query, loginRequest.Email)
 	
-	err := c.DB.QueryRow(query, loginRequest.Email).Scan(
+	err = c.DB.QueryRow(query, loginRequest.Email).Scan(
 		&user.ID,
 		&user.Email,
 		&hashedPassword,
 		&user.Name,
 		&user.Role,
 		&profileAvatar,
+		&emailVerifiedAt,
 		&user.CreatedAt,
 		&user.UpdatedAt,
+		&suspendedAt,
+		&user.PasswordResetRequired,
 	)
 	
 	// Se o valor for válido (não nulo), atribuímos à estrutura user
@@ -86,10 +136,19 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 	} else {
 		user.ProfileAvatar = ""
 	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if suspendedAt.Valid {
+		user.SuspendedAt = &suspendedAt.Time
+	}
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			log.Printf("Usuário não encontrado para o email: %s", loginRequest.Email)
+			// Comparação fictícia para igualar o tempo de resposta ao de uma senha incorreta
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(loginRequest.Password))
+			registerLoginFailure(ctx, loginRequest.Email, "")
 			return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Credenciais inválidas",
 			})
@@ -103,49 +162,185 @@ func (c *AuthController) Login(ctx *fiber.Ctx) error {
 	// Verificar senha
 	err = bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(loginRequest.Password))
 	if err != nil {
+		registerLoginFailure(ctx, loginRequest.Email, user.ID)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Credenciais inválidas",
+		})
+	}
+
+	// A redefinição forçada por um administrador invalida a senha atual; os outros meios de login seguem valendo
+	if user.PasswordResetRequired && user.IsActive() {
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "É necessário redefinir sua senha. Verifique seu email ou solicite um novo link.",
+			"code":  "PASSWORD_RESET_REQUIRED",
+		})
+	}
+
+	return c.completeLogin(ctx, &user, loginRequest.UseCookie)
+}
+
+// completeLogin finaliza um login cuja credencial primária já foi verificada (senha ou provedor social).
+// Com 2FA ativo, devolve apenas um desafio de curta duração; os tokens vêm em VerifyMFA.
+func (c *AuthController) completeLogin(ctx *fiber.Ctx, user *models.User, useCookie bool) error {
+	if !user.IsActive() {
+		return inactiveAccountResponse(ctx, user)
+	}
+
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if totp.EnabledAt != nil {
+		claims := auth.NewClaims(user)
+		claims.Purpose = auth.PurposeMFA
+		mfaToken, err := auth.SignClaimsWithTTL(claims, c.Config, mfaChallengeExpiration)
+		if err != nil {
+			log.Printf("Erro ao gerar desafio de 2FA: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao gerar token de autenticação",
+			})
+		}
+		return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+			"mfaRequired": true,
+			"mfaToken":    mfaToken,
+			"methods":     []string{"totp", "recovery_code"},
+		})
+	}
+
+	// Login bem-sucedido zera as falhas da conta (as do IP expiram com a janela)
+	if err := repository.ClearLoginFailures(accountThrottleKey(user.Email)); err != nil {
+		log.Printf("Erro ao limpar falhas de login: %v", err)
+	}
+
+	return c.respondWithSession(ctx, user, useCookie, fiber.StatusOK)
+}
+
+// inactiveAccountResponse recusa o login de contas suspensas ou excluídas.
+// Contas excluídas recebem a mesma resposta de credenciais inválidas, como se não existissem.
+func inactiveAccountResponse(ctx *fiber.Ctx, user *models.User) error {
+	if user.DeletedAt != nil {
 		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Credenciais inválidas",
 		})
 	}
+	return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+		"error": "Conta suspensa. Entre em contato com o suporte.",
+		"code":  "ACCOUNT_SUSPENDED",
+	})
+}
 
-	// Gerar token JWT
-	token, err := auth.GenerateToken(&user, c.Config)
+// respondWithSession abre uma sessão e responde com os tokens (no corpo ou em cookies)
+// e a página de redirecionamento da role
+func (c *AuthController) respondWithSession(ctx *fiber.Ctx, user *models.User, useCookie bool, status int) error {
+	userResponse, err := newUserResponse(ctx, user, c.Config)
 	if err != nil {
 		log.Printf("Erro ao gerar token: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Erro ao gerar token de autenticação",
 		})
 	}
-
-	// Verificar a página de redirecionamento com base na role
-	var redirectUrl string
-	switch user.Role {
-	case models.ADMIN:
-		redirectUrl = "/dashboard"
-	case models.CLIENT:
-		redirectUrl = "/cliente/visao-geral"
-	case models.EMPLOYEE:
-		redirectUrl = "/funcionario/visao-geral"
-	case models.AFFILIATE:
-		redirectUrl = "/afiliado/visao-geral"
-	default:
-		redirectUrl = "/cliente/visao-geral"
+	if useCookie {
+		if err := setSessionCookies(ctx, c.Config, userResponse); err != nil {
+			log.Printf("Erro ao gerar token CSRF: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao gerar token de autenticação",
+			})
+		}
 	}
 
 	// Resposta com token e informações do usuário
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
-		"user": models.UserResponse{
-			ID:            user.ID,
-			Email:         user.Email,
-			Name:          user.Name,
-			Role:          user.Role,
-			ProfileAvatar: user.ProfileAvatar,
-			Token:         token,
-		},
-		"redirectUrl": redirectUrl,
+	return ctx.Status(status).JSON(fiber.Map{
+		"user":        userResponse,
+		"redirectUrl": policy.LandingPage(user.Role),
 	})
 }
 
+// VerifyMFA conclui o login de contas com 2FA: troca o desafio emitido por Login
+// e um código TOTP ou de recuperação pelos tokens de acesso e de atualização
+func (c *AuthController) VerifyMFA(ctx *fiber.Ctx) error {
+	var req struct {
+		MFAToken  string `json:"mfaToken"`
+		UseCookie bool   `json:"useCookie"`
+		mfaCodeRequest
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.MFAToken == "" || (req.Code == "" && req.RecoveryCode == "") {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Desafio e código de verificação são obrigatórios",
+		})
+	}
+
+	claims, err := auth.ValidateToken(req.MFAToken, c.Config)
+	if err != nil || claims.Purpose != auth.PurposeMFA {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Desafio de autenticação inválido ou expirado",
+		})
+	}
+
+	user, err := repository.GetUserById(claims.UserID)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Desafio de autenticação inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if !user.IsActive() {
+		return inactiveAccountResponse(ctx, user)
+	}
+
+	// Os códigos errados contam para o mesmo bloqueio das senhas erradas
+	lockedUntil, err := repository.GetLoginLock(ipThrottleKey(ctx.IP()), accountThrottleKey(user.Email))
+	if err != nil {
+		log.Printf("Erro ao verificar bloqueio de login: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if lockedUntil != nil {
+		return loginLockedResponse(ctx, *lockedUntil)
+	}
+
+	totp, err := repository.GetUserTOTP(user.ID)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if totp.EnabledAt == nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Desafio de autenticação inválido ou expirado",
+		})
+	}
+
+	ok, err := verifySecondFactor(ctx, totp, req.mfaCodeRequest)
+	if err != nil {
+		log.Printf("Erro ao verificar segundo fator: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if !ok {
+		registerLoginFailure(ctx, user.Email, user.ID)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Código de verificação inválido",
+		})
+	}
+
+	if err := repository.ClearLoginFailures(accountThrottleKey(user.Email)); err != nil {
+		log.Printf("Erro ao limpar falhas de login: %v", err)
+	}
+
+	return c.respondWithSession(ctx, user, req.UseCookie, fiber.StatusOK)
+}
+
 // Register registra um novo usuário
 func (c *AuthController) Register(ctx *fiber.Ctx) error {
 	log.Println("Endpoint de registro chamado")
@@ -157,7 +352,6 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 			"error": "Dados de registro inválidos",
 		})
 	}
-	log.Printf("Dados de registro recebidos: %+v", registerRequest)
 
 	// Validação básica
 	if registerRequest.Email == "" || registerRequest.Password == "" || registerRequest.Name == "" {
@@ -166,6 +360,17 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 		})
 	}
 
+	registerRequest.Email = strings.TrimSpace(registerRequest.Email)
+	if !isValidEmail(registerRequest.Email) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email inválido",
+		})
+	}
+
+	if ok, err := c.checkPassword(ctx, registerRequest.Password, registerRequest.Email, registerRequest.Name); !ok {
+		return err
+	}
+
 	// Verificar conexão com o DB primeiro
 	if err := c.DB.Ping(); err != nil {
 		log.Printf("Erro na conexão com o banco de dados: %v", err)
@@ -176,10 +381,7 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 	log.Println("Conexão com o banco de dados verificada com sucesso")
 
 	// Verificar se o email já está em uso
-	var count int
-	emailCheckQuery := `SELECT COUNT(*) FROM "User" WHERE email = $1`
-	log.Printf("Executando verificação de email com query: %s e email: %s", emailCheckQuery, registerRequest.Email)
-	err := c.DB.QueryRow(emailCheckQuery, registerRequest.Email).Scan(&count)
+	inUse, err := repository.IsEmailInUse(registerRequest.Email)
 	if err != nil {
 		log.Printf("Erro ao verificar email: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -187,12 +389,25 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 		})
 	}
 
-	if count > 0 {
+	if inUse {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Este email já está em uso",
 		})
 	}
 
+	// Definir role: o cadastro público é sempre CLIENT, outras roles exigem código de convite
+	role := models.CLIENT
+	var codigo *models.CodigoConvite
+	if registerRequest.InviteCode != "" {
+		codigo, err = repository.GetCodigoConviteByHash(auth.HashToken(registerRequest.InviteCode))
+		if err != nil || codigo.UsedAt != nil || time.Now().After(codigo.ExpiresAt) {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Código de convite inválido ou expirado",
+			})
+		}
+		role = codigo.Role
+	}
+
 	// Hash da senha
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(registerRequest.Password), bcrypt.DefaultCost)
 	if err != nil {
@@ -202,12 +417,6 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// Definir role (default CLIENT)
-	role := models.CLIENT
-	if registerRequest.Role != "" {
-		role = registerRequest.Role
-	}
-
 	// Inserir novo usuário
 	id := uuid.New().String()
 	log.Printf("ID gerado para novo usuário: %s", id)
@@ -224,7 +433,17 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 	// Usamos um ponteiro para NullString para o campo que pode ser nulo
 	var profileAvatar sql.NullString
 
-	err = c.DB.QueryRow(
+	// O usuário, os consentimentos e o consumo do código de convite são gravados na mesma transação
+	tx, err := c.DB.Begin()
+	if err != nil {
+		log.Printf("Erro ao iniciar transação: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
 		insertQuery, 
 		id, 
 		registerRequest.Email, 
@@ -254,11 +473,167 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 			"error": "Erro ao criar usuário: " + err.Error(),
 		})
 	}
+
+	consents := signupConsents(c.Config.TermsVersion, ctx.IP(), registerRequest.MarketingOptIn)
+	if err := repository.InsertUserConsents(tx, user.ID, consents); err != nil {
+		log.Printf("Erro ao registrar consentimentos: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar usuário",
+		})
+	}
+
+	if codigo != nil {
+		if err := repository.UseCodigoConvite(tx, codigo.ID, user.ID); err != nil {
+			if err == repository.ErrNotFound {
+				return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Código de convite inválido ou expirado",
+				})
+			}
+			log.Printf("Erro ao usar código de convite: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Printf("Erro ao confirmar cadastro: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar usuário",
+		})
+	}
 	
 	log.Printf("Usuário criado com sucesso: %+v", user)
 
-	// Gerar token JWT
-	token, err := auth.GenerateToken(&user, c.Config)
+	// Enviar o link de confirmação do email
+	c.sendVerificationEmail(&user)
+
+	// Gerar tokens de acesso e de atualização
+	return c.respondWithSession(ctx, &user, registerRequest.UseCookie, fiber.StatusCreated)
+}
+
+// Refresh troca um token de atualização válido por um novo par de tokens.
+// A reutilização de um token já rotacionado revoga toda a família.
+func (c *AuthController) Refresh(ctx *fiber.Ctx) error {
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	_ = ctx.BodyParser(&req)
+
+	// No modo cookie o token de atualização vem do cookie HttpOnly e a resposta renova os cookies
+	fromCookie := false
+	if req.RefreshToken == "" {
+		req.RefreshToken = ctx.Cookies(auth.RefreshTokenCookie)
+		fromCookie = req.RefreshToken != ""
+	}
+	if req.RefreshToken == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token de atualização é obrigatório",
+		})
+	}
+
+	current, err := repository.GetRefreshTokenByHash(auth.HashToken(req.RefreshToken))
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Token de atualização inválido",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar token de atualização: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if current.RevokedAt != nil {
+		if current.ReplacedById != "" {
+			// Token já rotacionado sendo usado de novo: possível roubo, encerrar a família inteira
+			log.Printf("Reutilização de token de atualização detectada para o usuário %s", current.UserId)
+			if err := repository.RevokeSession(current.FamilyId, current.UserId); err != nil && err != repository.ErrNotFound {
+				log.Printf("Erro ao encerrar sessão: %v", err)
+			}
+		}
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Token de atualização revogado",
+		})
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Token de atualização expirado",
+		})
+	}
+
+	// A família do token é a sessão; sessões encerradas remotamente não podem ser renovadas
+	active, err := repository.TouchSession(current.FamilyId)
+	if err != nil {
+		log.Printf("Erro ao verificar sessão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if !active {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Sessão encerrada",
+		})
+	}
+
+	user, err := repository.GetUserById(current.UserId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Token de atualização inválido",
+		})
+	}
+
+	refreshToken, refreshHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar token de atualização: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar token de autenticação",
+		})
+	}
+
+	_, err = repository.RotateRefreshToken(current, refreshHash, time.Now().Add(c.Config.RefreshTokenTTL))
+	if err == repository.ErrNotFound {
+		// Outra requisição rotacionou este token ao mesmo tempo
+		if err := repository.RevokeSession(current.FamilyId, current.UserId); err != nil && err != repository.ErrNotFound {
+			log.Printf("Erro ao encerrar sessão: %v", err)
+		}
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Token de atualização revogado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao rotacionar token de atualização: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar token de autenticação",
+		})
+	}
+
+	// O perfil infantil selecionado continua valendo: renovar o token não remove os controles parentais
+	childProfileId, err := repository.GetSessionChildProfileId(current.FamilyId)
+	if err != nil {
+		log.Printf("Erro ao buscar perfil infantil da sessão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	// O espaço ativo também é restaurado; a participação nele é verificada pelo middleware de espaço
+	espacoId, err := repository.GetSessionEspacoId(current.FamilyId)
+	if err != nil {
+		log.Printf("Erro ao buscar espaço da sessão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	claims := auth.NewClaims(user)
+	claims.SessionID = current.FamilyId
+	claims.ChildProfileID = childProfileId
+	claims.EspacoID = espacoId
+	token, err := auth.SignClaims(claims, c.Config)
 	if err != nil {
 		log.Printf("Erro ao gerar token: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -266,31 +641,438 @@ func (c *AuthController) Register(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// Verificar a página de redirecionamento com base na role
-	var redirectUrl string
-	switch user.Role {
-	case models.ADMIN:
-		redirectUrl = "/dashboard"
-	case models.CLIENT:
-		redirectUrl = "/cliente/visao-geral"
-	case models.EMPLOYEE:
-		redirectUrl = "/funcionario/visao-geral"
-	case models.AFFILIATE:
-		redirectUrl = "/afiliado/visao-geral"
-	default:
-		redirectUrl = "/cliente/visao-geral"
+	if fromCookie {
+		resp := &models.UserResponse{Token: token, RefreshToken: refreshToken}
+		if err := setSessionCookies(ctx, c.Config, resp); err != nil {
+			log.Printf("Erro ao gerar token CSRF: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao gerar token de autenticação",
+			})
+		}
+		return ctx.JSON(fiber.Map{
+			"csrfToken": resp.CSRFToken,
+		})
 	}
 
-	// Resposta com token e informações do usuário
-	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"user": models.UserResponse{
-			ID:            user.ID,
-			Email:         user.Email,
-			Name:          user.Name,
-			Role:          user.Role,
-			ProfileAvatar: user.ProfileAvatar,
-			Token:         token,
-		},
-		"redirectUrl": redirectUrl,
+	return ctx.JSON(fiber.Map{
+		"token":        token,
+		"refreshToken": refreshToken,
 	})
 }
+
+// Logout encerra a sessão atual e revoga o token de acesso
+func (c *AuthController) Logout(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	// Tokens emitidos antes das sessões não têm sid: usar a família do token de atualização informado
+	sessionId, _ := ctx.Locals("sessionId").(string)
+	if sessionId == "" {
+		var req struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		_ = ctx.BodyParser(&req)
+		if req.RefreshToken == "" {
+			req.RefreshToken = ctx.Cookies(auth.RefreshTokenCookie)
+		}
+		if req.RefreshToken != "" {
+			if current, err := repository.GetRefreshTokenByHash(auth.HashToken(req.RefreshToken)); err == nil {
+				sessionId = current.FamilyId
+			}
+		}
+	}
+
+	if sessionId != "" {
+		if err := repository.RevokeSession(sessionId, userId); err != nil && err != repository.ErrNotFound {
+			log.Printf("Erro ao encerrar sessão: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao encerrar sessão",
+			})
+		}
+	}
+
+	jti, _ := ctx.Locals("tokenId").(string)
+	expiresAt, _ := ctx.Locals("tokenExpiresAt").(time.Time)
+	if err := repository.RevokeAccessToken(jti, expiresAt); err != nil {
+		log.Printf("Erro ao revogar token de acesso: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao encerrar sessão",
+		})
+	}
+
+	clearSessionCookies(ctx, c.Config)
+
+	return ctx.JSON(fiber.Map{
+		"message": "Sessão encerrada com sucesso",
+	})
+}
+
+// CSRFToken devolve o token CSRF da sessão por cookie, emitindo um novo se necessário.
+// Permite que o frontend recupere o token após recarregar a página.
+func (c *AuthController) CSRFToken(ctx *fiber.Ctx) error {
+	csrfToken := ctx.Cookies(auth.CSRFCookie)
+	if csrfToken == "" {
+		var err error
+		csrfToken, err = setCSRFCookie(ctx, c.Config)
+		if err != nil {
+			log.Printf("Erro ao gerar token CSRF: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+	}
+
+	return ctx.JSON(fiber.Map{
+		"csrfToken": csrfToken,
+	})
+}
+
+// ForgotPassword envia um link de redefinição de senha para o email informado.
+// A resposta é sempre a mesma para não revelar quais emails estão cadastrados.
+func (c *AuthController) ForgotPassword(ctx *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email é obrigatório",
+		})
+	}
+
+	response := fiber.Map{
+		"message": "Se o email estiver cadastrado, você receberá instruções para redefinir sua senha",
+	}
+
+	user, err := repository.GetUserByEmail(req.Email)
+	if err != nil {
+		if err != repository.ErrNotFound {
+			log.Printf("Erro ao buscar usuário: %v", err)
+		}
+		return ctx.JSON(response)
+	}
+	if !user.IsActive() {
+		return ctx.JSON(response)
+	}
+
+	// O envio acontece em segundo plano para que o tempo de resposta não revele se o email existe
+	if err := c.sendPasswordResetEmail(user,
+		"Recebemos um pedido para redefinir a senha da sua conta.",
+		"Se você não fez este pedido, ignore este email.",
+	); err != nil {
+		log.Printf("Erro ao gerar link de redefinição: %v", err)
+	}
+
+	return ctx.JSON(response)
+}
+
+// ResetPassword define uma nova senha a partir de um token de redefinição e encerra as sessões existentes
+func (c *AuthController) ResetPassword(ctx *fiber.Ctx) error {
+	var req struct {
+		Token    string `json:"token"`
+		Password string `json:"password"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" || req.Password == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token e nova senha são obrigatórios",
+		})
+	}
+
+	token, err := repository.GetValidUserToken(auth.HashToken(req.Token), models.TokenPasswordReset)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Link de redefinição inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar token de redefinição: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	user, err := repository.GetUserById(token.UserId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if ok, err := c.checkPassword(ctx, req.Password, user.Email, user.Name); !ok {
+		return err
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Erro ao gerar hash da senha: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao processar senha",
+		})
+	}
+
+	if err := repository.ResetUserPassword(token, string(hashedPassword)); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Link de redefinição inválido ou expirado",
+			})
+		}
+		log.Printf("Erro ao redefinir senha: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao redefinir senha",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Senha redefinida com sucesso. Faça login novamente.",
+	})
+}
+
+// RequestMagicLink envia um link de acesso sem senha, de uso único e curta duração, para o email informado.
+// A resposta é sempre a mesma para não revelar quais emails estão cadastrados.
+func (c *AuthController) RequestMagicLink(ctx *fiber.Ctx) error {
+	var req struct {
+		Email string `json:"email"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Email == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email é obrigatório",
+		})
+	}
+
+	// O limite vale para qualquer email, cadastrado ou não, para não revelar quais contas existem
+	requests, err := repository.IncrementLoginFailures(magicLinkThrottleKey(req.Email), magicLinkRequestWindow)
+	if err != nil {
+		log.Printf("Erro ao registrar pedido de link de acesso: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if requests > maxMagicLinkRequests {
+		ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(magicLinkRequestWindow.Seconds())))
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Muitos pedidos de link de acesso. Tente novamente mais tarde.",
+		})
+	}
+
+	response := fiber.Map{
+		"message": "Se o email estiver cadastrado, você receberá um link para entrar",
+	}
+
+	user, err := repository.GetUserByEmail(req.Email)
+	if err != nil {
+		if err != repository.ErrNotFound {
+			log.Printf("Erro ao buscar usuário: %v", err)
+		}
+		return ctx.JSON(response)
+	}
+	if !user.IsActive() {
+		return ctx.JSON(response)
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar link de acesso: %v", err)
+		return ctx.JSON(response)
+	}
+
+	_, err = repository.CreateUserToken(user.ID, models.TokenMagicLink, tokenHash, "", time.Now().Add(magicLinkExpiration))
+	if err != nil {
+		log.Printf("Erro ao salvar link de acesso: %v", err)
+		return ctx.JSON(response)
+	}
+
+	c.sendMail(mail.Message{
+		To:      user.Email,
+		Subject: "Seu link de acesso - Mais Criança",
+		Body: "Olá, " + user.Name + "!\n\n" +
+			"Para entrar na sua conta sem senha, acesse:\n\n" +
+			c.Config.AppURL + "/entrar/link?token=" + token + "\n\n" +
+			"O link é válido por 15 minutos e pode ser usado apenas uma vez. " +
+			"Se você não fez este pedido, ignore este email.",
+	})
+
+	return ctx.JSON(response)
+}
+
+// ConsumeMagicLink troca o link de acesso pelos mesmos tokens e redirecionamento do Login.
+// Contas com 2FA ativo recebem o desafio de 2FA, como no login com senha.
+func (c *AuthController) ConsumeMagicLink(ctx *fiber.Ctx) error {
+	var req struct {
+		Token     string `json:"token"`
+		UseCookie bool   `json:"useCookie"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token do link de acesso é obrigatório",
+		})
+	}
+
+	token, err := repository.GetValidUserToken(auth.HashToken(req.Token), models.TokenMagicLink)
+	if err == nil {
+		// Consumir o link também confirma o email, já que ele só chega a quem tem acesso à caixa de entrada
+		err = repository.VerifyUserEmail(token)
+	}
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Link de acesso inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao consumir link de acesso: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	user, err := repository.GetUserById(token.UserId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	return c.completeLogin(ctx, user, req.UseCookie)
+}
+
+// VerifyEmail confirma o email do usuário a partir do token enviado no cadastro
+func (c *AuthController) VerifyEmail(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token de verificação é obrigatório",
+		})
+	}
+
+	token, err := repository.GetValidUserToken(auth.HashToken(req.Token), models.TokenEmailVerification)
+	if err == nil {
+		err = repository.VerifyUserEmail(token)
+	}
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Link de verificação inválido ou expirado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao verificar email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao verificar email",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Email verificado com sucesso",
+	})
+}
+
+// ResendVerification reenvia o link de confirmação de email para o usuário autenticado
+func (c *AuthController) ResendVerification(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Este email já foi verificado",
+		})
+	}
+
+	recent, err := repository.CountRecentUserTokens(userId, models.TokenEmailVerification, time.Now().Add(-verificationResendInterval))
+	if err != nil {
+		log.Printf("Erro ao verificar reenvios: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if recent > 0 {
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Aguarde um minuto antes de solicitar um novo email de verificação",
+		})
+	}
+
+	c.sendVerificationEmail(user)
+
+	return ctx.JSON(fiber.Map{
+		"message": "Email de verificação reenviado",
+	})
+}
+
+// sendVerificationEmail gera um token de verificação e envia o link de confirmação ao usuário
+func (c *AuthController) sendVerificationEmail(user *models.User) {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar token de verificação: %v", err)
+		return
+	}
+
+	_, err = repository.CreateUserToken(user.ID, models.TokenEmailVerification, tokenHash, "", time.Now().Add(emailVerificationExpiration))
+	if err != nil {
+		log.Printf("Erro ao salvar token de verificação: %v", err)
+		return
+	}
+
+	c.sendMail(mail.Message{
+		To:      user.Email,
+		Subject: "Confirme seu email - Mais Criança",
+		Body: "Olá, " + user.Name + "!\n\n" +
+			"Para confirmar seu email e liberar compras e downloads, acesse:\n\n" +
+			c.Config.AppURL + "/verificar-email?token=" + token + "\n\n" +
+			"O link é válido por 24 horas.",
+	})
+}
+
+// sendPasswordResetEmail gera um token de redefinição de senha e envia o link ao usuário.
+// reason abre o email explicando por que a redefinição foi pedida e closing o encerra.
+func (c *AuthController) sendPasswordResetEmail(user *models.User, reason string, closing string) error {
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	_, err = repository.CreateUserToken(user.ID, models.TokenPasswordReset, tokenHash, "", time.Now().Add(passwordResetExpiration))
+	if err != nil {
+		return err
+	}
+
+	c.sendMail(mail.Message{
+		To:      user.Email,
+		Subject: "Redefinição de senha - Mais Criança",
+		Body: "Olá, " + user.Name + "!\n\n" +
+			reason + " Para criar uma nova senha, acesse:\n\n" +
+			c.Config.AppURL + "/redefinir-senha?token=" + token + "\n\n" +
+			"O link é válido por 1 hora e pode ser usado apenas uma vez. " + closing,
+	})
+	return nil
+}
+
+// checkPassword valida a nova senha pela política de senhas. Na recusa escreve a resposta 400 com
+// o motivo e retorna ok false. Se a consulta a senhas vazadas falhar, a senha é aceita para que
+// a indisponibilidade do provedor não impeça cadastros e trocas de senha.
+func (c *AuthController) checkPassword(ctx *fiber.Ctx, newPassword string, userInputs ...string) (ok bool, err error) {
+	err = c.Passwords.Check(ctx.UserContext(), newPassword, userInputs...)
+	if err == nil {
+		return true, nil
+	}
+	if !password.IsPolicyError(err) {
+		log.Printf("Senha aceita sem consulta a senhas vazadas: %v", err)
+		return true, nil
+	}
+	return false, ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// sendMail envia o email em segundo plano, registrando falhas no log
+func (c *AuthController) sendMail(msg mail.Message) {
+	go func() {
+		if err := c.Mailer.Send(msg); err != nil {
+			log.Printf("Erro ao enviar email \"%s\": %v", msg.Subject, err)
+		}
+	}()
+}
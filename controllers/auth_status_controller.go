@@ -3,6 +3,7 @@ package controllers
 import (
 	"github.com/WBianchi/maiscrianca/configs"
 	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/policy"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -18,7 +19,7 @@ func NewAuthStatusController(config *configs.Config) *AuthStatusController {
 	}
 }
 
-// GetAuthStatus retorna o status de autenticação do usuário e para onde redirecionar com base na role
+// GetAuthStatus retorna o status de autenticação do usuário, para onde redirecioná-lo e o que a role permite
 func (c *AuthStatusController) GetAuthStatus(ctx *fiber.Ctx) error {
 	// Recupera a role do usuário a partir do middleware de autenticação
 	userRole, ok := ctx.Locals("userRole").(models.Role)
@@ -29,24 +30,23 @@ func (c *AuthStatusController) GetAuthStatus(ctx *fiber.Ctx) error {
 		})
 	}
 
-	// Determina a URL para redirecionamento com base na role
-	var redirectUrl string
-	switch userRole {
-	case models.ADMIN:
-		redirectUrl = "/dashboard"
-	case models.CLIENT:
-		redirectUrl = "/cliente/visao-geral"
-	case models.EMPLOYEE:
-		redirectUrl = "/funcionario/visao-geral"
-	case models.AFFILIATE:
-		redirectUrl = "/afiliado/visao-geral"
-	default:
-		redirectUrl = "/"
-	}
+	// A página inicial e as permissões vêm da política central, a mesma usada no login
+	rolePolicy := policy.For(userRole)
 
-	// Retorna as informações de autenticação
-	return ctx.Status(fiber.StatusOK).JSON(fiber.Map{
+	status := fiber.Map{
 		"authenticated": true,
-		"redirectUrl":   redirectUrl,
-	})
+		"userId":        ctx.Locals("userId"),
+		"role":          userRole,
+		"redirectUrl":   rolePolicy.LandingPage,
+		"routeGroups":   rolePolicy.RouteGroups,
+		"permissions":   rolePolicy.Permissions,
+	}
+
+	// Sessões de personificação são sinalizadas para que o frontend exiba o aviso
+	if impersonatorId, _ := ctx.Locals("impersonatorId").(string); impersonatorId != "" {
+		status["impersonating"] = true
+		status["impersonatorId"] = impersonatorId
+	}
+
+	return ctx.Status(fiber.StatusOK).JSON(status)
 }
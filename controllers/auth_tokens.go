@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxUserAgentLength limita o user-agent guardado na sessão
+const maxUserAgentLength = 512
+
+// newUserResponse registra uma nova sessão para o dispositivo da requisição, emite o
+// token de acesso e inicia a família de tokens de atualização da sessão
+func newUserResponse(ctx *fiber.Ctx, user *models.User, config *configs.Config) (*models.UserResponse, error) {
+	userAgent := ctx.Get(fiber.HeaderUserAgent)
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	session := &models.Session{
+		UserId:    user.ID,
+		Device:    deviceFromUserAgent(userAgent),
+		UserAgent: userAgent,
+		IP:        ctx.IP(),
+	}
+	if err := repository.CreateSession(session); err != nil {
+		return nil, err
+	}
+
+	claims := auth.NewClaims(user)
+	claims.SessionID = session.ID
+	token, err := auth.SignClaims(claims, config)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, refreshHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = repository.CreateRefreshToken(user.ID, session.ID, refreshHash, time.Now().Add(config.RefreshTokenTTL))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserResponse{
+		ID:            user.ID,
+		Email:         user.Email,
+		Name:          user.Name,
+		Role:          user.Role,
+		ProfileAvatar: user.ProfileAvatar,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Token:         token,
+		RefreshToken:  refreshToken,
+	}, nil
+}
+
+// reissueAccessToken emite um novo token de acesso para a sessão em uso, mantendo o espaço, o perfil
+// infantil e a personificação do token atual; update ajusta os claims antes da assinatura.
+// Sessões por cookie recebem o token no próprio cookie e o retorno fica vazio.
+func reissueAccessToken(ctx *fiber.Ctx, config *configs.Config, user *models.User, update func(claims *auth.JWTClaims)) (string, error) {
+	claims := auth.NewClaims(user)
+	claims.EspacoID, _ = ctx.Locals("tokenEspacoId").(string)
+	claims.ChildProfileID, _ = ctx.Locals("childProfileId").(string)
+	claims.SessionID, _ = ctx.Locals("sessionId").(string)
+
+	// Na personificação o novo token continua marcado e não ultrapassa a validade do atual
+	ttl := config.AccessTokenTTL
+	if impersonatorId, _ := ctx.Locals("impersonatorId").(string); impersonatorId != "" {
+		claims.Act = &auth.ActorClaim{Subject: impersonatorId}
+		expiresAt, _ := ctx.Locals("tokenExpiresAt").(time.Time)
+		ttl = time.Until(expiresAt)
+	}
+
+	update(claims)
+
+	token, err := auth.SignClaimsWithTTL(claims, config, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	if usesCookieSession(ctx) {
+		setAccessTokenCookie(ctx, config, token)
+		return "", nil
+	}
+	return token, nil
+}
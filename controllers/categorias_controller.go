@@ -1,9 +1,9 @@
 package controllers
 
 import (
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
 	"github.com/gofiber/fiber/v2"
-	"github.com/maiscrianca/backend/models"
-	"github.com/maiscrianca/backend/repository"
 )
 
 // GetCategorias retorna todas as categorias
@@ -19,6 +19,17 @@ func GetCategorias(c *fiber.Ctx) error {
 		})
 	}
 
+	// Categorias bloqueadas pelos controles parentais não aparecem para o perfil infantil
+	if profile, _ := c.Locals("childProfile").(*models.ChildProfile); profile != nil {
+		allowed := make([]models.Categoria, 0, len(categorias))
+		for _, categoria := range categorias {
+			if !profile.Controls.BlocksCategoria(categoria.ID) {
+				allowed = append(allowed, categoria)
+			}
+		}
+		categorias = allowed
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    categorias,
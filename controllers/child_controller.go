@@ -0,0 +1,636 @@
+package controllers
+
+import (
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// maxChildProfiles limita quantos perfis infantis uma conta pode ter
+	maxChildProfiles = 8
+	// maxChildNameLength limita o nome do perfil infantil
+	maxChildNameLength = 60
+	// maxChildPinFailures define quantos PINs errados um perfil tolera antes do bloqueio
+	maxChildPinFailures = 5
+	// maxParentalFailures define quantas senhas ou PINs do responsável errados a conta tolera antes do bloqueio
+	maxParentalFailures = 5
+	// maxDailyLimitMinutes e maxBlockedCategorias limitam os controles parentais aceitos
+	maxDailyLimitMinutes = 24 * 60
+	maxBlockedCategorias = 100
+)
+
+// childPinPattern aceita PINs de 4 a 6 dígitos
+var childPinPattern = regexp.MustCompile(`^[0-9]{4,6}$`)
+
+// ChildController gerencia os perfis infantis da conta e a seleção do perfil que está lendo
+type ChildController struct {
+	Config *configs.Config
+}
+
+// NewChildController cria uma nova instância de ChildController
+func NewChildController(config *configs.Config) *ChildController {
+	return &ChildController{
+		Config: config,
+	}
+}
+
+// childProfileRequest representa os dados para criar ou editar um perfil infantil.
+// Informe a data de nascimento (AAAA-MM-DD) ou a faixa etária.
+type childProfileRequest struct {
+	Name      string `json:"name"`
+	BirthDate string `json:"birthDate"`
+	AgeBand   string `json:"ageBand"`
+	Avatar    string `json:"avatar"`
+	// Pin define um novo PIN de 4 a 6 dígitos; RemovePin retira o PIN do perfil
+	Pin       string `json:"pin"`
+	RemovePin bool   `json:"removePin"`
+}
+
+// parentCredentialRequest é a senha da conta ou o PIN do responsável que autoriza ações sobre os controles parentais
+type parentCredentialRequest struct {
+	Password    string `json:"password"`
+	ParentalPin string `json:"parentalPin"`
+}
+
+// parentalControlsRequest representa os controles parentais de um perfil infantil; nulo remove o limite
+type parentalControlsRequest struct {
+	parentCredentialRequest
+	MaxClassificacao    *int     `json:"maxClassificacao"`
+	BlockedCategoriaIds []string `json:"blockedCategoriaIds"`
+	DailyLimitMinutes   *int     `json:"dailyLimitMinutes"`
+}
+
+// ListChildren retorna os perfis infantis da conta e os avatares que podem ser escolhidos
+func (c *ChildController) ListChildren(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	profiles, err := repository.GetChildProfilesByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar perfis infantis: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar perfis infantis",
+		})
+	}
+
+	selected, _ := ctx.Locals("childProfileId").(string)
+	return ctx.JSON(fiber.Map{
+		"success":    true,
+		"data":       profiles,
+		"selectedId": selected,
+		"avatars":    models.ChildAvatars,
+	})
+}
+
+// GetChild retorna um perfil infantil da conta
+func (c *ChildController) GetChild(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	profile, err := repository.GetChildProfile(userId, ctx.Params("id"))
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Perfil infantil não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar perfil infantil: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar perfil infantil",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    profile,
+	})
+}
+
+// CreateChild cria um perfil infantil na conta
+func (c *ChildController) CreateChild(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	profile, req, errMsg := parseChildProfileRequest(ctx)
+	if errMsg != "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+	profile.UserId = userId
+
+	count, err := repository.CountChildProfiles(userId)
+	if err != nil {
+		log.Printf("Erro ao contar perfis infantis: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar perfil infantil",
+		})
+	}
+	if count >= maxChildProfiles {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A conta atingiu o limite de perfis infantis",
+		})
+	}
+
+	pinHash, err := hashChildPin(req.Pin)
+	if err != nil {
+		log.Printf("Erro ao gerar hash do PIN: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar perfil infantil",
+		})
+	}
+
+	if err := repository.CreateChildProfile(profile, pinHash); err != nil {
+		log.Printf("Erro ao criar perfil infantil: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar perfil infantil",
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Perfil infantil criado com sucesso",
+		"data":    profile,
+	})
+}
+
+// UpdateChild altera os dados e o PIN de um perfil infantil
+func (c *ChildController) UpdateChild(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	profile, req, errMsg := parseChildProfileRequest(ctx)
+	if errMsg != "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+	profile.ID = ctx.Params("id")
+	profile.UserId = userId
+
+	if err := repository.UpdateChildProfile(profile); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Perfil infantil não encontrado",
+			})
+		}
+		log.Printf("Erro ao atualizar perfil infantil: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atualizar perfil infantil",
+		})
+	}
+
+	if req.Pin != "" || req.RemovePin {
+		pinHash, err := hashChildPin(req.Pin)
+		if err == nil {
+			err = repository.SetChildProfilePin(userId, profile.ID, pinHash)
+		}
+		if err != nil {
+			log.Printf("Erro ao alterar PIN do perfil infantil: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao alterar PIN do perfil infantil",
+			})
+		}
+		profile.HasPin = pinHash != ""
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Perfil infantil atualizado com sucesso",
+		"data":    profile,
+	})
+}
+
+// DeleteChild remove um perfil infantil com seu progresso de leitura e favoritos
+func (c *ChildController) DeleteChild(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	if err := repository.DeleteChildProfile(userId, ctx.Params("id")); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Perfil infantil não encontrado",
+			})
+		}
+		log.Printf("Erro ao remover perfil infantil: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao remover perfil infantil",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Perfil infantil removido com sucesso",
+	})
+}
+
+// SelectChild emite um novo token com o perfil infantil escolhido (claim childProfileId).
+// Perfis com PIN exigem o PIN, com bloqueio temporário após erros seguidos.
+func (c *ChildController) SelectChild(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	childProfileId := ctx.Params("id")
+
+	var req struct {
+		Pin string `json:"pin"`
+	}
+	_ = ctx.BodyParser(&req)
+
+	profile, err := repository.GetChildProfile(userId, childProfileId)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Perfil infantil não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar perfil infantil: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	// Trocar de perfil passaria por cima dos controles parentais: é preciso sair do atual antes
+	if current, _ := ctx.Locals("childProfileId").(string); current != "" && current != childProfileId {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Saia do perfil infantil atual antes de selecionar outro",
+			"code":  "CHILD_MODE",
+		})
+	}
+
+	if profile.HasPin {
+		if ok, err := c.verifyChildPin(ctx, userId, childProfileId, req.Pin); !ok {
+			return err
+		}
+	}
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	token, err := c.switchChildProfile(ctx, user, childProfileId)
+	if err != nil {
+		log.Printf("Erro ao gerar token: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar token de autenticação",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success":      true,
+		"token":        token,
+		"childProfile": profile,
+	})
+}
+
+// DeselectChild emite um novo token sem perfil infantil, voltando à conta do responsável.
+// Exige a senha ou o PIN do responsável, quando a conta tiver algum, para que a criança não saia sozinha.
+func (c *ChildController) DeselectChild(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req parentCredentialRequest
+	_ = ctx.BodyParser(&req)
+
+	if current, _ := ctx.Locals("childProfileId").(string); current != "" {
+		if ok, err := verifyParentCredential(ctx, userId, req, false); !ok {
+			return err
+		}
+	}
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	token, err := c.switchChildProfile(ctx, user, "")
+	if err != nil {
+		log.Printf("Erro ao gerar token: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar token de autenticação",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"token":   token,
+	})
+}
+
+// UpdateControls define os controles parentais do perfil infantil: classificação indicativa máxima,
+// categorias bloqueadas e limite diário de leitura. Exige a senha ou o PIN do responsável.
+func (c *ChildController) UpdateControls(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	childProfileId := ctx.Params("id")
+
+	var req parentalControlsRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	controls, errMsg := parseParentalControls(&req)
+	if errMsg != "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+
+	if ok, err := verifyParentCredential(ctx, userId, req.parentCredentialRequest, true); !ok {
+		return err
+	}
+
+	if err := repository.UpdateChildProfileControls(userId, childProfileId, controls); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Perfil infantil não encontrado",
+			})
+		}
+		log.Printf("Erro ao atualizar controles parentais: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atualizar controles parentais",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditParentalControlsSet, userId, map[string]interface{}{
+		"childProfileId":      childProfileId,
+		"maxClassificacao":    controls.MaxClassificacao,
+		"blockedCategoriaIds": controls.BlockedCategoriaIds,
+		"dailyLimitMinutes":   controls.DailyLimitMinutes,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Controles parentais atualizados com sucesso",
+		"data":    controls,
+	})
+}
+
+// SetParentalPin define ou remove (pin vazio) o PIN do responsável. Se a conta já tiver senha
+// ou PIN, um deles precisa ser informado.
+func (c *ChildController) SetParentalPin(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		parentCredentialRequest
+		Pin string `json:"pin"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+	if req.Pin != "" && !childPinPattern.MatchString(req.Pin) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "O PIN deve ter de 4 a 6 dígitos",
+		})
+	}
+
+	if ok, err := verifyParentCredential(ctx, userId, req.parentCredentialRequest, false); !ok {
+		return err
+	}
+
+	pinHash, err := hashChildPin(req.Pin)
+	if err == nil {
+		err = repository.SetParentalPin(userId, pinHash)
+	}
+	if err != nil {
+		log.Printf("Erro ao alterar PIN do responsável: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao alterar PIN do responsável",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditParentalPinChanged, userId, map[string]interface{}{
+		"removed": pinHash == "",
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "PIN do responsável atualizado com sucesso",
+	})
+}
+
+// switchChildProfile grava o perfil infantil na sessão, para que a renovação do token o mantenha,
+// e emite o novo token de acesso com ele; vazio volta à conta do responsável. Ao entrar no perfil
+// infantil o token atual é revogado, para que uma cópia dele (outra aba, armazenamento do app)
+// não continue com o acesso irrestrito do responsável.
+func (c *ChildController) switchChildProfile(ctx *fiber.Ctx, user *models.User, childProfileId string) (string, error) {
+	if sessionId, _ := ctx.Locals("sessionId").(string); sessionId != "" {
+		if err := repository.SetSessionChildProfile(sessionId, childProfileId); err != nil {
+			return "", err
+		}
+	}
+
+	if childProfileId != "" {
+		if jti, _ := ctx.Locals("tokenId").(string); jti != "" {
+			expiresAt, _ := ctx.Locals("tokenExpiresAt").(time.Time)
+			if err := repository.RevokeAccessToken(jti, expiresAt); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	return reissueAccessToken(ctx, c.Config, user, func(claims *auth.JWTClaims) {
+		claims.ChildProfileID = childProfileId
+	})
+}
+
+// verifyChildPin confere o PIN do perfil infantil e aplica o bloqueio após erros seguidos.
+// Quando o PIN não confere, ok é false e a resposta de erro já foi escrita; err é o que o handler deve retornar.
+func (c *ChildController) verifyChildPin(ctx *fiber.Ctx, userId string, childProfileId string, pin string) (ok bool, err error) {
+	return verifyThrottled(ctx, childPinThrottleKey(childProfileId), maxChildPinFailures, func() (bool, error) {
+		pinHash, err := repository.GetChildProfilePinHash(userId, childProfileId)
+		if err != nil {
+			return false, err
+		}
+		return pin != "" && bcrypt.CompareHashAndPassword([]byte(pinHash), []byte(pin)) == nil, nil
+	}, fiber.Map{
+		"error": "PIN incorreto",
+		"code":  "CHILD_PIN_INVALID",
+	})
+}
+
+// verifyParentCredential confere a senha da conta ou o PIN do responsável, com bloqueio após erros seguidos.
+// Contas sem senha nem PIN passam quando required é false; com required, precisam definir um PIN antes.
+// Quando não confere, ok é false e a resposta de erro já foi escrita; err é o que o handler deve retornar.
+func verifyParentCredential(ctx *fiber.Ctx, userId string, req parentCredentialRequest, required bool) (ok bool, err error) {
+	passwordHash, err := repository.GetUserPasswordHash(userId)
+	var pinHash string
+	if err == nil {
+		pinHash, err = repository.GetParentalPinHash(userId)
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar credenciais do responsável: %v", err)
+		return false, ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if passwordHash == "" && pinHash == "" {
+		if !required {
+			return true, nil
+		}
+		return false, ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Defina um PIN do responsável para alterar os controles parentais",
+			"code":  "PARENTAL_PIN_REQUIRED",
+		})
+	}
+
+	return verifyThrottled(ctx, parentalThrottleKey(userId), maxParentalFailures, func() (bool, error) {
+		if req.Password != "" && passwordHash != "" &&
+			bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(req.Password)) == nil {
+			return true, nil
+		}
+		return req.ParentalPin != "" && pinHash != "" &&
+			bcrypt.CompareHashAndPassword([]byte(pinHash), []byte(req.ParentalPin)) == nil, nil
+	}, fiber.Map{
+		"error": "Senha ou PIN do responsável incorretos",
+		"code":  "PARENT_CREDENTIAL_INVALID",
+	})
+}
+
+// verifyThrottled executa check respeitando o bloqueio da chave: cada falha é contada e, após
+// maxFailures seguidas, a chave é bloqueada; um acerto zera a contagem. Na falha a resposta
+// invalid é escrita com status 401.
+func verifyThrottled(ctx *fiber.Ctx, key string, maxFailures int, check func() (bool, error), invalid fiber.Map) (ok bool, err error) {
+	lockedUntil, err := repository.GetLoginLock(key)
+	if err != nil {
+		log.Printf("Erro ao verificar bloqueio: %v", err)
+		return false, ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if lockedUntil != nil {
+		return false, loginLockedResponse(ctx, *lockedUntil)
+	}
+
+	valid, err := check()
+	if err != nil {
+		log.Printf("Erro ao verificar credencial: %v", err)
+		return false, ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if !valid {
+		failures, err := repository.IncrementLoginFailures(key, loginFailureWindow)
+		if err != nil {
+			log.Printf("Erro ao registrar tentativa errada: %v", err)
+		} else if failures >= maxFailures {
+			if err := repository.LockLoginKey(key, time.Now().Add(loginLockDuration(failures, maxFailures))); err != nil {
+				log.Printf("Erro ao aplicar bloqueio: %v", err)
+			}
+		}
+		return false, ctx.Status(fiber.StatusUnauthorized).JSON(invalid)
+	}
+
+	if err := repository.ClearLoginFailures(key); err != nil {
+		log.Printf("Erro ao limpar tentativas erradas: %v", err)
+	}
+	return true, nil
+}
+
+// hashChildPin gera o hash do PIN; PIN vazio resulta em hash vazio (perfil sem PIN)
+func hashChildPin(pin string) (string, error) {
+	if pin == "" {
+		return "", nil
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// parseParentalControls valida os controles parentais recebidos, removendo categorias repetidas.
+// Retorna a mensagem de erro para o cliente quando os dados são inválidos.
+func parseParentalControls(req *parentalControlsRequest) (models.ParentalControls, string) {
+	controls := models.ParentalControls{
+		MaxClassificacao:    req.MaxClassificacao,
+		DailyLimitMinutes:   req.DailyLimitMinutes,
+		BlockedCategoriaIds: []string{},
+	}
+
+	if req.MaxClassificacao != nil && !models.IsValidClassificacao(*req.MaxClassificacao) {
+		return controls, "Classificação indicativa inválida: use 0 (livre), 10, 12, 14, 16 ou 18"
+	}
+	if req.DailyLimitMinutes != nil && (*req.DailyLimitMinutes < 1 || *req.DailyLimitMinutes > maxDailyLimitMinutes) {
+		return controls, "O limite diário deve ser de 1 a 1440 minutos"
+	}
+	if len(req.BlockedCategoriaIds) > maxBlockedCategorias {
+		return controls, "Categorias bloqueadas demais"
+	}
+
+	seen := map[string]bool{}
+	for _, id := range req.BlockedCategoriaIds {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			return controls, "Categoria bloqueada inválida"
+		}
+		if !seen[id] {
+			seen[id] = true
+			controls.BlockedCategoriaIds = append(controls.BlockedCategoriaIds, id)
+		}
+	}
+
+	return controls, ""
+}
+
+// parseChildProfileRequest lê e valida o corpo de criação ou edição de um perfil infantil.
+// Retorna a mensagem de erro para o cliente quando os dados são inválidos.
+func parseChildProfileRequest(ctx *fiber.Ctx) (*models.ChildProfile, *childProfileRequest, string) {
+	var req childProfileRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, nil, "Erro ao processar dados: " + err.Error()
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > maxChildNameLength {
+		return nil, nil, "O nome é obrigatório e deve ter até 60 caracteres"
+	}
+	// Só os avatares ilustrados: o perfil infantil não aceita imagens de endereços externos
+	req.Avatar = strings.TrimSpace(req.Avatar)
+	if req.Avatar != "" && !models.IsValidChildAvatar(req.Avatar) {
+		return nil, nil, "Avatar inválido: escolha um dos avatares disponíveis"
+	}
+	if req.Pin != "" && !childPinPattern.MatchString(req.Pin) {
+		return nil, nil, "O PIN deve ter de 4 a 6 dígitos"
+	}
+
+	profile := &models.ChildProfile{
+		Name:   req.Name,
+		Avatar: req.Avatar,
+	}
+
+	switch {
+	case req.BirthDate != "":
+		birthDate, err := time.Parse("2006-01-02", req.BirthDate)
+		if err != nil {
+			return nil, nil, "A data de nascimento deve estar no formato AAAA-MM-DD"
+		}
+		if models.AgeBandFor(birthDate, time.Now()) == "" {
+			return nil, nil, "Perfis infantis são para menores de 18 anos"
+		}
+		profile.BirthDate = &birthDate
+		profile.AgeBand = models.AgeBandFor(birthDate, time.Now())
+	case req.AgeBand != "":
+		if !models.IsValidAgeBand(req.AgeBand) {
+			return nil, nil, "Faixa etária inválida"
+		}
+		profile.AgeBand = req.AgeBand
+	default:
+		return nil, nil, "Informe a data de nascimento ou a faixa etária"
+	}
+
+	return profile, &req, ""
+}
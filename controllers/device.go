@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"strings"
+)
+
+// deviceFromUserAgent descreve o navegador e o sistema do user-agent de forma legível,
+// como "Chrome no Android", para a listagem de sessões
+func deviceFromUserAgent(userAgent string) string {
+	if userAgent == "" {
+		return "Dispositivo desconhecido"
+	}
+
+	// A ordem importa: vários navegadores também se identificam como Chrome ou Safari
+	browser := "Navegador desconhecido"
+	for _, b := range []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"SamsungBrowser/", "Samsung Internet"},
+		{"Firefox/", "Firefox"},
+		{"CriOS/", "Chrome"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"okhttp/", "Aplicativo Android"},
+		{"CFNetwork/", "Aplicativo iOS"},
+	} {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+
+	system := ""
+	for _, s := range []struct{ token, name string }{
+		{"iPad", "iPad"},
+		{"iPhone", "iPhone"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	} {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	if system == "" {
+		return browser
+	}
+	return browser + " no " + system
+}
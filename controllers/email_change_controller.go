@@ -0,0 +1,264 @@
+package controllers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/mail"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// RequestEmailChange inicia a troca de email do usuário autenticado. O novo endereço recebe o link
+// de confirmação e o endereço atual recebe um aviso com um link para desfazer a troca.
+// Contas com senha precisam reconfirmá-la, com as tentativas erradas limitadas como na troca de
+// senha; contas só com login social confirmam pela sessão.
+func (c *AuthController) RequestEmailChange(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Password string `json:"password"`
+		NewEmail string `json:"newEmail"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	req.NewEmail = strings.TrimSpace(req.NewEmail)
+	if !isValidEmail(req.NewEmail) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email inválido",
+		})
+	}
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if req.NewEmail == user.Email {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "O novo email deve ser diferente do atual",
+		})
+	}
+
+	hashedPassword, err := repository.GetUserPasswordHash(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if hashedPassword != "" {
+		ok, err := verifyThrottled(ctx, passwordThrottleKey(userId), maxPasswordFailures, func() (bool, error) {
+			return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password)) == nil, nil
+		}, fiber.Map{"error": "Senha incorreta"})
+		if !ok {
+			return err
+		}
+	}
+
+	recent, err := repository.CountRecentUserTokens(userId, models.TokenEmailChange, time.Now().Add(-emailChangeInterval))
+	if err != nil {
+		log.Printf("Erro ao verificar pedidos de troca de email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if recent > 0 {
+		return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Aguarde um minuto antes de solicitar uma nova troca de email",
+		})
+	}
+
+	// A mesma verificação do cadastro; o índice único garante a regra também na confirmação
+	inUse, err := repository.IsEmailInUse(req.NewEmail)
+	if err != nil {
+		log.Printf("Erro ao verificar email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if inUse {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Este email já está em uso",
+		})
+	}
+
+	confirmToken, confirmHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar link de troca de email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao solicitar troca de email",
+		})
+	}
+	undoToken, undoHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar link de troca de email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao solicitar troca de email",
+		})
+	}
+
+	// Um novo pedido invalida o link de confirmação anterior, mas os links de desfazer
+	// já enviados continuam valendo para que o dono do endereço antigo não perca o acesso
+	_, err = repository.CreateUserToken(userId, models.TokenEmailChange, confirmHash, req.NewEmail, time.Now().Add(emailChangeExpiration))
+	if err == nil {
+		_, err = repository.AddUserToken(userId, models.TokenEmailChangeUndo, undoHash, user.Email, time.Now().Add(emailChangeUndoExpiration))
+	}
+	if err != nil {
+		log.Printf("Erro ao salvar link de troca de email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao solicitar troca de email",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditEmailChangeRequested, userId, map[string]interface{}{
+		"email":         req.NewEmail,
+		"previousEmail": user.Email,
+	})
+
+	c.sendMail(mail.Message{
+		To:      req.NewEmail,
+		Subject: "Confirme seu novo email - Mais Criança",
+		Body: "Olá, " + user.Name + "!\n\n" +
+			"Para passar a usar este endereço na sua conta, acesse:\n\n" +
+			c.Config.AppURL + "/confirmar-email?token=" + confirmToken + "\n\n" +
+			"O link é válido por 24 horas e pode ser usado apenas uma vez. " +
+			"Se você não fez este pedido, ignore este email.",
+	})
+	c.sendMail(mail.Message{
+		To:      user.Email,
+		Subject: "Pedido de troca de email - Mais Criança",
+		Body: "Olá, " + user.Name + "!\n\n" +
+			"Recebemos um pedido para trocar o email da sua conta para " + req.NewEmail + ". " +
+			"A troca só acontece depois que o novo endereço for confirmado.\n\n" +
+			"Se você não fez este pedido, desfaça a troca e proteja sua conta em:\n\n" +
+			c.Config.AppURL + "/desfazer-troca-email?token=" + undoToken + "\n\n" +
+			"O link é válido por 7 dias e também desfaz a troca se ela já tiver sido confirmada.",
+	})
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Enviamos um link de confirmação para o novo email",
+	})
+}
+
+// ConfirmEmailChange conclui a troca a partir do link enviado ao novo email
+func (c *AuthController) ConfirmEmailChange(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token de confirmação é obrigatório",
+		})
+	}
+
+	token, err := repository.GetValidUserToken(auth.HashToken(req.Token), models.TokenEmailChange)
+	var user *models.User
+	if err == nil {
+		user, err = repository.GetUserById(token.UserId)
+	}
+	if err == nil {
+		err = repository.ChangeUserEmail(token, &models.AuditLog{
+			ActorId:      token.UserId,
+			Action:       models.AuditEmailChanged,
+			TargetUserId: token.UserId,
+			Details: map[string]interface{}{
+				"email":         token.Data,
+				"previousEmail": user.Email,
+			},
+			IP: ctx.IP(),
+		})
+	}
+	switch err {
+	case nil:
+	case repository.ErrNotFound:
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Link de confirmação inválido ou expirado",
+		})
+	case repository.ErrDuplicate:
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Este email já está em uso",
+		})
+	default:
+		log.Printf("Erro ao trocar email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao trocar email",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Email alterado com sucesso",
+	})
+}
+
+// UndoEmailChange devolve a conta ao email anterior a partir do link enviado a ele. As sessões são
+// encerradas e o usuário recebe no email restaurado um link para definir uma nova senha.
+func (c *AuthController) UndoEmailChange(ctx *fiber.Ctx) error {
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token é obrigatório",
+		})
+	}
+
+	token, err := repository.GetValidUserToken(auth.HashToken(req.Token), models.TokenEmailChangeUndo)
+	var user *models.User
+	if err == nil {
+		user, err = repository.GetUserById(token.UserId)
+	}
+	if err == nil {
+		err = repository.UndoEmailChange(token, &models.AuditLog{
+			ActorId:      token.UserId,
+			Action:       models.AuditEmailChangeUndone,
+			TargetUserId: token.UserId,
+			Details: map[string]interface{}{
+				"email":         token.Data,
+				"previousEmail": user.Email,
+			},
+			IP: ctx.IP(),
+		})
+	}
+	switch err {
+	case nil:
+	case repository.ErrNotFound:
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Link inválido ou expirado",
+		})
+	case repository.ErrDuplicate:
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "O email anterior já está em uso por outra conta. Entre em contato com o suporte.",
+		})
+	default:
+		log.Printf("Erro ao desfazer troca de email: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao desfazer troca de email",
+		})
+	}
+
+	user.Email = token.Data
+	if err := c.sendPasswordResetEmail(user,
+		"A troca de email da sua conta foi desfeita e, por segurança, sua senha precisa ser trocada.",
+		"Se você não reconhece o pedido de troca, alguém pode conhecer sua senha antiga.",
+	); err != nil {
+		log.Printf("Erro ao gerar link de redefinição: %v", err)
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Troca de email desfeita. Enviamos um link para você definir uma nova senha.",
+	})
+}
@@ -0,0 +1,480 @@
+package controllers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// conviteExpiration define por quanto tempo um convite de espaço pode ser aceito
+const conviteExpiration = 7 * 24 * time.Hour
+
+// EspacoController gerencia espaços, membros e convites
+type EspacoController struct {
+	Config *configs.Config
+}
+
+// NewEspacoController cria uma nova instância de EspacoController
+func NewEspacoController(config *configs.Config) *EspacoController {
+	return &EspacoController{
+		Config: config,
+	}
+}
+
+// ListEspacos retorna os espaços do usuário autenticado
+func (c *EspacoController) ListEspacos(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	espacos, err := repository.GetEspacosByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar espaços: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar espaços",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    espacos,
+	})
+}
+
+// CreateEspaco cria um espaço e vincula o usuário autenticado como ADMIN dele
+func (c *EspacoController) CreateEspaco(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	espaco := new(models.Espaco)
+	if err := ctx.BodyParser(espaco); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	espaco.Nome = strings.TrimSpace(espaco.Nome)
+	if espaco.Nome == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "O nome do espaço é obrigatório",
+		})
+	}
+
+	if err := repository.CreateEspaco(espaco, userId); err != nil {
+		log.Printf("Erro ao criar espaço: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar espaço",
+		})
+	}
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Espaço criado com sucesso",
+		"data":    espaco,
+	})
+}
+
+// UpdateEspaco atualiza os dados do espaço
+func (c *EspacoController) UpdateEspaco(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+
+	var req struct {
+		Nome      string `json:"nome"`
+		Exigir2FA *bool  `json:"exigir2FA"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	req.Nome = strings.TrimSpace(req.Nome)
+	if req.Nome == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "O nome do espaço é obrigatório",
+		})
+	}
+
+	espaco, err := repository.GetEspacoById(espacoId)
+	if err == repository.ErrNotFound {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Espaço não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar espaço: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atualizar espaço",
+		})
+	}
+
+	espaco.Nome = req.Nome
+	if req.Exigir2FA != nil {
+		// Quem ativa a exigência precisa ter 2FA ativo, senão perderia o acesso ao próprio espaço
+		if *req.Exigir2FA && !espaco.Exigir2FA {
+			totp, err := repository.GetUserTOTP(userId)
+			if err != nil {
+				log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+				return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Erro ao atualizar espaço",
+				})
+			}
+			if totp.EnabledAt == nil {
+				return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Ative a autenticação em dois fatores antes de exigi-la no espaço",
+				})
+			}
+		}
+		espaco.Exigir2FA = *req.Exigir2FA
+	}
+
+	if err := repository.UpdateEspaco(espaco); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Espaço não encontrado",
+			})
+		}
+		log.Printf("Erro ao atualizar espaço: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atualizar espaço",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Espaço atualizado com sucesso",
+		"data":    espaco,
+	})
+}
+
+// SelectEspaco emite um novo token com o espaço escolhido como espaço ativo e o grava na sessão,
+// para que a renovação do token o mantenha
+func (c *EspacoController) SelectEspaco(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+
+	if sessionId, _ := ctx.Locals("sessionId").(string); sessionId != "" {
+		if err := repository.SetSessionEspaco(sessionId, espacoId); err != nil {
+			log.Printf("Erro ao gravar espaço da sessão: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+	}
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	token, err := reissueAccessToken(ctx, c.Config, user, func(claims *auth.JWTClaims) {
+		claims.EspacoID = espacoId
+	})
+	if err != nil {
+		log.Printf("Erro ao gerar token: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar token de autenticação",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success":    true,
+		"token":      token,
+		"espacoId":   espacoId,
+		"espacoRole": ctx.Locals("espacoRole"),
+	})
+}
+
+// ListMembros retorna os membros do espaço
+func (c *EspacoController) ListMembros(ctx *fiber.Ctx) error {
+	espacoId := ctx.Locals("espacoId").(string)
+
+	membros, err := repository.GetMembrosByEspacoId(espacoId)
+	if err != nil {
+		log.Printf("Erro ao buscar membros: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar membros do espaço",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    membros,
+	})
+}
+
+// UpdateMembroRole altera a role de um membro no espaço
+func (c *EspacoController) UpdateMembroRole(ctx *fiber.Ctx) error {
+	espacoId := ctx.Locals("espacoId").(string)
+	membroId := ctx.Params("userId")
+
+	var req struct {
+		Role models.Role `json:"role"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || !req.Role.IsValid() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Role inválida",
+		})
+	}
+
+	membro, err := repository.GetEspacoMembro(espacoId, membroId)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Membro não encontrado",
+		})
+	}
+
+	if membro.Role == models.ADMIN && req.Role != models.ADMIN {
+		if ok, err := c.hasOtherAdmin(espacoId); err != nil || !ok {
+			return c.lastAdminError(ctx, err)
+		}
+	}
+
+	if err := repository.UpdateEspacoMembroRole(espacoId, membroId, req.Role); err != nil {
+		log.Printf("Erro ao atualizar role do membro: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atualizar role do membro",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Role do membro atualizada com sucesso",
+	})
+}
+
+// RemoveMembro desvincula um membro do espaço
+func (c *EspacoController) RemoveMembro(ctx *fiber.Ctx) error {
+	espacoId := ctx.Locals("espacoId").(string)
+	membroId := ctx.Params("userId")
+
+	membro, err := repository.GetEspacoMembro(espacoId, membroId)
+	if err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Membro não encontrado",
+		})
+	}
+
+	if membro.Role == models.ADMIN {
+		if ok, err := c.hasOtherAdmin(espacoId); err != nil || !ok {
+			return c.lastAdminError(ctx, err)
+		}
+	}
+
+	if err := repository.RemoveEspacoMembro(espacoId, membroId); err != nil {
+		log.Printf("Erro ao remover membro: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao remover membro do espaço",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Membro removido com sucesso",
+	})
+}
+
+// CreateConvite convida um email para o espaço com a role informada
+func (c *EspacoController) CreateConvite(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+
+	var req struct {
+		Email string      `json:"email"`
+		Role  models.Role `json:"role"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados do convite inválidos",
+		})
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Role == "" {
+		req.Role = models.CLIENT
+	}
+	if req.Email == "" || !req.Role.IsValid() {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email e role válida são obrigatórios",
+		})
+	}
+
+	token, tokenHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar token de convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar convite",
+		})
+	}
+
+	convite := &models.ConviteEspaco{
+		EspacoId:    espacoId,
+		Email:       req.Email,
+		Role:        req.Role,
+		InvitedById: userId,
+		ExpiresAt:   time.Now().Add(conviteExpiration),
+	}
+
+	if err := repository.CreateConvite(convite, tokenHash); err != nil {
+		log.Printf("Erro ao criar convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar convite",
+		})
+	}
+
+	// O token só é exibido nesta resposta; no banco fica apenas o hash
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Convite criado com sucesso",
+		"data":    convite,
+		"token":   token,
+	})
+}
+
+// ListConvites retorna os convites pendentes do espaço
+func (c *EspacoController) ListConvites(ctx *fiber.Ctx) error {
+	espacoId := ctx.Locals("espacoId").(string)
+
+	convites, err := repository.GetConvitesPendentesByEspacoId(espacoId)
+	if err != nil {
+		log.Printf("Erro ao buscar convites: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar convites",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    convites,
+	})
+}
+
+// RevokeConvite revoga um convite pendente do espaço
+func (c *EspacoController) RevokeConvite(ctx *fiber.Ctx) error {
+	espacoId := ctx.Locals("espacoId").(string)
+
+	if err := repository.RevokeConvite(ctx.Params("conviteId"), espacoId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Convite pendente não encontrado",
+			})
+		}
+		log.Printf("Erro ao revogar convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao revogar convite",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Convite revogado com sucesso",
+	})
+}
+
+// ListMeusConvites retorna os convites pendentes endereçados ao email do usuário autenticado
+func (c *EspacoController) ListMeusConvites(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	convites, err := repository.GetConvitesPendentesByEmail(user.Email)
+	if err != nil {
+		log.Printf("Erro ao buscar convites: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar convites",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    convites,
+	})
+}
+
+// AcceptConvite aceita um convite e vincula o usuário autenticado ao espaço
+func (c *EspacoController) AcceptConvite(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Token string `json:"token"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Token == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token do convite é obrigatório",
+		})
+	}
+
+	convite, err := repository.GetConviteByTokenHash(auth.HashToken(req.Token))
+	if err != nil || convite.AcceptedAt != nil || convite.RevokedAt != nil || time.Now().After(convite.ExpiresAt) {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Convite inválido ou expirado",
+		})
+	}
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	if !strings.EqualFold(user.Email, convite.Email) {
+		return ctx.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Este convite foi enviado para outro email",
+		})
+	}
+
+	if err := repository.AcceptConvite(convite, userId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Convite inválido ou expirado",
+			})
+		}
+		log.Printf("Erro ao aceitar convite: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao aceitar convite",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success":  true,
+		"message":  "Convite aceito com sucesso",
+		"espacoId": convite.EspacoId,
+	})
+}
+
+// hasOtherAdmin informa se o espaço continuará com algum ADMIN após remover ou rebaixar um deles
+func (c *EspacoController) hasOtherAdmin(espacoId string) (bool, error) {
+	count, err := repository.CountEspacoAdmins(espacoId)
+	if err != nil {
+		return false, err
+	}
+	return count > 1, nil
+}
+
+// lastAdminError responde quando a operação deixaria o espaço sem nenhum ADMIN
+func (c *EspacoController) lastAdminError(ctx *fiber.Ctx, err error) error {
+	if err != nil {
+		log.Printf("Erro ao contar administradores do espaço: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "O espaço precisa de pelo menos um administrador",
+	})
+}
@@ -0,0 +1,15 @@
+package controllers
+
+import (
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJWKS publica as chaves públicas usadas para verificar os tokens de acesso
+func GetJWKS(c *fiber.Ctx) error {
+	// Permite cache curto para que chaves novas apareçam logo após uma rotação
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(fiber.Map{
+		"keys": auth.JWKS(),
+	})
+}
@@ -1,12 +1,14 @@
 package controllers
 
 import (
-	"github.com/gofiber/fiber/v2"
-	"github.com/maiscrianca/backend/models"
-	"github.com/maiscrianca/backend/repository"
+	"context"
+	"log"
 	"os"
-	"strconv"
-	"github.com/vercel/blob"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/WBianchi/maiscrianca/storage"
+	"github.com/gofiber/fiber/v2"
 )
 
 // GetLivros retorna todos os livros
@@ -22,6 +24,17 @@ func GetLivros(c *fiber.Ctx) error {
 		})
 	}
 
+	// Perfis infantis só veem livros publicados que os controles parentais permitem
+	if profile, _ := c.Locals("childProfile").(*models.ChildProfile); profile != nil {
+		allowed := make([]models.Livro, 0, len(livros))
+		for i := range livros {
+			if childAllows(c, &livros[i]) {
+				allowed = append(allowed, livros[i])
+			}
+		}
+		livros = allowed
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    livros,
@@ -34,12 +47,19 @@ func GetLivro(c *fiber.Ctx) error {
 	espacoId := c.Locals("espacoId").(string)
 
 	livro, err := repository.GetLivroById(id, espacoId)
-	if err != nil {
+	if err != nil || !childAllows(c, livro) {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Livro não encontrado",
 		})
 	}
 
+	if err := recordChildReading(c, livro); err != nil {
+		log.Printf("Erro ao registrar tempo de leitura: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
 	return c.JSON(fiber.Map{
 		"success": true,
 		"data":    livro,
@@ -61,6 +81,12 @@ func CreateLivro(c *fiber.Ctx) error {
 		})
 	}
 
+	if !models.IsValidClassificacao(livro.Classificacao) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Classificação indicativa inválida: use 0 (livre), 10, 12, 14, 16 ou 18",
+		})
+	}
+
 	// Adicionar o espacoId ao livro
 	livro.EspacoId = espacoId
 
@@ -101,6 +127,12 @@ func UpdateLivro(c *fiber.Ctx) error {
 		})
 	}
 
+	if !models.IsValidClassificacao(livroUpdate.Classificacao) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Classificação indicativa inválida: use 0 (livre), 10, 12, 14, 16 ou 18",
+		})
+	}
+
 	// Garantir que o ID e o espacoId corretos sejam usados
 	livroUpdate.ID = id
 	livroUpdate.EspacoId = espacoId
@@ -145,6 +177,55 @@ func DeleteLivro(c *fiber.Ctx) error {
 	})
 }
 
+// DownloadLivro retorna o endereço do arquivo do livro para download
+func DownloadLivro(c *fiber.Ctx) error {
+	id := c.Params("id")
+	espacoId := c.Locals("espacoId").(string)
+
+	livro, err := repository.GetLivroById(id, espacoId)
+	if err != nil || !childAllows(c, livro) {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Livro não encontrado",
+		})
+	}
+
+	if livro.Arquivo == "" {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Este livro não possui arquivo para download",
+		})
+	}
+
+	if err := recordChildReading(c, livro); err != nil {
+		log.Printf("Erro ao registrar tempo de leitura: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"url":     livro.Arquivo,
+	})
+}
+
+// childAllows informa se o perfil infantil selecionado, quando houver, pode ver o livro:
+// só livros publicados e permitidos pelos controles parentais
+func childAllows(c *fiber.Ctx, livro *models.Livro) bool {
+	profile, _ := c.Locals("childProfile").(*models.ChildProfile)
+	return profile == nil || (livro.Publicado && profile.Controls.Allows(livro))
+}
+
+// recordChildReading conta a abertura ou o download do livro como atividade de leitura do perfil
+// infantil selecionado, para o limite diário; sem perfil selecionado não há o que registrar
+func recordChildReading(c *fiber.Ctx, livro *models.Livro) error {
+	profile, _ := c.Locals("childProfile").(*models.ChildProfile)
+	if profile == nil {
+		return nil
+	}
+	_, err := repository.RecordReadingActivity(profile.ID, livro.ID, maxReadingSecondsPerUpdate)
+	return err
+}
+
 // UploadCapa faz upload da capa do livro usando Vercel Blob
 func UploadCapa(c *fiber.Ctx) error {
 	return handleBlobUpload(c, "book-covers")
@@ -162,9 +243,9 @@ func UploadPagina(c *fiber.Ctx) error {
 
 // handleBlobUpload gerencia o upload para o Vercel Blob
 func handleBlobUpload(c *fiber.Ctx, folder string) error {
-	// Obter token do Vercel Blob
-	blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
-	if blobToken == "" {
+	// Preparar cliente do Vercel Blob
+	client := blobStorage()
+	if client == nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Token do Vercel Blob não configurado",
 		})
@@ -187,16 +268,8 @@ func handleBlobUpload(c *fiber.Ctx, folder string) error {
 	}
 	defer fileContent.Close()
 
-	// Preparar cliente do Vercel Blob
-	client := blob.NewClient(blobToken)
-
 	// Upload do arquivo para o Vercel Blob
-	uploadResult, err := client.Upload(c.Context(), &blob.UploadOptions{
-		Data:        fileContent,
-		Filename:    file.Filename,
-		ContentType: file.Header["Content-Type"][0],
-		Folder:      folder,
-	})
+	uploadResult, err := client.Upload(c.Context(), folder+"/"+file.Filename, fileContent, file.Header.Get("Content-Type"))
 
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -210,3 +283,27 @@ func handleBlobUpload(c *fiber.Ctx, folder string) error {
 		"url":     uploadResult.URL,
 	})
 }
+
+// blobStorage retorna o cliente do Vercel Blob com o token de BLOB_READ_WRITE_TOKEN, ou nil sem token
+func blobStorage() *storage.VercelBlob {
+	blobToken := os.Getenv("BLOB_READ_WRITE_TOKEN")
+	if blobToken == "" {
+		return nil
+	}
+	return storage.NewVercelBlob(blobToken)
+}
+
+// deleteBlobs remove arquivos que deixaram de ser usados; falhas só são registradas no log
+func deleteBlobs(urls []string) {
+	if len(urls) == 0 {
+		return
+	}
+	client := blobStorage()
+	if client == nil {
+		log.Printf("Token do Vercel Blob não configurado: %d arquivo(s) não removido(s)", len(urls))
+		return
+	}
+	if err := client.Delete(context.Background(), urls...); err != nil {
+		log.Printf("Erro ao remover arquivos do Vercel Blob: %v", err)
+	}
+}
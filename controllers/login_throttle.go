@@ -0,0 +1,129 @@
+package controllers
+
+import (
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// maxLoginFailuresPerAccount define quantas falhas seguidas uma conta tolera antes do bloqueio
+	maxLoginFailuresPerAccount = 5
+	// maxLoginFailuresPerIP define quantas falhas seguidas um IP tolera antes do bloqueio
+	maxLoginFailuresPerIP = 20
+	// loginFailureWindow define após quanto tempo sem falhas o contador recomeça
+	loginFailureWindow = 15 * time.Minute
+	// loginLockBase é a duração do primeiro bloqueio, dobrada a cada nova falha
+	loginLockBase = time.Minute
+	// loginLockMax limita a duração de um bloqueio
+	loginLockMax = time.Hour
+)
+
+// dummyPasswordHash é comparado quando o email não existe, para que o tempo de
+// resposta não revele quais contas estão cadastradas
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, err := bcrypt.GenerateFromPassword([]byte("maiscrianca-dummy-password"), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Erro ao gerar hash de comparação: %v", err)
+	}
+	return hash
+})
+
+// ipThrottleKey retorna a chave de contagem de falhas de login do IP
+func ipThrottleKey(ip string) string {
+	return "ip:" + ip
+}
+
+// accountThrottleKey retorna a chave de contagem de falhas de login da conta
+func accountThrottleKey(email string) string {
+	return "account:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// magicLinkThrottleKey retorna a chave de contagem de pedidos de link de acesso do email
+func magicLinkThrottleKey(email string) string {
+	return "magic-link:" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// childPinThrottleKey retorna a chave de contagem de PINs errados do perfil infantil
+func childPinThrottleKey(childProfileId string) string {
+	return "child-pin:" + childProfileId
+}
+
+// parentalThrottleKey retorna a chave de contagem de senhas ou PINs do responsável errados
+func parentalThrottleKey(userId string) string {
+	return "parental:" + userId
+}
+
+// passwordThrottleKey retorna a chave de contagem de senhas atuais erradas na troca de senha
+func passwordThrottleKey(userId string) string {
+	return "password:" + userId
+}
+
+// loginLockDuration calcula o bloqueio exponencial para o número de falhas acima do limite
+func loginLockDuration(failures int, threshold int) time.Duration {
+	exponent := float64(failures - threshold)
+	lock := time.Duration(float64(loginLockBase) * math.Pow(2, exponent))
+	if lock <= 0 || lock > loginLockMax {
+		return loginLockMax
+	}
+	return lock
+}
+
+// loginLockedResponse responde a uma tentativa de login feita durante um bloqueio
+func loginLockedResponse(ctx *fiber.Ctx, lockedUntil time.Time) error {
+	retryAfter := int(math.Ceil(time.Until(lockedUntil).Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	ctx.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return ctx.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"error":      "Muitas tentativas de login. Tente novamente mais tarde.",
+		"retryAfter": retryAfter,
+	})
+}
+
+// registerLoginFailure contabiliza a falha para o IP e para a conta e aplica o bloqueio quando
+// algum limite é atingido. userId é vazio quando o email não corresponde a nenhuma conta.
+func registerLoginFailure(ctx *fiber.Ctx, email string, userId string) {
+	limits := []struct {
+		key       string
+		threshold int
+		action    string
+	}{
+		{ipThrottleKey(ctx.IP()), maxLoginFailuresPerIP, models.AuditIPLocked},
+		{accountThrottleKey(email), maxLoginFailuresPerAccount, models.AuditAccountLocked},
+	}
+
+	for _, limit := range limits {
+		failures, err := repository.IncrementLoginFailures(limit.key, loginFailureWindow)
+		if err != nil {
+			log.Printf("Erro ao registrar falha de login: %v", err)
+			continue
+		}
+		if failures < limit.threshold {
+			continue
+		}
+
+		lockedUntil := time.Now().Add(loginLockDuration(failures, limit.threshold))
+		if err := repository.LockLoginKey(limit.key, lockedUntil); err != nil {
+			log.Printf("Erro ao bloquear login: %v", err)
+			continue
+		}
+
+		log.Printf("Login bloqueado para %s até %s após %d falhas", limit.key, lockedUntil.Format(time.RFC3339), failures)
+		recordAudit(ctx, "", limit.action, userId, map[string]interface{}{
+			"key":         limit.key,
+			"failures":    failures,
+			"lockedUntil": lockedUntil,
+		})
+	}
+}
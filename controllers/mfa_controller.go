@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"log"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// totpIssuer é o nome exibido nos aplicativos autenticadores
+	totpIssuer = "Mais Criança"
+	// recoveryCodeCount define quantos códigos de recuperação são emitidos por vez
+	recoveryCodeCount = 10
+	// mfaChallengeExpiration define por quanto tempo o desafio de 2FA do login é válido
+	mfaChallengeExpiration = 5 * time.Minute
+)
+
+// MFAController gerencia a autenticação em dois fatores (TOTP) do usuário autenticado
+type MFAController struct {
+	Config *configs.Config
+}
+
+// NewMFAController cria uma nova instância de MFAController
+func NewMFAController(config *configs.Config) *MFAController {
+	return &MFAController{
+		Config: config,
+	}
+}
+
+// mfaCodeRequest representa o segundo fator informado pelo usuário: código TOTP ou código de recuperação
+type mfaCodeRequest struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recoveryCode"`
+}
+
+// GetStatus informa se o 2FA está ativo e quantos códigos de recuperação restam
+func (c *MFAController) GetStatus(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	totp, err := repository.GetUserTOTP(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	remaining := 0
+	if totp.EnabledAt != nil {
+		remaining, err = repository.CountUnusedRecoveryCodes(userId)
+		if err != nil {
+			log.Printf("Erro ao contar códigos de recuperação: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+	}
+
+	return ctx.JSON(fiber.Map{
+		"enabled":                totp.EnabledAt != nil,
+		"enabledAt":              totp.EnabledAt,
+		"recoveryCodesRemaining": remaining,
+	})
+}
+
+// Setup gera um novo segredo TOTP e devolve a URI de provisionamento para o QR code.
+// O 2FA só passa a valer depois da confirmação em Enable.
+func (c *MFAController) Setup(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	totp, err := repository.GetUserTOTP(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if totp.EnabledAt != nil {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A autenticação em dois fatores já está ativa",
+		})
+	}
+
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		log.Printf("Erro ao gerar segredo TOTP: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao configurar autenticação em dois fatores",
+		})
+	}
+
+	if err := repository.SetUserTOTPSecret(userId, secret); err != nil {
+		log.Printf("Erro ao salvar segredo TOTP: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao configurar autenticação em dois fatores",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"secret":          secret,
+		"provisioningUri": auth.TOTPProvisioningURI(secret, user.Email, totpIssuer),
+	})
+}
+
+// Enable confirma o segredo com um código do aplicativo, ativa o 2FA e emite os códigos de recuperação
+func (c *MFAController) Enable(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req mfaCodeRequest
+	if err := ctx.BodyParser(&req); err != nil || req.Code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Código de verificação é obrigatório",
+		})
+	}
+
+	totp, err := repository.GetUserTOTP(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if totp.EnabledAt != nil {
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A autenticação em dois fatores já está ativa",
+		})
+	}
+	if totp.Secret == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Inicie a configuração da autenticação em dois fatores primeiro",
+		})
+	}
+
+	step, ok := auth.ValidateTOTP(totp.Secret, req.Code, time.Now())
+	if !ok {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Código de verificação inválido",
+		})
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		log.Printf("Erro ao gerar códigos de recuperação: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao ativar autenticação em dois fatores",
+		})
+	}
+
+	if err := repository.EnableUserTOTP(userId, step, hashes); err != nil {
+		log.Printf("Erro ao ativar 2FA: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao ativar autenticação em dois fatores",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditMFAEnabled, userId, nil)
+
+	// Os códigos só são exibidos nesta resposta; no banco ficam apenas os hashes
+	return ctx.JSON(fiber.Map{
+		"success":       true,
+		"message":       "Autenticação em dois fatores ativada com sucesso",
+		"recoveryCodes": codes,
+	})
+}
+
+// Disable desativa o 2FA após reconfirmar a senha e o segundo fator
+func (c *MFAController) Disable(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Password string `json:"password"`
+		mfaCodeRequest
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Password == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Senha e código de verificação são obrigatórios",
+		})
+	}
+
+	hashedPassword, err := repository.GetUserPasswordHash(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password)) != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Senha incorreta",
+		})
+	}
+
+	totp, err := repository.GetUserTOTP(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if totp.EnabledAt == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A autenticação em dois fatores não está ativa",
+		})
+	}
+
+	ok, err := verifySecondFactor(ctx, totp, req.mfaCodeRequest)
+	if err != nil {
+		log.Printf("Erro ao verificar segundo fator: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Código de verificação inválido",
+		})
+	}
+
+	if err := repository.DisableUserTOTP(userId); err != nil {
+		log.Printf("Erro ao desativar 2FA: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao desativar autenticação em dois fatores",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditMFADisabled, userId, nil)
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Autenticação em dois fatores desativada com sucesso",
+	})
+}
+
+// RegenerateRecoveryCodes invalida os códigos de recuperação atuais e emite novos
+func (c *MFAController) RegenerateRecoveryCodes(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req mfaCodeRequest
+	if err := ctx.BodyParser(&req); err != nil || req.Code == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Código de verificação é obrigatório",
+		})
+	}
+
+	totp, err := repository.GetUserTOTP(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if totp.EnabledAt == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A autenticação em dois fatores não está ativa",
+		})
+	}
+
+	// Apenas o código do aplicativo é aceito aqui: um código de recuperação não deve gerar outros
+	ok, err := verifySecondFactor(ctx, totp, mfaCodeRequest{Code: req.Code})
+	if err != nil {
+		log.Printf("Erro ao verificar segundo fator: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if !ok {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Código de verificação inválido",
+		})
+	}
+
+	codes, hashes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		log.Printf("Erro ao gerar códigos de recuperação: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar códigos de recuperação",
+		})
+	}
+
+	if err := repository.ReplaceRecoveryCodes(userId, hashes); err != nil {
+		log.Printf("Erro ao salvar códigos de recuperação: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao gerar códigos de recuperação",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditRecoveryCodesNew, userId, nil)
+
+	return ctx.JSON(fiber.Map{
+		"success":       true,
+		"recoveryCodes": codes,
+	})
+}
+
+// verifySecondFactor valida o código TOTP (sem permitir reutilização) ou consome um código de recuperação
+func verifySecondFactor(ctx *fiber.Ctx, totp *models.UserTOTP, req mfaCodeRequest) (bool, error) {
+	if req.Code != "" {
+		step, ok := auth.ValidateTOTP(totp.Secret, req.Code, time.Now())
+		if !ok || step <= totp.LastStep {
+			return false, nil
+		}
+		err := repository.UseTOTPStep(totp.UserId, step)
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	if req.RecoveryCode != "" {
+		err := repository.UseRecoveryCode(totp.UserId, auth.HashRecoveryCode(req.RecoveryCode))
+		if err == repository.ErrNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		recordAudit(ctx, totp.UserId, models.AuditRecoveryCodeUsed, totp.UserId, nil)
+		return true, nil
+	}
+
+	return false, nil
+}
@@ -0,0 +1,429 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/oidc"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// oidcStateExpiration define por quanto tempo um login social iniciado pode ser concluído
+	oidcStateExpiration = 10 * time.Minute
+	// oidcStateCookiePath cobre o callback do login (/api/auth/oidc) e o do vínculo (/api/user/identities)
+	oidcStateCookiePath = "/api"
+)
+
+// OIDCController gerencia o login social (OpenID Connect) e os provedores vinculados à conta
+type OIDCController struct {
+	Config  *configs.Config
+	Auth    *AuthController
+	Clients map[string]*oidc.Client
+}
+
+// NewOIDCController cria uma nova instância de OIDCController com um cliente por provedor configurado
+func NewOIDCController(config *configs.Config, authController *AuthController) *OIDCController {
+	clients := map[string]*oidc.Client{}
+	for name, provider := range config.OIDCProviders {
+		clients[name] = oidc.NewClient(provider)
+	}
+
+	return &OIDCController{
+		Config:  config,
+		Auth:    authController,
+		Clients: clients,
+	}
+}
+
+// ListProviders retorna os provedores de login social habilitados
+func (c *OIDCController) ListProviders(ctx *fiber.Ctx) error {
+	providers := make([]string, 0, len(c.Clients))
+	for name := range c.Clients {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	return ctx.JSON(fiber.Map{
+		"providers": providers,
+	})
+}
+
+// StartLogin inicia o login social e retorna a URL de autorização do provedor
+func (c *OIDCController) StartLogin(ctx *fiber.Ctx) error {
+	return c.start(ctx, "")
+}
+
+// StartLink inicia o vínculo de um provedor à conta do usuário autenticado
+func (c *OIDCController) StartLink(ctx *fiber.Ctx) error {
+	return c.start(ctx, ctx.Locals("userId").(string))
+}
+
+// start gera state, nonce e PKCE, guarda-os e monta a URL de autorização. O hash do state também
+// vai para um cookie HttpOnly, conferido no callback, para que só o navegador que iniciou o fluxo
+// consiga concluí-lo.
+func (c *OIDCController) start(ctx *fiber.Ctx, userId string) error {
+	provider := ctx.Params("provider")
+	client, ok := c.Clients[provider]
+	if !ok {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Provedor de login não encontrado",
+		})
+	}
+
+	state, stateHash, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar state do login social: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao iniciar login social",
+		})
+	}
+	nonce, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		log.Printf("Erro ao gerar nonce do login social: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao iniciar login social",
+		})
+	}
+	verifier, challenge, err := oidc.GeneratePKCE()
+	if err != nil {
+		log.Printf("Erro ao gerar PKCE do login social: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao iniciar login social",
+		})
+	}
+
+	authorizationUrl, err := client.AuthCodeURL(ctx.UserContext(), state, nonce, challenge)
+	if err != nil {
+		log.Printf("Erro ao consultar provedor %s: %v", provider, err)
+		return ctx.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": "Provedor de login indisponível",
+		})
+	}
+
+	err = repository.CreateOIDCState(&models.OIDCState{
+		Provider:     provider,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		UserId:       userId,
+		ExpiresAt:    time.Now().Add(oidcStateExpiration),
+	}, stateHash)
+	if err != nil {
+		log.Printf("Erro ao salvar state do login social: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao iniciar login social",
+		})
+	}
+	ctx.Cookie(sessionCookie(c.Config, auth.OIDCStateCookie, stateHash, oidcStateCookiePath, oidcStateExpiration, true))
+
+	return ctx.JSON(fiber.Map{
+		"authorizationUrl": authorizationUrl,
+		"state":            state,
+	})
+}
+
+// oidcCallback é o resultado de um callback válido: o state consumido e o ID token validado
+type oidcCallback struct {
+	State     *models.OIDCState
+	Claims    *oidc.IDTokenClaims
+	UseCookie bool
+}
+
+// Callback conclui o login social com o code e o state recebidos pelo frontend, encontrando ou
+// criando o usuário pelo email verificado. O vínculo de provedores é concluído por LinkCallback.
+func (c *OIDCController) Callback(ctx *fiber.Ctx) error {
+	provider := ctx.Params("provider")
+
+	callback, errResponse := c.exchange(ctx, "")
+	if callback == nil {
+		return errResponse
+	}
+
+	user, err := c.findOrCreateUser(ctx, provider, callback.Claims)
+	if err != nil {
+		if fiberErr, ok := err.(*fiber.Error); ok {
+			return ctx.Status(fiberErr.Code).JSON(fiber.Map{
+				"error": fiberErr.Message,
+			})
+		}
+		log.Printf("Erro no login social: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	return c.Auth.completeLogin(ctx, user, callback.UseCookie)
+}
+
+// LinkCallback conclui o vínculo de um provedor à conta do usuário autenticado. Além do cookie do
+// navegador, a requisição precisa vir da própria conta que iniciou o vínculo: um link de
+// autorização enviado a outra pessoa não vincula a conta dela no provedor à de quem o gerou.
+func (c *OIDCController) LinkCallback(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	callback, errResponse := c.exchange(ctx, userId)
+	if callback == nil {
+		return errResponse
+	}
+
+	return c.link(ctx, userId, ctx.Params("provider"), callback.Claims)
+}
+
+// exchange confere o state com o cookie do navegador que iniciou o fluxo, consome-o e troca o code
+// pelo ID token no provedor. userId é o usuário que deve ter iniciado o fluxo, vazio no login.
+// Quando não há resultado, a resposta de erro já foi escrita e o segundo valor deve ser retornado.
+func (c *OIDCController) exchange(ctx *fiber.Ctx, userId string) (*oidcCallback, error) {
+	provider := ctx.Params("provider")
+	client, ok := c.Clients[provider]
+	if !ok {
+		return nil, ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Provedor de login não encontrado",
+		})
+	}
+
+	var req struct {
+		Code      string `json:"code"`
+		State     string `json:"state"`
+		UseCookie bool   `json:"useCookie"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Code == "" || req.State == "" {
+		return nil, ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Code e state são obrigatórios",
+		})
+	}
+
+	// O cookie vale para uma única tentativa, válida ou não
+	stateHash := auth.HashToken(req.State)
+	cookie := ctx.Cookies(auth.OIDCStateCookie)
+	expired := sessionCookie(c.Config, auth.OIDCStateCookie, "", oidcStateCookiePath, 0, true)
+	expired.Expires = time.Unix(0, 0)
+	expired.MaxAge = -1
+	ctx.Cookie(expired)
+	if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(stateHash)) != 1 {
+		return nil, ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Login social iniciado em outro navegador, tente novamente",
+		})
+	}
+
+	state, err := repository.ConsumeOIDCState(stateHash, provider)
+	if err == nil && state.UserId != userId {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return nil, ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Login social inválido ou expirado, tente novamente",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar state do login social: %v", err)
+		return nil, ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	claims, err := client.Exchange(ctx.UserContext(), req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		log.Printf("Erro ao validar login no provedor %s: %v", provider, err)
+		return nil, ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Não foi possível confirmar o login com o provedor",
+		})
+	}
+
+	return &oidcCallback{State: state, Claims: claims, UseCookie: req.UseCookie}, nil
+}
+
+// findOrCreateUser localiza o usuário pela conta vinculada ou pelo email verificado pelo provedor,
+// criando um usuário CLIENT quando não houver nenhum. O vínculo automático pelo email só acontece
+// com contas que já confirmaram o email.
+func (c *OIDCController) findOrCreateUser(ctx *fiber.Ctx, provider string, claims *oidc.IDTokenClaims) (*models.User, error) {
+	identity, err := repository.GetUserIdentity(provider, claims.Subject)
+	if err == nil {
+		return repository.GetUserById(identity.UserId)
+	}
+	if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	// Sem vínculo prévio, só um email confirmado pelo provedor pode identificar a conta
+	email := strings.TrimSpace(claims.Email)
+	if email == "" || !bool(claims.EmailVerified) {
+		return nil, fiber.NewError(fiber.StatusForbidden, "O provedor não confirmou seu email")
+	}
+
+	identity = &models.UserIdentity{
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    email,
+	}
+
+	user, err := repository.GetUserByEmail(email)
+	if err == nil {
+		// Contas suspensas ou excluídas não ganham vínculo novo; completeLogin recusa o login
+		if !user.IsActive() {
+			return user, nil
+		}
+		// Sem email confirmado, a conta pode ter sido criada por outra pessoa com o email da vítima
+		// e uma senha que ela conhece; o vínculo automático daria a essa pessoa acesso à conta
+		if user.EmailVerifiedAt == nil {
+			return nil, fiber.NewError(fiber.StatusConflict,
+				"Já existe uma conta com este email que ainda não foi confirmada. "+
+					"Entre com sua senha ou com um link de acesso e vincule o provedor pelo seu perfil.")
+		}
+		identity.UserId = user.ID
+		if err := repository.CreateUserIdentity(identity); err != nil {
+			return nil, err
+		}
+		recordAudit(ctx, user.ID, models.AuditIdentityLinked, user.ID, map[string]interface{}{
+			"provider":  provider,
+			"automatic": true,
+		})
+		return user, nil
+	}
+	if err != repository.ErrNotFound {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name = strings.SplitN(email, "@", 2)[0]
+	}
+	user = &models.User{
+		Email: email,
+		Name:  name,
+		Role:  models.CLIENT,
+	}
+	consents := signupConsents(c.Config.TermsVersion, ctx.IP(), false)
+	if err := repository.CreateUserWithIdentity(user, identity, consents); err != nil {
+		return nil, err
+	}
+	log.Printf("Usuário criado por login social (%s): %s", provider, user.ID)
+
+	return user, nil
+}
+
+// link vincula a conta do provedor ao usuário que iniciou o fluxo
+func (c *OIDCController) link(ctx *fiber.Ctx, userId string, provider string, claims *oidc.IDTokenClaims) error {
+	existing, err := repository.GetUserIdentity(provider, claims.Subject)
+	if err == nil {
+		if existing.UserId != userId {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Esta conta do provedor já está vinculada a outro usuário",
+			})
+		}
+		return ctx.JSON(fiber.Map{
+			"success": true,
+			"message": "Provedor já vinculado",
+		})
+	}
+	if err != repository.ErrNotFound {
+		log.Printf("Erro ao buscar conta vinculada: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	identity := &models.UserIdentity{
+		UserId:   userId,
+		Provider: provider,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := repository.CreateUserIdentity(identity); err != nil {
+		// A restrição única ("userId", provider) recusa uma segunda conta do mesmo provedor
+		log.Printf("Erro ao vincular provedor: %v", err)
+		return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Já existe uma conta deste provedor vinculada ao usuário",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditIdentityLinked, userId, map[string]interface{}{
+		"provider": provider,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Provedor vinculado com sucesso",
+		"data":    identity,
+	})
+}
+
+// ListIdentities retorna os provedores vinculados à conta do usuário autenticado
+func (c *OIDCController) ListIdentities(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	identities, err := repository.GetUserIdentitiesByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar contas vinculadas: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar contas vinculadas",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    identities,
+	})
+}
+
+// Unlink desvincula um provedor, desde que o usuário continue com outra forma de login
+func (c *OIDCController) Unlink(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	provider := ctx.Params("provider")
+
+	identities, err := repository.GetUserIdentitiesByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar contas vinculadas: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	passwordHash, err := repository.GetUserPasswordHash(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	linked := false
+	for _, identity := range identities {
+		linked = linked || identity.Provider == provider
+	}
+	if !linked {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Provedor não vinculado",
+		})
+	}
+	if passwordHash == "" && len(identities) == 1 {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Defina uma senha antes de desvincular sua única forma de login",
+		})
+	}
+
+	if err := repository.DeleteUserIdentity(userId, provider); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Provedor não vinculado",
+			})
+		}
+		log.Printf("Erro ao desvincular provedor: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao desvincular provedor",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditIdentityUnlinked, userId, map[string]interface{}{
+		"provider": provider,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Provedor desvinculado com sucesso",
+	})
+}
@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"log"
+	"strings"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxPapelNomeLength limita o tamanho do nome de um papel personalizado
+const maxPapelNomeLength = 60
+
+// PapelController gerencia os papéis personalizados de um espaço e sua atribuição aos membros
+type PapelController struct{}
+
+// NewPapelController cria uma nova instância de PapelController
+func NewPapelController() *PapelController {
+	return &PapelController{}
+}
+
+// papelRequest representa os dados para criar ou editar um papel personalizado
+type papelRequest struct {
+	Nome        string   `json:"nome"`
+	Descricao   string   `json:"descricao"`
+	Permissions []string `json:"permissions"`
+}
+
+// ListPermissions retorna as permissões que podem compor um papel personalizado
+func (c *PapelController) ListPermissions(ctx *fiber.Ctx) error {
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    policy.EspacoPermissions(),
+	})
+}
+
+// ListPapeis retorna os papéis personalizados do espaço
+func (c *PapelController) ListPapeis(ctx *fiber.Ctx) error {
+	espacoId := ctx.Locals("espacoId").(string)
+
+	papeis, err := repository.GetPapeisByEspacoId(espacoId)
+	if err != nil {
+		log.Printf("Erro ao buscar papéis: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar papéis do espaço",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    papeis,
+	})
+}
+
+// CreatePapel cria um papel personalizado no espaço
+func (c *PapelController) CreatePapel(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+
+	papel, errMsg := parsePapelRequest(ctx)
+	if errMsg != "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+	papel.EspacoId = espacoId
+
+	if err := repository.CreateEspacoPapel(papel); err != nil {
+		if err == repository.ErrDuplicate {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Já existe um papel com este nome no espaço",
+			})
+		}
+		log.Printf("Erro ao criar papel: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao criar papel",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditPapelCreated, "", map[string]interface{}{
+		"espacoId":    espacoId,
+		"papelId":     papel.ID,
+		"nome":        papel.Nome,
+		"permissions": papel.Permissions,
+	})
+
+	return ctx.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"success": true,
+		"message": "Papel criado com sucesso",
+		"data":    papel,
+	})
+}
+
+// UpdatePapel altera nome, descrição e permissões de um papel personalizado
+func (c *PapelController) UpdatePapel(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+
+	papel, errMsg := parsePapelRequest(ctx)
+	if errMsg != "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": errMsg,
+		})
+	}
+	papel.ID = ctx.Params("papelId")
+	papel.EspacoId = espacoId
+
+	if err := repository.UpdateEspacoPapel(papel); err != nil {
+		switch err {
+		case repository.ErrNotFound:
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Papel não encontrado",
+			})
+		case repository.ErrDuplicate:
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Já existe um papel com este nome no espaço",
+			})
+		}
+		log.Printf("Erro ao atualizar papel: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atualizar papel",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditPapelUpdated, "", map[string]interface{}{
+		"espacoId":    espacoId,
+		"papelId":     papel.ID,
+		"nome":        papel.Nome,
+		"permissions": papel.Permissions,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Papel atualizado com sucesso",
+		"data":    papel,
+	})
+}
+
+// DeletePapel remove um papel personalizado; os membros com ele voltam às permissões da role no espaço
+func (c *PapelController) DeletePapel(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+	papelId := ctx.Params("papelId")
+
+	if err := repository.DeleteEspacoPapel(espacoId, papelId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Papel não encontrado",
+			})
+		}
+		log.Printf("Erro ao remover papel: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao remover papel",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditPapelDeleted, "", map[string]interface{}{
+		"espacoId": espacoId,
+		"papelId":  papelId,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Papel removido com sucesso",
+	})
+}
+
+// AssignPapel atribui um papel personalizado a um membro do espaço; papelId vazio remove a atribuição
+func (c *PapelController) AssignPapel(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	espacoId := ctx.Locals("espacoId").(string)
+	membroId := ctx.Params("userId")
+
+	var req struct {
+		PapelId string `json:"papelId"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	if _, err := repository.GetEspacoMembro(espacoId, membroId); err != nil {
+		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Membro não encontrado",
+		})
+	}
+
+	if req.PapelId != "" {
+		if _, err := repository.GetEspacoPapel(espacoId, req.PapelId); err != nil {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Papel não encontrado",
+			})
+		}
+	}
+
+	if err := repository.SetEspacoMembroPapel(espacoId, membroId, req.PapelId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Membro ou papel não encontrado",
+			})
+		}
+		log.Printf("Erro ao atribuir papel: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao atribuir papel ao membro",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditPapelAssigned, membroId, map[string]interface{}{
+		"espacoId": espacoId,
+		"papelId":  req.PapelId,
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Papel do membro atualizado com sucesso",
+	})
+}
+
+// parsePapelRequest lê e valida o corpo de criação ou edição de um papel.
+// Retorna a mensagem de erro para o cliente quando os dados são inválidos.
+func parsePapelRequest(ctx *fiber.Ctx) (*models.EspacoPapel, string) {
+	var req papelRequest
+	if err := ctx.BodyParser(&req); err != nil {
+		return nil, "Erro ao processar dados: " + err.Error()
+	}
+
+	req.Nome = strings.TrimSpace(req.Nome)
+	if req.Nome == "" || len(req.Nome) > maxPapelNomeLength {
+		return nil, "O nome do papel é obrigatório e deve ter até 60 caracteres"
+	}
+
+	permissions := []string{}
+	seen := map[string]bool{}
+	for _, p := range req.Permissions {
+		if !policy.IsEspacoPermission(policy.Permission(p)) {
+			return nil, "Permissão inválida: " + p
+		}
+		if !seen[p] {
+			seen[p] = true
+			permissions = append(permissions, p)
+		}
+	}
+
+	return &models.EspacoPapel{
+		Nome:        req.Nome,
+		Descricao:   strings.TrimSpace(req.Descricao),
+		Permissions: permissions,
+	}, ""
+}
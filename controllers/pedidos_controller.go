@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"log"
+	"math"
+
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// defaultPedidosPageSize é o tamanho padrão da página de pedidos
+	defaultPedidosPageSize = 50
+	// maxPedidosPageSize limita o tamanho da página de pedidos
+	maxPedidosPageSize = 200
+)
+
+// GetPedidos retorna os pedidos da loja com os itens, paginados, para o painel e para integrações
+func GetPedidos(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("pageSize", defaultPedidosPageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > maxPedidosPageSize {
+		pageSize = defaultPedidosPageSize
+	}
+
+	pedidos, total, err := repository.ListPedidos(page, pageSize)
+	if err != nil {
+		log.Printf("Erro ao listar pedidos: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao listar pedidos",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    pedidos,
+		"pagination": fiber.Map{
+			"page":       page,
+			"pageSize":   pageSize,
+			"total":      total,
+			"totalPages": int(math.Ceil(float64(total) / float64(pageSize))),
+		},
+	})
+}
@@ -0,0 +1,456 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/mail"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// dataDeletionGracePeriod define quanto tempo o titular tem para desistir do pedido de exclusão
+	dataDeletionGracePeriod = 30 * 24 * time.Hour
+	// dataExportFormatVersion identifica o formato do arquivo de exportação para quem o processa
+	dataExportFormatVersion = 1
+)
+
+// PrivacyController atende os direitos do titular previstos na LGPD: acesso aos dados
+// pessoais (exportação) e exclusão, com período de carência antes da anonimização
+type PrivacyController struct {
+	// Auth envia os emails de confirmação dos pedidos de exclusão
+	Auth *AuthController
+}
+
+// NewPrivacyController cria uma nova instância de PrivacyController
+func NewPrivacyController(authController *AuthController) *PrivacyController {
+	return &PrivacyController{
+		Auth: authController,
+	}
+}
+
+// securityEvent é o evento de segurança da conta incluído na exportação, sem dados de terceiros
+type securityEvent struct {
+	Action    string    `json:"action"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// ExportData gera um arquivo zip com os dados pessoais do usuário autenticado em JSON
+func (c *PrivacyController) ExportData(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	user, err := repository.GetUserById(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	espacos, err := repository.GetEspacosByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar espaços do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	identities, err := repository.GetUserIdentitiesByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar contas vinculadas: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	sessions, err := repository.GetActiveSessionsByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar sessões do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	totp, err := repository.GetUserTOTP(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar 2FA do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	children, err := exportChildren(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar perfis infantis: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	pedidos, err := repository.GetPedidosByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar pedidos do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	consents, err := repository.GetUserConsents(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar consentimentos do usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+	entries, err := repository.GetAuditLogsByTargetUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar eventos de segurança: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+
+	// Os detalhes da auditoria podem citar administradores e outros usuários; só o evento é exportado
+	events := make([]securityEvent, 0, len(entries))
+	for _, entry := range entries {
+		events = append(events, securityEvent{Action: entry.Action, IP: entry.IP, CreatedAt: entry.CreatedAt})
+	}
+
+	generatedAt := time.Now().UTC()
+	files := []exportFile{
+		{"perfil.json", user},
+		{"espacos.json", espacos},
+		{"contas_vinculadas.json", identities},
+		{"sessoes.json", sessions},
+		{"criancas.json", children},
+		{"pedidos.json", pedidos},
+		{"consentimentos.json", fiber.Map{"current": currentConsents(consents), "history": consents}},
+		{"seguranca.json", fiber.Map{"mfaEnabled": totp.EnabledAt != nil, "events": events}},
+	}
+
+	archive, err := buildDataExport(userId, generatedAt, files)
+	if err != nil {
+		log.Printf("Erro ao gerar arquivo de exportação: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao exportar dados",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditDataExported, userId, nil)
+
+	ctx.Set(fiber.HeaderContentType, "application/zip")
+	ctx.Set(fiber.HeaderContentDisposition, `attachment; filename="meus-dados-`+generatedAt.Format("2006-01-02")+`.zip"`)
+	ctx.Set(fiber.HeaderCacheControl, "no-store")
+	return ctx.Send(archive)
+}
+
+// GetConsents retorna os consentimentos vigentes do usuário autenticado e o histórico de decisões
+func (c *PrivacyController) GetConsents(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	consents, err := repository.GetUserConsents(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar consentimentos: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar consentimentos",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"current": currentConsents(consents),
+			"history": consents,
+		},
+	})
+}
+
+// UpdateMarketingConsent registra a opção do usuário por receber ou não comunicações de marketing
+func (c *PrivacyController) UpdateMarketingConsent(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Granted *bool `json:"granted"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.Granted == nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Informe granted como true ou false",
+		})
+	}
+
+	consent := &models.UserConsent{
+		UserId:  userId,
+		Purpose: models.ConsentMarketing,
+		Granted: *req.Granted,
+		IP:      ctx.IP(),
+	}
+	if err := repository.AddUserConsent(consent); err != nil {
+		log.Printf("Erro ao registrar consentimento: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao registrar consentimento",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Preferência de comunicação atualizada",
+		"data":    consent,
+	})
+}
+
+// signupConsents são os consentimentos registrados no cadastro: criar a conta é o aceite da
+// versão vigente dos termos de uso e da política de privacidade; marketing depende da opção
+func signupConsents(version string, ip string, marketing bool) []models.UserConsent {
+	return []models.UserConsent{
+		{Purpose: models.ConsentTerms, Granted: true, Version: version, IP: ip},
+		{Purpose: models.ConsentPrivacy, Granted: true, Version: version, IP: ip},
+		{Purpose: models.ConsentMarketing, Granted: marketing, IP: ip},
+	}
+}
+
+// currentConsents retorna a decisão vigente, a mais recente, de cada finalidade
+func currentConsents(history []models.UserConsent) map[string]models.UserConsent {
+	current := map[string]models.UserConsent{}
+	for _, consent := range history {
+		current[consent.Purpose] = consent
+	}
+	return current
+}
+
+// childExport é um perfil infantil incluído na exportação, com a leitura registrada para ele
+type childExport struct {
+	*models.ChildProfile
+	Progress    []models.ReadingProgress `json:"progress"`
+	Favorites   []models.ChildFavorite   `json:"favorites"`
+	ReadingTime []models.ReadingTime     `json:"readingTime"`
+}
+
+// exportChildren reúne os perfis infantis do usuário com o progresso de leitura, os favoritos e o tempo de leitura de cada um
+func exportChildren(userId string) ([]childExport, error) {
+	profiles, err := repository.GetChildProfilesByUserId(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	children := make([]childExport, 0, len(profiles))
+	for i := range profiles {
+		progress, err := repository.GetReadingProgress(profiles[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		favorites, err := repository.GetChildFavorites(profiles[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		readingTime, err := repository.GetReadingTimeHistory(profiles[i].ID)
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, childExport{
+			ChildProfile: &profiles[i],
+			Progress:     progress,
+			Favorites:    favorites,
+			ReadingTime:  readingTime,
+		})
+	}
+	return children, nil
+}
+
+// exportFile é um arquivo JSON do zip de exportação de dados
+type exportFile struct {
+	name string
+	data interface{}
+}
+
+// buildDataExport monta o zip com os arquivos e um índice (exportacao.json) que descreve o conteúdo
+func buildDataExport(userId string, generatedAt time.Time, files []exportFile) ([]byte, error) {
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, file.name)
+	}
+	manifest := exportFile{"exportacao.json", fiber.Map{
+		"formatVersion": dataExportFormatVersion,
+		"userId":        userId,
+		"generatedAt":   generatedAt,
+		"files":         names,
+	}}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for _, file := range append([]exportFile{manifest}, files...) {
+		w, err := archive.Create(file.name)
+		if err != nil {
+			return nil, err
+		}
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GetDeletionRequest retorna o pedido de exclusão pendente do usuário, ou null se não houver
+func (c *PrivacyController) GetDeletionRequest(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	request, err := repository.GetPendingDataDeletionRequest(userId)
+	if err != nil && err != repository.ErrNotFound {
+		log.Printf("Erro ao buscar pedido de exclusão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar pedido de exclusão",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    request,
+	})
+}
+
+// RequestDeletion agenda a exclusão dos dados pessoais do usuário para o fim do período de carência.
+// Contas com senha precisam reconfirmá-la; contas só com login social confirmam pela sessão.
+func (c *PrivacyController) RequestDeletion(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	var req struct {
+		Password string `json:"password"`
+		Reason   string `json:"reason"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	hashedPassword, err := repository.GetUserPasswordHash(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if hashedPassword != "" && bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.Password)) != nil {
+		return ctx.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Senha incorreta",
+		})
+	}
+
+	request := &models.DataDeletionRequest{
+		UserId:       userId,
+		Reason:       strings.TrimSpace(req.Reason),
+		ScheduledFor: time.Now().Add(dataDeletionGracePeriod),
+	}
+	if err := repository.CreateDataDeletionRequest(request); err != nil {
+		if err == repository.ErrDuplicate {
+			return ctx.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "Já existe um pedido de exclusão pendente",
+			})
+		}
+		log.Printf("Erro ao registrar pedido de exclusão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao registrar pedido de exclusão",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditDataDeletionRequest, userId, map[string]interface{}{
+		"requestId":    request.ID,
+		"scheduledFor": request.ScheduledFor,
+	})
+
+	if user, err := repository.GetUserById(userId); err == nil {
+		c.Auth.sendMail(mail.Message{
+			To:      user.Email,
+			Subject: "Pedido de exclusão de dados - Mais Criança",
+			Body: "Olá, " + user.Name + "!\n\n" +
+				"Recebemos seu pedido de exclusão dos dados pessoais da sua conta. " +
+				"Os dados serão excluídos em " + request.ScheduledFor.Format("02/01/2006") + ".\n\n" +
+				"Até lá, você pode desistir pela sua conta, em Privacidade. " +
+				"Se você não fez este pedido, entre na sua conta, cancele o pedido e troque sua senha.",
+		})
+	}
+
+	return ctx.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"success": true,
+		"message": "Pedido de exclusão registrado. Você pode cancelá-lo até a data agendada.",
+		"data":    request,
+	})
+}
+
+// CancelDeletion cancela o pedido de exclusão pendente do usuário
+func (c *PrivacyController) CancelDeletion(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	if err := repository.CancelDataDeletionRequest(userId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Nenhum pedido de exclusão pendente",
+			})
+		}
+		log.Printf("Erro ao cancelar pedido de exclusão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao cancelar pedido de exclusão",
+		})
+	}
+
+	recordAudit(ctx, userId, models.AuditDataDeletionCanceled, userId, nil)
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Pedido de exclusão cancelado",
+	})
+}
+
+// ProcessDueDeletions anonimiza as contas cujo período de carência terminou e avisa o titular
+// no email que deixa de constar no cadastro. É executado periodicamente pelo servidor.
+func (c *PrivacyController) ProcessDueDeletions() {
+	requests, err := repository.GetDueDataDeletionRequests()
+	if err != nil {
+		log.Printf("Erro ao buscar pedidos de exclusão vencidos: %v", err)
+		return
+	}
+
+	for i := range requests {
+		request := &requests[i]
+
+		user, err := repository.GetUserById(request.UserId)
+		if err != nil {
+			log.Printf("Erro ao buscar usuário do pedido de exclusão %s: %v", request.ID, err)
+			continue
+		}
+
+		entry := &models.AuditLog{
+			Action:       models.AuditDataErased,
+			TargetUserId: request.UserId,
+			Details: map[string]interface{}{
+				"requestId":   request.ID,
+				"requestedAt": request.RequestedAt,
+			},
+		}
+		if err := repository.AnonymizeUser(request, entry); err != nil {
+			log.Printf("Erro ao anonimizar usuário do pedido de exclusão %s: %v", request.ID, err)
+			continue
+		}
+
+		// As imagens do avatar também são dados pessoais
+		thumbnails := make([]string, 0, len(user.AvatarThumbnails))
+		for _, url := range user.AvatarThumbnails {
+			thumbnails = append(thumbnails, url)
+		}
+		deleteBlobs(thumbnails)
+
+		c.Auth.sendMail(mail.Message{
+			To:      user.Email,
+			Subject: "Seus dados foram excluídos - Mais Criança",
+			Body: "Olá, " + user.Name + "!\n\n" +
+				"Conforme seu pedido, os dados pessoais da sua conta foram excluídos e a conta foi encerrada. " +
+				"Guardamos apenas os registros que a lei nos obriga a manter, sem identificação pessoal.",
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package controllers
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// maxRecommendations limita quantos livros são sugeridos de uma vez
+	maxRecommendations = 20
+	// maxReadingSecondsPerUpdate limita o tempo contado entre duas atividades de leitura do mesmo
+	// livro: uma pausa maior, ou um app que deixa de enviar o progresso, não conta como leitura
+	maxReadingSecondsPerUpdate = 600
+)
+
+// ReadingController registra a leitura do perfil infantil selecionado: progresso, favoritos e recomendações
+type ReadingController struct{}
+
+// NewReadingController cria uma nova instância de ReadingController
+func NewReadingController() *ReadingController {
+	return &ReadingController{}
+}
+
+// ListProgress retorna o progresso de leitura do perfil infantil
+func (c *ReadingController) ListProgress(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+
+	progress, err := repository.GetReadingProgress(profile.ID)
+	if err != nil {
+		log.Printf("Erro ao buscar progresso de leitura: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar progresso de leitura",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    progress,
+	})
+}
+
+// SaveProgress registra a página atual de um livro publicado do espaço; concluido marca o fim da leitura.
+// O tempo desde a última atividade no livro é medido no servidor e conta para o limite diário do perfil.
+func (c *ReadingController) SaveProgress(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+
+	var req struct {
+		Pagina    int  `json:"pagina"`
+		Concluido bool `json:"concluido"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Erro ao processar dados: " + err.Error(),
+		})
+	}
+
+	livro, errResponse := findReadableLivro(ctx)
+	if livro == nil {
+		return errResponse
+	}
+
+	if req.Pagina < 0 || (len(livro.Paginas) > 0 && req.Pagina >= len(livro.Paginas)) {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Página inválida",
+		})
+	}
+
+	progress := &models.ReadingProgress{
+		ChildProfileId: profile.ID,
+		LivroId:        livro.ID,
+		LivroTitulo:    livro.Titulo,
+		LivroCapa:      livro.Capa,
+		Pagina:         req.Pagina,
+		Concluido:      req.Concluido,
+	}
+	if err := repository.SaveReadingProgress(progress); err != nil {
+		log.Printf("Erro ao salvar progresso de leitura: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao salvar progresso de leitura",
+		})
+	}
+
+	seconds, err := repository.RecordReadingActivity(profile.ID, livro.ID, maxReadingSecondsPerUpdate)
+	if err != nil {
+		log.Printf("Erro ao registrar tempo de leitura: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao salvar progresso de leitura",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success":    true,
+		"data":       progress,
+		"screenTime": screenTimeStatus(profile, seconds),
+	})
+}
+
+// GetScreenTime retorna o tempo de leitura de hoje e o limite diário do perfil infantil
+func (c *ReadingController) GetScreenTime(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+
+	seconds, err := repository.GetReadingTimeToday(profile.ID)
+	if err != nil {
+		log.Printf("Erro ao buscar tempo de leitura: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar tempo de leitura",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    screenTimeStatus(profile, seconds),
+	})
+}
+
+// ListFavorites retorna os livros favoritos do perfil infantil
+func (c *ReadingController) ListFavorites(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+
+	favorites, err := repository.GetChildFavorites(profile.ID)
+	if err != nil {
+		log.Printf("Erro ao buscar favoritos: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar favoritos",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    favorites,
+	})
+}
+
+// AddFavorite marca um livro publicado do espaço como favorito do perfil infantil
+func (c *ReadingController) AddFavorite(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+
+	livro, errResponse := findReadableLivro(ctx)
+	if livro == nil {
+		return errResponse
+	}
+
+	if err := repository.AddChildFavorite(profile.ID, livro.ID); err != nil {
+		log.Printf("Erro ao adicionar favorito: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao adicionar favorito",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Livro adicionado aos favoritos",
+	})
+}
+
+// RemoveFavorite desmarca um livro como favorito do perfil infantil
+func (c *ReadingController) RemoveFavorite(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+
+	if err := repository.RemoveChildFavorite(profile.ID, ctx.Params("livroId")); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Livro não está nos favoritos",
+			})
+		}
+		log.Printf("Erro ao remover favorito: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao remover favorito",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Livro removido dos favoritos",
+	})
+}
+
+// GetRecommendations sugere livros do espaço a partir do que o perfil infantil já leu e favoritou
+func (c *ReadingController) GetRecommendations(ctx *fiber.Ctx) error {
+	profile := ctx.Locals("childProfile").(*models.ChildProfile)
+	espacoId := ctx.Locals("espacoId").(string)
+
+	livros, err := repository.GetRecommendedLivros(profile, espacoId, maxRecommendations)
+	if err != nil {
+		log.Printf("Erro ao buscar recomendações: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar recomendações",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    livros,
+	})
+}
+
+// screenTimeStatus descreve o tempo lido hoje e quanto resta do limite diário; sem limite, remainingSeconds é nulo
+func screenTimeStatus(profile *models.ChildProfile, seconds int) fiber.Map {
+	status := fiber.Map{
+		"usedSeconds":       seconds,
+		"dailyLimitMinutes": profile.Controls.DailyLimitMinutes,
+		"remainingSeconds":  nil,
+	}
+	if limit := profile.Controls.DailyLimitMinutes; limit != nil {
+		remaining := *limit*60 - seconds
+		if remaining < 0 {
+			remaining = 0
+		}
+		status["remainingSeconds"] = remaining
+	}
+	return status
+}
+
+// findReadableLivro busca o livro de :livroId entre os publicados do espaço da requisição
+// que os controles parentais do perfil infantil permitem.
+// Quando não há livro a retornar, a resposta de erro já foi escrita e o segundo valor deve ser retornado.
+func findReadableLivro(ctx *fiber.Ctx) (*models.Livro, error) {
+	espacoId := ctx.Locals("espacoId").(string)
+
+	livro, err := repository.GetLivroById(ctx.Params("livroId"), espacoId)
+	if err == nil && !childAllows(ctx, livro) {
+		err = repository.ErrNotFound
+	}
+	if err == repository.ErrNotFound {
+		return nil, ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Livro não encontrado",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar livro: %v", err)
+		return nil, ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	return livro, nil
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionController gerencia as sessões de login do usuário autenticado
+type SessionController struct{}
+
+// NewSessionController cria uma nova instância de SessionController
+func NewSessionController() *SessionController {
+	return &SessionController{}
+}
+
+// ListSessions retorna as sessões ativas do usuário, indicando a sessão atual
+func (c *SessionController) ListSessions(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	currentId, _ := ctx.Locals("sessionId").(string)
+
+	sessions, err := repository.GetActiveSessionsByUserId(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar sessões: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao buscar sessões",
+		})
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentId
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"data":    sessions,
+	})
+}
+
+// RevokeSession encerra uma sessão do usuário; encerrar a sessão atual equivale ao logout
+func (c *SessionController) RevokeSession(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	if err := repository.RevokeSession(ctx.Params("id"), userId); err != nil {
+		if err == repository.ErrNotFound {
+			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Sessão não encontrada",
+			})
+		}
+		log.Printf("Erro ao encerrar sessão: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao encerrar sessão",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Sessão encerrada com sucesso",
+	})
+}
+
+// RevokeOtherSessions encerra todas as sessões do usuário, exceto a atual
+func (c *SessionController) RevokeOtherSessions(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	currentId, _ := ctx.Locals("sessionId").(string)
+
+	count, err := repository.RevokeUserSessions(userId, currentId)
+	if err != nil {
+		log.Printf("Erro ao encerrar sessões: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao encerrar sessões",
+		})
+	}
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Outras sessões encerradas com sucesso",
+		"revoked": count,
+	})
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// refreshCookiePath restringe o cookie do token de atualização às rotas de autenticação
+const refreshCookiePath = "/api/auth"
+
+// setSessionCookies grava os tokens em cookies HttpOnly e emite um novo token CSRF.
+// Os tokens são removidos da resposta para que não fiquem acessíveis ao JavaScript.
+func setSessionCookies(ctx *fiber.Ctx, config *configs.Config, resp *models.UserResponse) error {
+	csrfToken, err := setCSRFCookie(ctx, config)
+	if err != nil {
+		return err
+	}
+
+	setAccessTokenCookie(ctx, config, resp.Token)
+	ctx.Cookie(sessionCookie(config, auth.RefreshTokenCookie, resp.RefreshToken, refreshCookiePath, config.RefreshTokenTTL, true))
+
+	resp.Token = ""
+	resp.RefreshToken = ""
+	resp.CSRFToken = csrfToken
+	return nil
+}
+
+// setAccessTokenCookie substitui apenas o token de acesso, como na troca de espaço ativo
+func setAccessTokenCookie(ctx *fiber.Ctx, config *configs.Config, token string) {
+	ctx.Cookie(sessionCookie(config, auth.AccessTokenCookie, token, "/", config.AccessTokenTTL, true))
+}
+
+// setCSRFCookie emite um novo token CSRF, legível pelo JavaScript, e o retorna
+func setCSRFCookie(ctx *fiber.Ctx, config *configs.Config) (string, error) {
+	csrfToken, _, err := auth.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+	// O token CSRF dura tanto quanto a sessão, que pode ser renovada até o fim do token de atualização
+	ctx.Cookie(sessionCookie(config, auth.CSRFCookie, csrfToken, "/", config.RefreshTokenTTL, false))
+	return csrfToken, nil
+}
+
+// clearSessionCookies expira todos os cookies de sessão
+func clearSessionCookies(ctx *fiber.Ctx, config *configs.Config) {
+	for _, cookie := range []*fiber.Cookie{
+		sessionCookie(config, auth.AccessTokenCookie, "", "/", 0, true),
+		sessionCookie(config, auth.RefreshTokenCookie, "", refreshCookiePath, 0, true),
+		sessionCookie(config, auth.CSRFCookie, "", "/", 0, false),
+	} {
+		cookie.Expires = time.Unix(0, 0)
+		cookie.MaxAge = -1
+		ctx.Cookie(cookie)
+	}
+}
+
+// usesCookieSession informa se a requisição foi autenticada pelo cookie de sessão
+func usesCookieSession(ctx *fiber.Ctx) bool {
+	source, _ := ctx.Locals("authSource").(string)
+	return source == "cookie"
+}
+
+// sessionCookie monta um cookie de sessão com os atributos configurados
+func sessionCookie(config *configs.Config, name string, value string, path string, ttl time.Duration, httpOnly bool) *fiber.Cookie {
+	return &fiber.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   config.CookieDomain,
+		MaxAge:   int(ttl.Seconds()),
+		Secure:   config.CookieSecure,
+		HTTPOnly: httpOnly,
+		SameSite: config.CookieSameSite,
+	}
+}
@@ -1,45 +1,49 @@
 package controllers
 
 import (
+	"bytes"
 	"database/sql"
+	"io"
 	"log"
+	"strconv"
+	"strings"
 
+	"github.com/WBianchi/maiscrianca/avatar"
+	"github.com/WBianchi/maiscrianca/mail"
 	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
 	"github.com/gofiber/fiber/v2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// maxUserNameLength limita o nome informado no perfil
+	maxUserNameLength = 100
+	// maxPasswordFailures define quantas senhas atuais erradas a troca de senha tolera antes do bloqueio
+	maxPasswordFailures = 5
 )
 
 // UserController gerencia as operações relacionadas ao usuário
 type UserController struct {
-	DB *sql.DB
+	DB   *sql.DB
+	Auth *AuthController
 }
 
 // NewUserController cria uma nova instância de UserController
-func NewUserController(db *sql.DB) *UserController {
+func NewUserController(db *sql.DB, authController *AuthController) *UserController {
 	return &UserController{
-		DB: db,
+		DB:   db,
+		Auth: authController,
 	}
 }
 
 // GetUserProfile recupera o perfil do usuário autenticado
 func (c *UserController) GetUserProfile(ctx *fiber.Ctx) error {
 	userId := ctx.Locals("userId").(string)
-	
-	var user models.User
-	query := `SELECT id, email, name, role, profile_avatar, created_at, updated_at 
-			  FROM "User" WHERE id = $1`
-	
-	err := c.DB.QueryRow(query, userId).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Name,
-		&user.Role,
-		&user.ProfileAvatar,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
 
+	user, err := repository.GetUserById(userId)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if err == repository.ErrNotFound {
 			return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
 				"error": "Usuário não encontrado",
 			})
@@ -55,71 +59,243 @@ func (c *UserController) GetUserProfile(ctx *fiber.Ctx) error {
 	})
 }
 
-// UpdateUserProfile atualiza o perfil do usuário
+// UpdateUserProfile atualiza o perfil do usuário. O avatar não é aceito aqui: ele só pode
+// ser trocado pelo envio da imagem em UploadAvatar.
 func (c *UserController) UpdateUserProfile(ctx *fiber.Ctx) error {
 	userId := ctx.Locals("userId").(string)
-	
-	// Verifica se o usuário existe
-	var exists bool
-	err := c.DB.QueryRow(`SELECT EXISTS(SELECT 1 FROM "User" WHERE id = $1)`, userId).Scan(&exists)
-	
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := ctx.BodyParser(&req); err != nil {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Dados inválidos",
+		})
+	}
+
+	// Atualiza apenas os campos fornecidos
+	if name := strings.TrimSpace(req.Name); name != "" {
+		if len(name) > maxUserNameLength {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "O nome deve ter até 100 caracteres",
+			})
+		}
+
+		if err := repository.UpdateUserName(userId, name); err != nil {
+			if err == repository.ErrNotFound {
+				return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
+					"error": "Usuário não encontrado",
+				})
+			}
+			log.Printf("Erro ao atualizar usuário: %v", err)
+			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro ao atualizar perfil",
+			})
+		}
+	}
+
+	return ctx.JSON(fiber.Map{
+		"message": "Perfil atualizado com sucesso",
+	})
+}
+
+// ChangePassword troca a senha do usuário autenticado, validada pela política de senhas, e encerra
+// as demais sessões. A senha atual é exigida e as tentativas erradas são limitadas; contas só com
+// login social, que ainda não têm senha, definem a primeira senha pela sessão.
+func (c *UserController) ChangePassword(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+	sessionId, _ := ctx.Locals("sessionId").(string)
+
+	var req struct {
+		CurrentPassword string `json:"currentPassword"`
+		NewPassword     string `json:"newPassword"`
+	}
+	if err := ctx.BodyParser(&req); err != nil || req.NewPassword == "" {
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A nova senha é obrigatória",
+		})
+	}
+
+	hashedPassword, err := repository.GetUserPasswordHash(userId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if hashedPassword != "" {
+		ok, err := verifyThrottled(ctx, passwordThrottleKey(userId), maxPasswordFailures, func() (bool, error) {
+			return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.CurrentPassword)) == nil, nil
+		}, fiber.Map{"error": "Senha atual incorreta"})
+		if !ok {
+			return err
+		}
+		if bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(req.NewPassword)) == nil {
+			return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "A nova senha deve ser diferente da atual",
+			})
+		}
+	}
+
+	user, err := repository.GetUserById(userId)
 	if err != nil {
-		log.Printf("Erro ao verificar usuário: %v", err)
+		log.Printf("Erro ao buscar usuário: %v", err)
 		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Erro interno do servidor",
 		})
 	}
-	
-	if !exists {
-		return ctx.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Usuário não encontrado",
+	if ok, err := c.Auth.checkPassword(ctx, req.NewPassword, user.Email, user.Name); !ok {
+		return err
+	}
+
+	newHash, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		log.Printf("Erro ao gerar hash da senha: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao processar senha",
 		})
 	}
-	
-	// Extrair dados da requisição
-	type UpdateRequest struct {
-		Name          string `json:"name"`
-		ProfileAvatar string `json:"profileAvatar"`
+
+	revoked, err := repository.ChangeUserPassword(userId, string(newHash), sessionId, &models.AuditLog{
+		ActorId:      userId,
+		Action:       models.AuditPasswordChanged,
+		TargetUserId: userId,
+		IP:           ctx.IP(),
+	})
+	if err != nil {
+		log.Printf("Erro ao trocar senha: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao trocar senha",
+		})
 	}
-	
-	var req UpdateRequest
-	if err := ctx.BodyParser(&req); err != nil {
+
+	c.Auth.sendMail(mail.Message{
+		To:      user.Email,
+		Subject: "Sua senha foi alterada - Mais Criança",
+		Body: "Olá, " + user.Name + "!\n\n" +
+			"A senha da sua conta foi alterada e as outras sessões abertas foram encerradas. " +
+			"Se você não fez esta alteração, use a opção \"Esqueci minha senha\" na tela de login para criar uma nova.",
+	})
+
+	return ctx.JSON(fiber.Map{
+		"success":         true,
+		"message":         "Senha alterada com sucesso",
+		"revokedSessions": revoked,
+	})
+}
+
+// UploadAvatar recebe a imagem do avatar (campo multipart "file"), confere o tipo pelo conteúdo,
+// descarta os metadados e grava as miniaturas quadradas no armazenamento. O avatar do perfil só
+// é trocado depois que todas as miniaturas foram gravadas, e as anteriores são removidas.
+func (c *UserController) UploadAvatar(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	file, err := ctx.FormFile("file")
+	if err != nil {
 		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Dados inválidos",
+			"error": "Arquivo não fornecido",
 		})
 	}
-	
-	// Atualiza apenas os campos fornecidos
-	if req.Name != "" || req.ProfileAvatar != "" {
-		query := `UPDATE "User" SET updated_at = NOW()`
-		args := []interface{}{}
-		paramCount := 1
-		
-		if req.Name != "" {
-			query += `, name = $` + string(rune('0'+paramCount))
-			args = append(args, req.Name)
-			paramCount++
-		}
-		
-		if req.ProfileAvatar != "" {
-			query += `, profile_avatar = $` + string(rune('0'+paramCount))
-			args = append(args, req.ProfileAvatar)
-			paramCount++
-		}
-		
-		query += ` WHERE id = $` + string(rune('0'+paramCount))
-		args = append(args, userId)
-		
-		_, err = c.DB.Exec(query, args...)
+	if file.Size > avatar.MaxUploadSize {
+		return ctx.Status(fiber.StatusRequestEntityTooLarge).JSON(fiber.Map{
+			"error": "A imagem deve ter até 4 MB",
+		})
+	}
+
+	content, err := file.Open()
+	if err != nil {
+		log.Printf("Erro ao abrir avatar enviado: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Não foi possível ler o arquivo",
+		})
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(io.LimitReader(content, avatar.MaxUploadSize))
+	if err != nil {
+		log.Printf("Erro ao ler avatar enviado: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Não foi possível ler o arquivo",
+		})
+	}
+
+	thumbnails, err := avatar.Process(data)
+	switch err {
+	case nil:
+	case avatar.ErrUnsupportedType:
+		return ctx.Status(fiber.StatusUnsupportedMediaType).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case avatar.ErrInvalidImage, avatar.ErrTooLarge, avatar.ErrTooSmall:
+		return ctx.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	default:
+		log.Printf("Erro ao processar avatar: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao processar a imagem",
+		})
+	}
+
+	blob := blobStorage()
+	if blob == nil {
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Token do Vercel Blob não configurado",
+		})
+	}
+
+	urls := map[string]string{}
+	uploaded := make([]string, 0, len(thumbnails))
+	for _, thumbnail := range thumbnails {
+		size := strconv.Itoa(thumbnail.Size)
+		result, err := blob.Upload(ctx.Context(), "avatars/"+userId+"/"+size+".jpg", bytes.NewReader(thumbnail.Data), avatar.ContentType)
 		if err != nil {
-			log.Printf("Erro ao atualizar usuário: %v", err)
+			log.Printf("Erro ao enviar miniatura do avatar: %v", err)
+			deleteBlobs(uploaded)
 			return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Erro ao atualizar perfil",
+				"error": "Erro ao salvar o avatar",
 			})
 		}
+		urls[size] = result.URL
+		uploaded = append(uploaded, result.URL)
+	}
+
+	profileAvatar := urls[strconv.Itoa(avatar.DefaultSize)]
+	previous, err := repository.SetUserAvatar(userId, profileAvatar, urls)
+	if err != nil {
+		log.Printf("Erro ao atualizar avatar: %v", err)
+		deleteBlobs(uploaded)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao salvar o avatar",
+		})
 	}
-	
+	deleteBlobs(previous)
+
 	return ctx.JSON(fiber.Map{
-		"message": "Perfil atualizado com sucesso",
+		"success": true,
+		"message": "Avatar atualizado com sucesso",
+		"data": fiber.Map{
+			"profileAvatar":    profileAvatar,
+			"avatarThumbnails": urls,
+		},
+	})
+}
+
+// DeleteAvatar remove o avatar do perfil e as miniaturas armazenadas
+func (c *UserController) DeleteAvatar(ctx *fiber.Ctx) error {
+	userId := ctx.Locals("userId").(string)
+
+	previous, err := repository.SetUserAvatar(userId, "", nil)
+	if err != nil {
+		log.Printf("Erro ao remover avatar: %v", err)
+		return ctx.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro ao remover o avatar",
+		})
+	}
+	deleteBlobs(previous)
+
+	return ctx.JSON(fiber.Map{
+		"success": true,
+		"message": "Avatar removido com sucesso",
 	})
 }
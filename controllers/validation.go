@@ -0,0 +1,11 @@
+package controllers
+
+import (
+	"net/mail"
+)
+
+// isValidEmail informa se o valor é um endereço de email simples e bem formado
+func isValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email
+}
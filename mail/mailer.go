@@ -0,0 +1,35 @@
+package mail
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+)
+
+// Message representa um email a ser enviado
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer envia emails transacionais da aplicação
+type Mailer interface {
+	Send(msg Message) error
+}
+
+// NewMailer cria o Mailer definido por MAIL_DRIVER: "smtp" ou, por padrão, "outbox"
+func NewMailer(config *configs.Config) Mailer {
+	if config.MailDriver == "smtp" {
+		return &SMTPMailer{
+			Host:     config.SMTPHost,
+			Port:     config.SMTPPort,
+			Username: config.SMTPUsername,
+			Password: config.SMTPPassword,
+			From:     config.MailFrom,
+		}
+	}
+
+	return &OutboxMailer{
+		Dir:  config.MailOutboxDir,
+		From: config.MailFrom,
+	}
+}
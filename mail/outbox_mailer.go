@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutboxMailer grava os emails em arquivos .eml em vez de enviá-los, para desenvolvimento e testes.
+// Sem diretório configurado, as mensagens são apenas escritas no log.
+type OutboxMailer struct {
+	Dir  string
+	From string
+
+	mu  sync.Mutex
+	seq int
+}
+
+// Send grava a mensagem no diretório de saída ou no log
+func (m *OutboxMailer) Send(msg Message) error {
+	if m.Dir == "" {
+		log.Printf("Email (outbox) para %s: %s\n%s", msg.To, msg.Subject, msg.Body)
+		return nil
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.seq++
+	seq := m.seq
+	m.mu.Unlock()
+
+	recipient := strings.NewReplacer("@", "_at_", "/", "_").Replace(msg.To)
+	name := fmt.Sprintf("%s-%03d-%s.eml", time.Now().Format("20060102-150405"), seq, recipient)
+
+	return os.WriteFile(filepath.Join(m.Dir, name), buildMessage(m.From, msg), 0o644)
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPMailer envia emails por um servidor SMTP
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// Send envia a mensagem pelo servidor SMTP configurado
+func (m *SMTPMailer) Send(msg Message) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	addr := m.Host + ":" + m.Port
+	if err := smtp.SendMail(addr, auth, m.From, []string{msg.To}, buildMessage(m.From, msg)); err != nil {
+		return fmt.Errorf("erro ao enviar email para %s: %w", msg.To, err)
+	}
+	return nil
+}
+
+// headerSanitizer remove quebras de linha para impedir a injeção de cabeçalhos
+var headerSanitizer = strings.NewReplacer("\r", "", "\n", "")
+
+// buildMessage monta a mensagem no formato RFC 5322 em texto simples UTF-8
+func buildMessage(from string, msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + headerSanitizer.Replace(from) + "\r\n")
+	b.WriteString("To: " + headerSanitizer.Replace(msg.To) + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", headerSanitizer.Replace(msg.Subject)) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -4,9 +4,16 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"time"
 
+	"github.com/WBianchi/maiscrianca/auth"
 	"github.com/WBianchi/maiscrianca/configs"
 	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/mail"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/migrations"
+	"github.com/WBianchi/maiscrianca/password"
+	"github.com/WBianchi/maiscrianca/repository"
 	"github.com/WBianchi/maiscrianca/routes"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -24,6 +31,14 @@ func main() {
 
 	// Carregar configurações
 	config := configs.LoadConfig()
+	if err := config.Validate(); err != nil {
+		log.Fatal("Configuração inválida: ", err)
+	}
+
+	// Carregar chaves de assinatura dos tokens
+	if err := auth.LoadKeys(config); err != nil {
+		log.Fatal("Erro ao carregar chaves JWT: ", err)
+	}
 
 	// Configurar conexão com o PostgreSQL
 	db, err := sql.Open("postgres", config.DatabaseURL)
@@ -39,9 +54,49 @@ func main() {
 	}
 	fmt.Println("Conexão com o PostgreSQL estabelecida com sucesso!")
 
+	// Aplicar migrações pendentes
+	if err := migrations.Run(db); err != nil {
+		log.Fatal("Erro ao aplicar migrações:", err)
+	}
+
+	// Disponibilizar a conexão para o repositório
+	repository.SetDB(db)
+
+	// Limpar periodicamente tokens expirados
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := repository.DeleteExpiredTokens(); err != nil {
+				log.Printf("Erro ao remover tokens expirados: %v", err)
+			}
+		}
+	}()
+
 	// Inicializar controladores
-	authController := controllers.NewAuthController(db, config)
-	userController := controllers.NewUserController(db)
+	mailer := mail.NewMailer(config)
+	passwords, err := password.NewPolicy(config)
+	if err != nil {
+		log.Fatal("Erro ao carregar a lista de senhas vazadas: ", err)
+	}
+	authController := controllers.NewAuthController(db, config, mailer, passwords)
+	authStatusController := controllers.NewAuthStatusController(config)
+	userController := controllers.NewUserController(db, authController)
+	espacoController := controllers.NewEspacoController(config)
+	papelController := controllers.NewPapelController()
+	adminController := controllers.NewAdminController(config, authController)
+	apiKeyController := controllers.NewAPIKeyController()
+	mfaController := controllers.NewMFAController(config)
+	sessionController := controllers.NewSessionController()
+	oidcController := controllers.NewOIDCController(config, authController)
+	privacyController := controllers.NewPrivacyController(authController)
+	childController := controllers.NewChildController(config)
+	readingController := controllers.NewReadingController()
+
+	// Anonimizar periodicamente as contas cujo pedido de exclusão passou do período de carência
+	go func() {
+		for range time.Tick(time.Hour) {
+			privacyController.ProcessDueDeletions()
+		}
+	}()
 
 	// Inicializar o aplicativo Fiber
 	app := fiber.New(fiber.Config{
@@ -70,11 +125,14 @@ func main() {
 	
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: allowOrigins,
-		AllowHeaders: "Origin, Content-Type, Accept, Authorization",
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-Espaco-Id, X-CSRF-Token, X-API-Key",
 		AllowMethods: "GET, POST, PUT, DELETE",
 		AllowCredentials: true,
 	}))
 
+	// Proteção CSRF para requisições autenticadas por cookie
+	app.Use(middleware.CSRFMiddleware())
+
 	// Rota de healthcheck
 	app.Get("/api/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -84,8 +142,19 @@ func main() {
 	})
 
 	// Configurar rotas
-	routes.SetupAuthRoutes(app, authController)
+	routes.SetupJWKSRoutes(app)
+	routes.SetupAuthRoutes(app, authController, authStatusController, config)
+	routes.SetupOIDCRoutes(app, oidcController, config)
 	routes.SetupUserRoutes(app, userController, config)
+	routes.SetupMFARoutes(app, mfaController, config)
+	routes.SetupSessionRoutes(app, sessionController, config)
+	routes.SetupPrivacyRoutes(app, privacyController, config)
+	routes.SetupChildRoutes(app, childController, readingController, config)
+	routes.SetupLivrosRoutes(app, config)
+	routes.SetupEspacoRoutes(app, espacoController, papelController, config)
+	routes.SetupAdminRoutes(app, adminController, apiKeyController, config)
+	routes.SetupDashboardRoutes(app, config)
+	routes.SetupPedidosRoutes(app, config)
 
 	// Iniciar o servidor
 	port := config.Port
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AllowAPIKey libera a autenticação por chave de API nas rotas seguintes e deve vir antes de
+// AuthMiddleware. Use apenas em grupos em que toda rota passa por RequirePermission, que é onde
+// os escopos da chave são aplicados.
+func AllowAPIKey() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals("apiKeyAllowed", true)
+		return c.Next()
+	}
+}
+
+// apiKeyAuth autentica a requisição pela chave de API e preenche os mesmos Locals do token
+// (userId, userRole, tokenEspacoId), para que os handlers funcionem sem alteração.
+// O espaço da chave e os escopos ficam em apiKeyEspacoId e apiKeyScopes.
+func apiKeyAuth(c *fiber.Ctx, rawKey string) error {
+	key, err := repository.GetActiveAPIKeyByHash(auth.HashToken(rawKey))
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Chave de API inválida, revogada ou expirada",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar chave de API: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	user, err := repository.GetUserById(key.UserId)
+	if err != nil {
+		log.Printf("Erro ao buscar usuário da chave de API: %v", err)
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Chave de API inválida, revogada ou expirada",
+		})
+	}
+	// A chave age em nome do usuário e deixa de valer enquanto a conta estiver suspensa
+	if !user.IsActive() {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Chave de API inválida, revogada ou expirada",
+		})
+	}
+
+	if err := repository.TouchAPIKey(key); err != nil {
+		log.Printf("Erro ao registrar uso da chave de API: %v", err)
+	}
+
+	scopes := policy.Set{}
+	for _, scope := range key.Scopes {
+		scopes.Add(policy.Permission(scope))
+	}
+
+	c.Locals("userId", user.ID)
+	c.Locals("userRole", user.Role)
+	c.Locals("tokenEspacoId", key.EspacoId)
+	c.Locals("apiKeyId", key.ID)
+	c.Locals("apiKeyEspacoId", key.EspacoId)
+	c.Locals("apiKeyScopes", scopes)
+	c.Locals("authSource", "api_key")
+
+	return c.Next()
+}
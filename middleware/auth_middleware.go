@@ -1,52 +1,136 @@
 package middleware
 
 import (
+	"log"
 	"strings"
 
 	"github.com/WBianchi/maiscrianca/auth"
 	"github.com/WBianchi/maiscrianca/configs"
 	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/WBianchi/maiscrianca/repository"
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthMiddleware verifica se o usuário está autenticado
+// AuthMiddleware verifica se o usuário está autenticado pelo cabeçalho
+// Authorization: Bearer, pela chave de API em X-API-Key ou, na falta deles, pelo cookie de sessão.
+// A chave de API só é aceita nas rotas liberadas por AllowAPIKey.
 func AuthMiddleware(config *configs.Config) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 		
+		// Integrações servidor a servidor se autenticam com chave de API em vez de token
+		if apiKey := c.Get(auth.APIKeyHeader); apiKey != "" && authHeader == "" {
+			if allowed, _ := c.Locals("apiKeyAllowed").(bool); !allowed {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Chaves de API não são aceitas nesta rota",
+				})
+			}
+			return apiKeyAuth(c, apiKey)
+		}
+		
+		// Sem cabeçalho, aceitar o cookie de sessão (a proteção CSRF fica em CSRFMiddleware)
+		var tokenString string
+		authSource := "bearer"
 		if authHeader == "" {
+			tokenString = c.Cookies(auth.AccessTokenCookie)
+			authSource = "cookie"
+		} else {
+			// Verificar formato "Bearer {token}"
+			parts := strings.Split(authHeader, " ")
+			if len(parts) != 2 || parts[0] != "Bearer" {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Formato de autorização inválido",
+				})
+			}
+			tokenString = parts[1]
+		}
+		
+		if tokenString == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error": "Não autorizado: token não fornecido",
 			})
 		}
 		
-		// Verificar formato "Bearer {token}"
-		parts := strings.Split(authHeader, " ")
-		if len(parts) != 2 || parts[0] != "Bearer" {
+		claims, err := auth.ValidateToken(tokenString, config)
+		
+		if err != nil {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Formato de autorização inválido",
+				"error": "Token inválido: " + err.Error(),
 			})
 		}
 		
-		tokenString := parts[1]
-		claims, err := auth.ValidateToken(tokenString, config)
+		// Tokens de uso restrito (ex.: desafio de 2FA) não dão acesso à API
+		if claims.Purpose != "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token inválido para esta operação",
+			})
+		}
 		
+		// Verificar se o token foi revogado (logout)
+		if claims.ID == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Token inválido: identificador ausente",
+			})
+		}
+		revoked, err := repository.IsAccessTokenRevoked(claims.ID)
 		if err != nil {
+			log.Printf("Erro ao verificar revogação do token: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+		if revoked {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Token inválido: " + err.Error(),
+				"error": "Token revogado",
 			})
 		}
 		
+		// Tokens ligados a uma sessão deixam de valer assim que ela é encerrada
+		if claims.SessionID != "" {
+			active, err := repository.TouchSession(claims.SessionID)
+			if err != nil {
+				log.Printf("Erro ao verificar sessão: %v", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Erro interno do servidor",
+				})
+			}
+			if !active {
+				return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+					"error": "Sessão encerrada",
+				})
+			}
+		}
+		
 		// Adicionar dados do usuário ao contexto
 		c.Locals("userId", claims.UserID)
 		c.Locals("userRole", claims.Role)
+		c.Locals("tokenEspacoId", claims.EspacoID)
+		c.Locals("childProfileId", claims.ChildProfileID)
+		c.Locals("tokenId", claims.ID)
+		c.Locals("sessionId", claims.SessionID)
+		c.Locals("tokenExpiresAt", claims.ExpiresAt.Time)
+		c.Locals("authSource", authSource)
+		
+		// Tokens de personificação: o administrador precisa continuar ADMIN e cada requisição é auditada
+		if impersonatorId := claims.ImpersonatorID(); impersonatorId != "" {
+			return impersonatedRequest(c, impersonatorId, claims.UserID)
+		}
 		
 		return c.Next()
 	}
 }
 
-// RoleGuard verifica se o usuário possui a role necessária
-func RoleGuard(roles ...models.Role) fiber.Handler {
+// Requirement é o que RoleGuard pode exigir: uma role ou uma permissão do pacote policy
+type Requirement interface {
+	models.Role | policy.Permission
+}
+
+// RoleGuard verifica se o usuário atende a pelo menos um dos requisitos: uma role, seja a role
+// global ou a role no espaço resolvido por EspacoMiddleware, ou uma permissão resolvida pelo
+// pacote policy, como em RequirePermission. Para exigir várias permissões ao mesmo tempo,
+// use RequirePermission.
+func RoleGuard[R Requirement](required ...R) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userRole, ok := c.Locals("userRole").(models.Role)
 		
@@ -56,9 +140,18 @@ func RoleGuard(roles ...models.Role) fiber.Handler {
 			})
 		}
 		
-		for _, role := range roles {
-			if userRole == role {
-				return c.Next()
+		espacoRole, _ := c.Locals("espacoRole").(models.Role)
+		
+		for _, requirement := range required {
+			switch r := any(requirement).(type) {
+			case models.Role:
+				if userRole == r || espacoRole == r {
+					return c.Next()
+				}
+			case policy.Permission:
+				if Permissions(c).Has(r) {
+					return c.Next()
+				}
 			}
 		}
 		
@@ -67,3 +160,48 @@ func RoleGuard(roles ...models.Role) fiber.Handler {
 		})
 	}
 }
+
+// RequirePermission verifica se o usuário tem todas as permissões informadas, resolvidas pelo
+// pacote policy a partir da role global e, depois de EspacoMiddleware, do vínculo com o espaço
+// (role ou papel personalizado). Pode ser usado junto com RoleGuard.
+func RequirePermission(permissions ...policy.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if _, ok := c.Locals("userRole").(models.Role); !ok {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Informações de autenticação ausentes",
+			})
+		}
+
+		granted := Permissions(c)
+		for _, permission := range permissions {
+			if !granted.Has(permission) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error":      "Acesso negado: permissão insuficiente",
+					"permission": permission,
+				})
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+// Permissions retorna as permissões efetivas do usuário da requisição. O resultado é
+// calculado uma vez e guardado no contexto, evitando recalcular a cada RequirePermission.
+func Permissions(c *fiber.Ctx) policy.Set {
+	if cached, ok := c.Locals("permissions").(policy.Set); ok {
+		return cached
+	}
+
+	userRole, _ := c.Locals("userRole").(models.Role)
+	membro, _ := c.Locals("espacoMembro").(*models.EspacoMembro)
+	permissions := policy.Resolve(userRole, membro)
+
+	// Chaves de API nunca excedem os escopos concedidos, nem as permissões do próprio usuário
+	if scopes, ok := c.Locals("apiKeyScopes").(policy.Set); ok {
+		permissions = permissions.Intersect(scopes)
+	}
+
+	c.Locals("permissions", permissions)
+	return permissions
+}
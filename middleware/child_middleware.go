@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireChildProfile exige um perfil infantil selecionado (claim childProfileId) que ainda
+// pertença ao usuário e disponibiliza o perfil em Locals("childProfile").
+// Deve ser usado depois de AuthMiddleware.
+func RequireChildProfile() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		childProfileId, _ := c.Locals("childProfileId").(string)
+		if childProfileId == "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Selecione um perfil infantil para continuar",
+				"code":  "CHILD_PROFILE_REQUIRED",
+			})
+		}
+		return loadChildProfile(c)
+	}
+}
+
+// ChildProfileContext carrega o perfil infantil selecionado, quando houver, em Locals("childProfile")
+// para que os controles parentais sejam aplicados. Sem perfil selecionado a requisição segue sem restrições.
+// Deve ser usado depois de AuthMiddleware.
+func ChildProfileContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if childProfileId, _ := c.Locals("childProfileId").(string); childProfileId == "" {
+			return c.Next()
+		}
+		return loadChildProfile(c)
+	}
+}
+
+// EnforceScreenTime recusa a leitura quando o perfil infantil selecionado atingiu o limite diário
+// definido pelos responsáveis. O tempo lido é medido no servidor pelas aberturas, downloads e
+// atualizações de progresso (repository.RecordReadingActivity), não informado pelo app.
+// Deve ser usado depois de RequireChildProfile ou ChildProfileContext.
+func EnforceScreenTime() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		profile, _ := c.Locals("childProfile").(*models.ChildProfile)
+		if profile == nil || profile.Controls.DailyLimitMinutes == nil {
+			return c.Next()
+		}
+
+		seconds, err := repository.GetReadingTimeToday(profile.ID)
+		if err != nil {
+			log.Printf("Erro ao buscar tempo de leitura: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+		if seconds >= *profile.Controls.DailyLimitMinutes*60 {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "O tempo de leitura de hoje acabou",
+				"code":  "SCREEN_TIME_EXCEEDED",
+			})
+		}
+
+		return c.Next()
+	}
+}
+
+// BlockChildMode recusa a requisição enquanto um perfil infantil estiver selecionado, para que
+// a criança não use as funções da conta do responsável. Deve ser usado depois de AuthMiddleware.
+func BlockChildMode() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if childProfileId, _ := c.Locals("childProfileId").(string); childProfileId != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Saia do perfil infantil para continuar",
+				"code":  "CHILD_MODE",
+			})
+		}
+		return c.Next()
+	}
+}
+
+// loadChildProfile busca o perfil infantil do claim e segue para o próximo handler. Um perfil
+// removido não libera o acesso irrestrito: o responsável precisa sair dele antes.
+func loadChildProfile(c *fiber.Ctx) error {
+	userId, ok := c.Locals("userId").(string)
+	if !ok || userId == "" {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Informações de autenticação ausentes",
+		})
+	}
+
+	profile, err := repository.GetChildProfile(userId, c.Locals("childProfileId").(string))
+	if err == repository.ErrNotFound {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "O perfil infantil selecionado não existe mais",
+			"code":  "CHILD_PROFILE_REQUIRED",
+		})
+	}
+	if err != nil {
+		log.Printf("Erro ao buscar perfil infantil: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+
+	c.Locals("childProfile", profile)
+	return c.Next()
+}
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+
+	"github.com/WBianchi/maiscrianca/auth"
+	"github.com/gofiber/fiber/v2"
+)
+
+// CSRFMiddleware aplica a validação double-submit às requisições que alteram estado
+// e dependem dos cookies de sessão: o cabeçalho X-CSRF-Token deve repetir o cookie mc_csrf.
+// Requisições com Authorization: Bearer não usam cookies e não precisam do token.
+func CSRFMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		switch c.Method() {
+		case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+			return c.Next()
+		}
+
+		if c.Get("Authorization") != "" {
+			return c.Next()
+		}
+		if c.Cookies(auth.AccessTokenCookie) == "" && c.Cookies(auth.RefreshTokenCookie) == "" {
+			return c.Next()
+		}
+
+		cookie := c.Cookies(auth.CSRFCookie)
+		header := c.Get(auth.CSRFHeader)
+		if cookie == "" || subtle.ConstantTimeCompare([]byte(cookie), []byte(header)) != 1 {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Token CSRF ausente ou inválido",
+				"code":  "CSRF_INVALID",
+			})
+		}
+
+		return c.Next()
+	}
+}
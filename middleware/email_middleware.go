@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireVerifiedEmail bloqueia a ação quando a política de configuração exige
+// email verificado e o usuário ainda não o confirmou.
+// Deve ser usado depois de AuthMiddleware.
+func RequireVerifiedEmail(config *configs.Config, action string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !config.RequiresVerifiedEmail(action) {
+			return c.Next()
+		}
+
+		userId, ok := c.Locals("userId").(string)
+		if !ok || userId == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Informações de autenticação ausentes",
+			})
+		}
+
+		verified, err := repository.IsEmailVerified(userId)
+		if err != nil {
+			log.Printf("Erro ao verificar email do usuário: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+
+		if !verified {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Confirme seu email para continuar",
+				"code":  "EMAIL_NOT_VERIFIED",
+			})
+		}
+
+		return c.Next()
+	}
+}
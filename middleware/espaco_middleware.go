@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// EspacoMiddleware resolve o espaço da requisição e verifica se o usuário pertence a ele.
+// O espaço vem do parâmetro de rota :espacoId, do cabeçalho X-Espaco-Id, do claim
+// espacoId do token ou, na falta deles, do primeiro espaço ao qual o usuário foi vinculado.
+// Chaves de API só acessam o espaço ao qual foram vinculadas.
+// Se o espaço exigir 2FA, membros ADMIN e EMPLOYEE sem 2FA ativo são recusados.
+// Deve ser usado depois de AuthMiddleware.
+func EspacoMiddleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userId, ok := c.Locals("userId").(string)
+		if !ok || userId == "" {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Informações de autenticação ausentes",
+			})
+		}
+
+		espacoId := c.Params("espacoId")
+		if espacoId == "" {
+			espacoId = c.Get("X-Espaco-Id")
+		}
+		if espacoId == "" {
+			espacoId, _ = c.Locals("tokenEspacoId").(string)
+		}
+
+		// Chaves de API só acessam o espaço ao qual foram vinculadas
+		if boundEspacoId, _ := c.Locals("apiKeyEspacoId").(string); boundEspacoId != "" && espacoId != boundEspacoId {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Acesso negado: a chave de API não tem acesso a este espaço",
+			})
+		}
+
+		var membro *models.EspacoMembro
+		var err error
+		if espacoId != "" {
+			membro, err = repository.GetEspacoMembro(espacoId, userId)
+		} else {
+			membro, err = repository.GetDefaultEspacoMembro(userId)
+		}
+
+		if err == repository.ErrNotFound {
+			if espacoId == "" {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Acesso negado: usuário não está vinculado a nenhum espaço",
+				})
+			}
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Acesso negado: usuário não pertence a este espaço",
+			})
+		}
+		if err != nil {
+			log.Printf("Erro ao verificar vínculo com o espaço: %v", err)
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Erro interno do servidor",
+			})
+		}
+
+		// Espaços que exigem 2FA bloqueiam membros privilegiados até que ativem o segundo fator
+		userRole, _ := c.Locals("userRole").(models.Role)
+		if membro.Role.IsPrivileged() || userRole.IsPrivileged() {
+			required, err := repository.RequiresMFAEnrollment(membro.EspacoId, userId)
+			if err != nil && err != repository.ErrNotFound {
+				log.Printf("Erro ao verificar exigência de 2FA: %v", err)
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Erro interno do servidor",
+				})
+			}
+			if required {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"error": "Este espaço exige autenticação em dois fatores. Ative o 2FA para continuar",
+					"code":  "MFA_ENROLLMENT_REQUIRED",
+				})
+			}
+		}
+
+		// Adicionar dados do espaço ao contexto
+		c.Locals("espacoId", membro.EspacoId)
+		c.Locals("espacoRole", membro.Role)
+		c.Locals("espacoMembro", membro)
+		// Permissões calculadas antes da resolução do espaço deixam de valer
+		c.Locals("permissions", nil)
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// impersonatedRequest executa uma requisição feita com token de personificação: confere que quem
+// personifica ainda é ADMIN com a conta ativa e registra a requisição e o status da resposta no log de auditoria
+func impersonatedRequest(c *fiber.Ctx, impersonatorId string, userId string) error {
+	impersonator, err := repository.GetUserById(impersonatorId)
+	if err != nil && err != repository.ErrNotFound {
+		log.Printf("Erro ao buscar administrador da personificação: %v", err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Erro interno do servidor",
+		})
+	}
+	if err == repository.ErrNotFound || impersonator.Role != models.ADMIN || !impersonator.IsActive() {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Personificação não autorizada",
+		})
+	}
+
+	c.Locals("impersonatorId", impersonatorId)
+
+	handlerErr := c.Next()
+
+	// Erros devolvidos pelo handler só viram resposta no ErrorHandler, depois deste middleware
+	status := c.Response().StatusCode()
+	details := map[string]interface{}{
+		"method":  c.Method(),
+		"path":    c.Path(),
+		"tokenId": c.Locals("tokenId"),
+	}
+	if handlerErr != nil {
+		status = fiber.StatusInternalServerError
+		if e, ok := handlerErr.(*fiber.Error); ok {
+			status = e.Code
+		}
+		details["error"] = handlerErr.Error()
+	}
+	details["status"] = status
+
+	entry := &models.AuditLog{
+		ActorId:      impersonatorId,
+		Action:       models.AuditImpersonatedRequest,
+		TargetUserId: userId,
+		Details:      details,
+		IP:           c.IP(),
+	}
+	if err := repository.CreateAuditLog(entry); err != nil {
+		log.Printf("Erro ao registrar auditoria (%s): %v", entry.Action, err)
+	}
+
+	return handlerErr
+}
+
+// BlockImpersonation recusa a ação quando a requisição não vem do próprio usuário: token de
+// personificação ou chave de API. Deve proteger operações sensíveis, como troca de senha, 2FA,
+// sessões e dados de pagamento. Deve ser usado depois de AuthMiddleware.
+func BlockImpersonation() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if impersonatorId, _ := c.Locals("impersonatorId").(string); impersonatorId != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Esta ação não é permitida durante a personificação de usuário",
+				"code":  "IMPERSONATION_FORBIDDEN",
+			})
+		}
+		if apiKeyId, _ := c.Locals("apiKeyId").(string); apiKeyId != "" {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "Esta ação não é permitida com chave de API",
+				"code":  "API_KEY_FORBIDDEN",
+			})
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,30 @@
+-- Catálogo de livros e categorias, separado por espaço
+CREATE TABLE IF NOT EXISTS "Categoria" (
+    id          TEXT PRIMARY KEY,
+    nome        TEXT NOT NULL,
+    descricao   TEXT,
+    "espacoId"  TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "Categoria_espacoId_idx" ON "Categoria" ("espacoId");
+
+CREATE TABLE IF NOT EXISTS "Livro" (
+    id            TEXT PRIMARY KEY,
+    titulo        TEXT NOT NULL,
+    autor         TEXT,
+    descricao     TEXT,
+    capa          TEXT,
+    arquivo       TEXT,
+    paginas       TEXT[] NOT NULL DEFAULT '{}',
+    preco         NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    "categoriaId" TEXT REFERENCES "Categoria" (id),
+    publicado     BOOLEAN NOT NULL DEFAULT FALSE,
+    "espacoId"    TEXT NOT NULL,
+    "createdAt"   TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt"   TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "Livro_espacoId_idx" ON "Livro" ("espacoId");
+CREATE INDEX IF NOT EXISTS "Livro_categoriaId_idx" ON "Livro" ("categoriaId");
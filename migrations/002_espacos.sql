@@ -0,0 +1,17 @@
+-- Espaços (tenants) e vínculo de usuários com cada espaço
+CREATE TABLE IF NOT EXISTS "Espaco" (
+    id          TEXT PRIMARY KEY,
+    nome        TEXT NOT NULL,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS "EspacoMembro" (
+    "espacoId"  TEXT NOT NULL REFERENCES "Espaco" (id) ON DELETE CASCADE,
+    "userId"    TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    role        TEXT NOT NULL DEFAULT 'CLIENT',
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("espacoId", "userId")
+);
+
+CREATE INDEX IF NOT EXISTS "EspacoMembro_userId_idx" ON "EspacoMembro" ("userId");
@@ -0,0 +1,16 @@
+-- Convites por email para participar de um espaço com uma role específica
+CREATE TABLE IF NOT EXISTS "ConviteEspaco" (
+    id            TEXT PRIMARY KEY,
+    "espacoId"    TEXT NOT NULL REFERENCES "Espaco" (id) ON DELETE CASCADE,
+    email         TEXT NOT NULL,
+    role          TEXT NOT NULL,
+    "tokenHash"   TEXT NOT NULL UNIQUE,
+    "invitedById" TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    "expiresAt"   TIMESTAMP(3) NOT NULL,
+    "acceptedAt"  TIMESTAMP(3),
+    "revokedAt"   TIMESTAMP(3),
+    "createdAt"   TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "ConviteEspaco_espacoId_idx" ON "ConviteEspaco" ("espacoId");
+CREATE INDEX IF NOT EXISTS "ConviteEspaco_email_idx" ON "ConviteEspaco" (LOWER(email));
@@ -0,0 +1,19 @@
+-- Tokens de atualização rotativos e lista de tokens de acesso revogados
+CREATE TABLE IF NOT EXISTS "RefreshToken" (
+    id             TEXT PRIMARY KEY,
+    "userId"       TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    "familyId"     TEXT NOT NULL,
+    "tokenHash"    TEXT NOT NULL UNIQUE,
+    "expiresAt"    TIMESTAMP(3) NOT NULL,
+    "revokedAt"    TIMESTAMP(3),
+    "replacedById" TEXT,
+    "createdAt"    TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "RefreshToken_userId_idx" ON "RefreshToken" ("userId");
+CREATE INDEX IF NOT EXISTS "RefreshToken_familyId_idx" ON "RefreshToken" ("familyId");
+
+CREATE TABLE IF NOT EXISTS "RevokedToken" (
+    jti         TEXT PRIMARY KEY,
+    "expiresAt" TIMESTAMP(3) NOT NULL
+);
@@ -0,0 +1,24 @@
+-- Log de auditoria e códigos de convite para cadastro de funcionários e afiliados
+CREATE TABLE IF NOT EXISTS "AuditLog" (
+    id             TEXT PRIMARY KEY,
+    "actorId"      TEXT,
+    action         TEXT NOT NULL,
+    "targetUserId" TEXT,
+    details        JSONB NOT NULL DEFAULT '{}',
+    ip             TEXT,
+    "createdAt"    TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "AuditLog_targetUserId_idx" ON "AuditLog" ("targetUserId");
+CREATE INDEX IF NOT EXISTS "AuditLog_createdAt_idx" ON "AuditLog" ("createdAt");
+
+CREATE TABLE IF NOT EXISTS "CodigoConvite" (
+    id            TEXT PRIMARY KEY,
+    "codeHash"    TEXT NOT NULL UNIQUE,
+    role          TEXT NOT NULL,
+    "createdById" TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    "expiresAt"   TIMESTAMP(3) NOT NULL,
+    "usedAt"      TIMESTAMP(3),
+    "usedById"    TEXT REFERENCES "User" (id) ON DELETE SET NULL,
+    "createdAt"   TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
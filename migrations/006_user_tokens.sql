@@ -0,0 +1,13 @@
+-- Tokens de uso único enviados por email (redefinição de senha e afins)
+CREATE TABLE IF NOT EXISTS "UserToken" (
+    id          TEXT PRIMARY KEY,
+    "userId"    TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    purpose     TEXT NOT NULL,
+    "tokenHash" TEXT NOT NULL UNIQUE,
+    data        TEXT,
+    "expiresAt" TIMESTAMP(3) NOT NULL,
+    "usedAt"    TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "UserToken_userId_purpose_idx" ON "UserToken" ("userId", purpose);
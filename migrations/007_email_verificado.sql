@@ -0,0 +1,2 @@
+-- Data de verificação do email do usuário
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "emailVerifiedAt" TIMESTAMP(3);
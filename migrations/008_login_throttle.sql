@@ -0,0 +1,7 @@
+-- Contadores de falhas de login por IP e por conta, com bloqueio temporário
+CREATE TABLE IF NOT EXISTS "LoginThrottle" (
+    key             TEXT PRIMARY KEY,
+    failures        INTEGER NOT NULL DEFAULT 0,
+    "lockedUntil"   TIMESTAMP(3),
+    "lastFailureAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,17 @@
+-- Autenticação em dois fatores (TOTP) com códigos de recuperação
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "totpSecret" TEXT;
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "totpEnabledAt" TIMESTAMP(3);
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "totpLastStep" BIGINT NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS "RecoveryCode" (
+    id          TEXT PRIMARY KEY,
+    "userId"    TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    "codeHash"  TEXT NOT NULL,
+    "usedAt"    TIMESTAMP(3),
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "RecoveryCode_userId_idx" ON "RecoveryCode" ("userId");
+
+-- Espaços podem exigir 2FA dos membros ADMIN e EMPLOYEE
+ALTER TABLE "Espaco" ADD COLUMN IF NOT EXISTS "exigir2FA" BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,21 @@
+-- Sessões de login: cada família de tokens de atualização corresponde a uma sessão (Session.id = familyId)
+CREATE TABLE IF NOT EXISTS "Session" (
+    id           TEXT PRIMARY KEY,
+    "userId"     TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    device       TEXT NOT NULL DEFAULT '',
+    "userAgent"  TEXT NOT NULL DEFAULT '',
+    ip           TEXT NOT NULL DEFAULT '',
+    "createdAt"  TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "lastSeenAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "revokedAt"  TIMESTAMP(3)
+);
+
+CREATE INDEX IF NOT EXISTS "Session_userId_idx" ON "Session" ("userId");
+
+-- Famílias ativas criadas antes desta migração passam a ter uma sessão
+INSERT INTO "Session" (id, "userId", "createdAt", "lastSeenAt")
+SELECT "familyId", "userId", MIN("createdAt"), MAX("createdAt")
+FROM "RefreshToken"
+WHERE "revokedAt" IS NULL AND "expiresAt" > NOW()
+GROUP BY "familyId", "userId"
+ON CONFLICT (id) DO NOTHING;
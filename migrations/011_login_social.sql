@@ -0,0 +1,22 @@
+-- Login social (OpenID Connect): contas vinculadas e estados pendentes do fluxo authorization code
+CREATE TABLE IF NOT EXISTS "UserIdentity" (
+    id          TEXT PRIMARY KEY,
+    "userId"    TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    provider    TEXT NOT NULL,
+    subject     TEXT NOT NULL,
+    email       TEXT NOT NULL DEFAULT '',
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject),
+    UNIQUE ("userId", provider)
+);
+
+CREATE TABLE IF NOT EXISTS "OIDCState" (
+    "stateHash"    TEXT PRIMARY KEY,
+    provider       TEXT NOT NULL,
+    "codeVerifier" TEXT NOT NULL,
+    nonce          TEXT NOT NULL,
+    -- Preenchido quando o fluxo vincula o provedor a uma conta já autenticada
+    "userId"       TEXT REFERENCES "User" (id) ON DELETE CASCADE,
+    "expiresAt"    TIMESTAMP(3) NOT NULL,
+    "createdAt"    TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,16 @@
+-- Papéis personalizados por espaço, com permissões finas no formato recurso:ação
+CREATE TABLE IF NOT EXISTS "EspacoPapel" (
+    id          TEXT PRIMARY KEY,
+    "espacoId"  TEXT NOT NULL REFERENCES "Espaco" (id) ON DELETE CASCADE,
+    nome        TEXT NOT NULL,
+    descricao   TEXT,
+    permissions TEXT[] NOT NULL DEFAULT '{}',
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE ("espacoId", nome)
+);
+
+-- Membro com papel personalizado usa as permissões do papel no lugar das da role no espaço
+ALTER TABLE "EspacoMembro" ADD COLUMN IF NOT EXISTS "papelId" TEXT REFERENCES "EspacoPapel" (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS "EspacoMembro_papelId_idx" ON "EspacoMembro" ("papelId");
@@ -0,0 +1,17 @@
+-- Chaves de API para integrações servidor a servidor (ERP, marketing)
+CREATE TABLE IF NOT EXISTS "ApiKey" (
+    id            TEXT PRIMARY KEY,
+    name          TEXT NOT NULL,
+    prefix        TEXT NOT NULL UNIQUE,
+    "keyHash"     TEXT NOT NULL UNIQUE,
+    "userId"      TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    "espacoId"    TEXT NOT NULL REFERENCES "Espaco" (id) ON DELETE CASCADE,
+    scopes        TEXT[] NOT NULL DEFAULT '{}',
+    "expiresAt"   TIMESTAMP(3) NOT NULL,
+    "lastUsedAt"  TIMESTAMP(3),
+    "revokedAt"   TIMESTAMP(3),
+    "createdById" TEXT REFERENCES "User" (id) ON DELETE SET NULL,
+    "createdAt"   TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "ApiKey_espacoId_idx" ON "ApiKey" ("espacoId");
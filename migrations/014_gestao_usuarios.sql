@@ -0,0 +1,8 @@
+-- Estado da conta gerido pelos administradores: suspensão, exclusão lógica e troca de senha obrigatória
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "suspendedAt" TIMESTAMP(3);
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "suspendedReason" TEXT;
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "deletedAt" TIMESTAMP(3);
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "passwordResetRequired" BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Listagem administrativa ordenada e filtrada pela data de cadastro
+CREATE INDEX IF NOT EXISTS "User_createdAt_idx" ON "User" ("createdAt");
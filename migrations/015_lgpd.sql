@@ -0,0 +1,18 @@
+-- Pedidos de exclusão de dados pessoais (LGPD), executados após o período de carência
+CREATE TABLE IF NOT EXISTS "DataDeletionRequest" (
+    id              TEXT PRIMARY KEY,
+    "userId"        TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    reason          TEXT,
+    "requestedAt"   TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "scheduledFor"  TIMESTAMP(3) NOT NULL,
+    "canceledAt"    TIMESTAMP(3),
+    "completedAt"   TIMESTAMP(3)
+);
+
+-- Cada usuário tem no máximo um pedido pendente
+CREATE UNIQUE INDEX IF NOT EXISTS "DataDeletionRequest_pending_idx"
+    ON "DataDeletionRequest" ("userId") WHERE "canceledAt" IS NULL AND "completedAt" IS NULL;
+CREATE INDEX IF NOT EXISTS "DataDeletionRequest_scheduledFor_idx" ON "DataDeletionRequest" ("scheduledFor");
+
+-- A linha do usuário anonimizado é mantida para os registros que a lei obriga a guardar
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "anonymizedAt" TIMESTAMP(3);
@@ -0,0 +1,34 @@
+-- Perfis de leitores infantis de uma conta CLIENT
+CREATE TABLE IF NOT EXISTS "ChildProfile" (
+    id          TEXT PRIMARY KEY,
+    "userId"    TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    "birthDate" DATE,
+    -- Faixa etária informada quando os responsáveis preferem não registrar a data de nascimento
+    "ageBand"   TEXT,
+    avatar      TEXT,
+    "pinHash"   TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "ChildProfile_userId_idx" ON "ChildProfile" ("userId");
+
+-- Progresso de leitura de cada perfil infantil
+CREATE TABLE IF NOT EXISTS "ReadingProgress" (
+    "childProfileId" TEXT NOT NULL REFERENCES "ChildProfile" (id) ON DELETE CASCADE,
+    "livroId"        TEXT NOT NULL REFERENCES "Livro" (id) ON DELETE CASCADE,
+    pagina           INTEGER NOT NULL DEFAULT 0,
+    concluido        BOOLEAN NOT NULL DEFAULT FALSE,
+    "startedAt"      TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updatedAt"      TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("childProfileId", "livroId")
+);
+
+-- Livros favoritos de cada perfil infantil
+CREATE TABLE IF NOT EXISTS "ChildFavorite" (
+    "childProfileId" TEXT NOT NULL REFERENCES "ChildProfile" (id) ON DELETE CASCADE,
+    "livroId"        TEXT NOT NULL REFERENCES "Livro" (id) ON DELETE CASCADE,
+    "createdAt"      TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("childProfileId", "livroId")
+);
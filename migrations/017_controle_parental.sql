@@ -0,0 +1,22 @@
+-- Classificação indicativa dos livros: idade mínima recomendada (0 = livre)
+ALTER TABLE "Livro" ADD COLUMN IF NOT EXISTS classificacao INTEGER NOT NULL DEFAULT 0;
+
+-- Controles parentais de cada perfil infantil; NULL significa sem limite
+ALTER TABLE "ChildProfile" ADD COLUMN IF NOT EXISTS "maxClassificacao" INTEGER;
+ALTER TABLE "ChildProfile" ADD COLUMN IF NOT EXISTS "blockedCategoriaIds" TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE "ChildProfile" ADD COLUMN IF NOT EXISTS "dailyLimitMinutes" INTEGER;
+
+-- PIN do responsável, alternativa à senha para alterar os controles e sair do perfil infantil
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "parentalPinHash" TEXT;
+
+-- Perfil infantil selecionado na sessão, restaurado ao renovar o token de acesso.
+-- Sem chave estrangeira: se o perfil for removido, a sessão continua presa a ele até o responsável sair.
+ALTER TABLE "Session" ADD COLUMN IF NOT EXISTS "childProfileId" TEXT;
+
+-- Tempo de leitura de cada perfil infantil por dia, para o limite diário
+CREATE TABLE IF NOT EXISTS "ReadingTime" (
+    "childProfileId" TEXT NOT NULL REFERENCES "ChildProfile" (id) ON DELETE CASCADE,
+    day              DATE NOT NULL,
+    seconds          INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY ("childProfileId", day)
+);
@@ -0,0 +1,3 @@
+-- Miniaturas do avatar enviado pelo usuário, por tamanho em pixels ({"512": url, ...});
+-- "profileAvatar" aponta para a miniatura padrão
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS "avatarThumbnails" JSONB;
@@ -0,0 +1,3 @@
+-- A troca de email confirma a unicidade também no banco, para que duas contas
+-- não assumam o mesmo endereço ao confirmar links ao mesmo tempo
+CREATE UNIQUE INDEX IF NOT EXISTS "User_email_key" ON "User" (email);
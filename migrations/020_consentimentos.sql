@@ -0,0 +1,13 @@
+-- Histórico de consentimentos do titular (LGPD): aceite dos termos e da política de
+-- privacidade e opção por marketing. Cada decisão é um novo registro; a mais recente vale.
+CREATE TABLE IF NOT EXISTS "UserConsent" (
+    id          TEXT PRIMARY KEY,
+    "userId"    TEXT NOT NULL REFERENCES "User" (id) ON DELETE CASCADE,
+    purpose     TEXT NOT NULL,
+    granted     BOOLEAN NOT NULL,
+    version     TEXT,
+    ip          TEXT,
+    "createdAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS "UserConsent_userId_purpose_idx" ON "UserConsent" ("userId", purpose, "createdAt");
@@ -0,0 +1,3 @@
+-- Espaço ativo selecionado na sessão, restaurado ao renovar o token de acesso.
+-- A participação no espaço continua sendo verificada a cada requisição pelo middleware de espaço.
+ALTER TABLE "Session" ADD COLUMN IF NOT EXISTS "espacoId" TEXT REFERENCES "Espaco" (id) ON DELETE SET NULL;
@@ -0,0 +1,9 @@
+-- Última atividade de leitura (abertura, download ou progresso) de cada perfil infantil em cada
+-- livro. O tempo de leitura do limite diário é calculado no servidor a partir do intervalo entre
+-- duas atividades, sem depender do tempo informado pelo app.
+CREATE TABLE IF NOT EXISTS "ReadingActivity" (
+    "childProfileId" TEXT NOT NULL REFERENCES "ChildProfile" (id) ON DELETE CASCADE,
+    "livroId"        TEXT NOT NULL REFERENCES "Livro" (id) ON DELETE CASCADE,
+    "lastReadAt"     TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("childProfileId", "livroId")
+);
@@ -0,0 +1,5 @@
+-- Perfis infantis passam a aceitar apenas os avatares ilustrados (models.ChildAvatars);
+-- endereços de imagem gravados antes disso são descartados
+UPDATE "ChildProfile" SET avatar = NULL
+WHERE avatar IS NOT NULL
+  AND avatar NOT IN ('urso', 'coelho', 'gato', 'cachorro', 'leao', 'coruja', 'raposa', 'panda', 'dinossauro', 'foguete');
@@ -0,0 +1,73 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"log"
+	"sort"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// Run aplica, em ordem, os scripts SQL que ainda não foram executados no banco
+func Run(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS "SchemaMigration" (
+		version     TEXT PRIMARY KEY,
+		"appliedAt" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return fmt.Errorf("erro ao criar tabela de migrações: %w", err)
+	}
+
+	names, err := files.ReadDir(".")
+	if err != nil {
+		return err
+	}
+
+	var versions []string
+	for _, entry := range names {
+		versions = append(versions, entry.Name())
+	}
+	sort.Strings(versions)
+
+	for _, version := range versions {
+		var applied bool
+		err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM "SchemaMigration" WHERE version = $1)`, version).Scan(&applied)
+		if err != nil {
+			return fmt.Errorf("erro ao verificar migração %s: %w", version, err)
+		}
+		if applied {
+			continue
+		}
+
+		script, err := files.ReadFile(version)
+		if err != nil {
+			return err
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(string(script)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("erro ao aplicar migração %s: %w", version, err)
+		}
+
+		if _, err := tx.Exec(`INSERT INTO "SchemaMigration" (version) VALUES ($1)`, version); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("erro ao registrar migração %s: %w", version, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+
+		log.Printf("Migração aplicada: %s", version)
+	}
+
+	return nil
+}
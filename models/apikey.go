@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+)
+
+// APIKey representa uma chave de API de integração. A chave age como o usuário UserId,
+// apenas no espaço EspacoId e limitada às permissões em Scopes.
+type APIKey struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name"`
+	Prefix      string     `json:"prefix"`
+	UserId      string     `json:"userId"`
+	EspacoId    string     `json:"espacoId"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	LastUsedAt  *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	CreatedById string     `json:"createdById"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// Ações registradas no log de auditoria
+const (
+	AuditUserRoleChanged      = "user.role_changed"
+	AuditInviteCodeIssued     = "invite_code.issued"
+	AuditInviteCodeRevoked    = "invite_code.revoked"
+	AuditAccountLocked        = "account.locked"
+	AuditAccountUnlocked      = "account.unlocked"
+	AuditIPLocked             = "ip.locked"
+	AuditMFAEnabled           = "mfa.enabled"
+	AuditMFADisabled          = "mfa.disabled"
+	AuditRecoveryCodesNew     = "mfa.recovery_codes_regenerated"
+	AuditRecoveryCodeUsed     = "mfa.recovery_code_used"
+	AuditSessionsRevoked      = "user.sessions_revoked"
+	AuditIdentityLinked       = "user.identity_linked"
+	AuditIdentityUnlinked     = "user.identity_unlinked"
+	AuditPapelCreated         = "espaco.papel_created"
+	AuditPapelUpdated         = "espaco.papel_updated"
+	AuditPapelDeleted         = "espaco.papel_deleted"
+	AuditPapelAssigned        = "espaco.papel_assigned"
+	AuditImpersonationStarted = "admin.impersonation_started"
+	AuditImpersonatedRequest  = "admin.impersonated_request"
+	AuditAPIKeyCreated        = "api_key.created"
+	AuditAPIKeyRevoked        = "api_key.revoked"
+	AuditUserSuspended        = "user.suspended"
+	AuditUserReactivated      = "user.reactivated"
+	AuditPasswordResetForced  = "user.password_reset_forced"
+	AuditUserDeleted          = "user.deleted"
+	AuditDataExported         = "lgpd.data_exported"
+	AuditDataDeletionRequest  = "lgpd.deletion_requested"
+	AuditDataDeletionCanceled = "lgpd.deletion_canceled"
+	AuditDataErased           = "lgpd.data_erased"
+	AuditParentalControlsSet  = "parental.controls_updated"
+	AuditParentalPinChanged   = "parental.pin_changed"
+	AuditEmailChangeRequested = "user.email_change_requested"
+	AuditEmailChanged         = "user.email_changed"
+	AuditEmailChangeUndone    = "user.email_change_undone"
+	AuditPasswordChanged      = "user.password_changed"
+)
+
+// AuditLog representa um registro de auditoria de uma ação administrativa ou de segurança
+type AuditLog struct {
+	ID           string                 `json:"id"`
+	ActorId      string                 `json:"actorId,omitempty"`
+	Action       string                 `json:"action"`
+	TargetUserId string                 `json:"targetUserId,omitempty"`
+	Details      map[string]interface{} `json:"details,omitempty"`
+	IP           string                 `json:"ip,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+}
+
+// CodigoConvite representa um código de uso único que permite o cadastro com uma role específica
+type CodigoConvite struct {
+	ID          string     `json:"id"`
+	Role        Role       `json:"role"`
+	CreatedById string     `json:"createdById"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	UsedAt      *time.Time `json:"usedAt,omitempty"`
+	UsedById    string     `json:"usedById,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"time"
+)
+
+// Faixas etárias dos perfis infantis
+const (
+	AgeBand0To3   = "0-3"
+	AgeBand4To6   = "4-6"
+	AgeBand7To9   = "7-9"
+	AgeBand10To12 = "10-12"
+	AgeBand13To17 = "13-17"
+)
+
+// IsValidAgeBand informa se a faixa etária é uma das faixas conhecidas
+func IsValidAgeBand(band string) bool {
+	switch band {
+	case AgeBand0To3, AgeBand4To6, AgeBand7To9, AgeBand10To12, AgeBand13To17:
+		return true
+	}
+	return false
+}
+
+// ChildAvatars são os avatares ilustrados que podem ser escolhidos para um perfil infantil.
+// O perfil guarda só o identificador; a imagem fica no frontend, sem URLs externas.
+var ChildAvatars = []string{
+	"urso", "coelho", "gato", "cachorro", "leao", "coruja", "raposa", "panda", "dinossauro", "foguete",
+}
+
+// IsValidChildAvatar informa se o avatar é um dos avatares ilustrados de perfis infantis
+func IsValidChildAvatar(avatar string) bool {
+	for _, a := range ChildAvatars {
+		if a == avatar {
+			return true
+		}
+	}
+	return false
+}
+
+// AgeBandFor calcula a faixa etária de quem nasceu em birthDate na data now.
+// Retorna vazio para datas futuras e para maiores de idade.
+func AgeBandFor(birthDate time.Time, now time.Time) string {
+	age := now.Year() - birthDate.Year()
+	if now.Month() < birthDate.Month() || (now.Month() == birthDate.Month() && now.Day() < birthDate.Day()) {
+		age--
+	}
+	switch {
+	case birthDate.After(now) || age < 0:
+		return ""
+	case age <= 3:
+		return AgeBand0To3
+	case age <= 6:
+		return AgeBand4To6
+	case age <= 9:
+		return AgeBand7To9
+	case age <= 12:
+		return AgeBand10To12
+	case age <= 17:
+		return AgeBand13To17
+	}
+	return ""
+}
+
+// ChildProfile representa o perfil de um leitor infantil gerido por uma conta CLIENT.
+// Com data de nascimento, a faixa etária é calculada a partir dela.
+type ChildProfile struct {
+	ID        string     `json:"id"`
+	UserId    string     `json:"userId"`
+	Name      string     `json:"name"`
+	BirthDate *time.Time `json:"birthDate,omitempty"`
+	AgeBand   string     `json:"ageBand,omitempty"`
+	Avatar    string     `json:"avatar,omitempty"`
+	HasPin    bool       `json:"hasPin"`
+	// Controls são os controles parentais aplicados pelo servidor ao que o perfil lê
+	Controls  ParentalControls `json:"controls"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// ParentalControls são as restrições definidas pelos responsáveis para um perfil infantil.
+// Valores nulos significam sem limite.
+type ParentalControls struct {
+	MaxClassificacao    *int     `json:"maxClassificacao"`
+	BlockedCategoriaIds []string `json:"blockedCategoriaIds"`
+	DailyLimitMinutes   *int     `json:"dailyLimitMinutes"`
+}
+
+// Allows informa se o livro respeita a classificação máxima e as categorias bloqueadas
+func (c ParentalControls) Allows(livro *Livro) bool {
+	if c.MaxClassificacao != nil && livro.Classificacao > *c.MaxClassificacao {
+		return false
+	}
+	return livro.CategoriaId == "" || !c.BlocksCategoria(livro.CategoriaId)
+}
+
+// BlocksCategoria informa se a categoria está bloqueada para o perfil
+func (c ParentalControls) BlocksCategoria(categoriaId string) bool {
+	for _, id := range c.BlockedCategoriaIds {
+		if id == categoriaId {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadingTime representa quanto o perfil infantil leu em um dia
+type ReadingTime struct {
+	Day     string `json:"day"`
+	Seconds int    `json:"seconds"`
+}
+
+// ReadingProgress representa até onde o perfil infantil leu um livro
+type ReadingProgress struct {
+	ChildProfileId string    `json:"childProfileId"`
+	LivroId        string    `json:"livroId"`
+	LivroTitulo    string    `json:"livroTitulo"`
+	LivroCapa      string    `json:"livroCapa,omitempty"`
+	Pagina         int       `json:"pagina"`
+	Concluido      bool      `json:"concluido"`
+	StartedAt      time.Time `json:"startedAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+// ChildFavorite representa um livro marcado como favorito pelo perfil infantil
+type ChildFavorite struct {
+	ChildProfileId string    `json:"childProfileId"`
+	LivroId        string    `json:"livroId"`
+	LivroTitulo    string    `json:"livroTitulo"`
+	LivroCapa      string    `json:"livroCapa,omitempty"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
@@ -0,0 +1,21 @@
+package models
+
+// DashboardMetrics representa os dados de métricas do dashboard
+type DashboardMetrics struct {
+	TotalVendas      float64       `json:"totalVendas"`
+	LivrosVendidos   int           `json:"livrosVendidos"`
+	ProdutosVendidos int           `json:"produtosVendidos"`
+	Visualizacoes    int           `json:"visualizacoes"`
+	Cliques          int           `json:"cliques"`
+	GraficoMetricas  []MetricaData `json:"graficoMetricas"`
+}
+
+// MetricaData representa os dados do gráfico de métricas por período
+type MetricaData struct {
+	Data     string  `json:"data"`
+	Vendas   float64 `json:"vendas"`
+	Livros   int     `json:"livros"`
+	Produtos int     `json:"produtos"`
+	Views    int     `json:"views"`
+	Cliques  int     `json:"cliques"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+)
+
+// Espaco representa um espaço (tenant) que agrupa catálogo e membros
+type Espaco struct {
+	ID        string    `json:"id"`
+	Nome      string    `json:"nome"`
+	Exigir2FA bool      `json:"exigir2FA"` // Exige 2FA dos membros ADMIN e EMPLOYEE
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// EspacoMembro representa o vínculo de um usuário com um espaço.
+// Com um papel personalizado, as permissões do papel substituem as da role no espaço.
+type EspacoMembro struct {
+	EspacoId    string    `json:"espacoId"`
+	UserId      string    `json:"userId"`
+	Role        Role      `json:"role"`
+	PapelId     string    `json:"papelId,omitempty"`
+	PapelNome   string    `json:"papelNome,omitempty"`
+	Permissions []string  `json:"-"` // Permissões do papel personalizado, se houver
+	CreatedAt   time.Time `json:"createdAt"`
+	Name        string    `json:"name,omitempty"`
+	Email       string    `json:"email,omitempty"`
+}
+
+// EspacoPapel representa um papel personalizado de um espaço com suas permissões
+type EspacoPapel struct {
+	ID          string    `json:"id"`
+	EspacoId    string    `json:"espacoId"`
+	Nome        string    `json:"nome"`
+	Descricao   string    `json:"descricao,omitempty"`
+	Permissions []string  `json:"permissions"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// EspacoDoUsuario representa um espaço junto com a role do usuário nele
+type EspacoDoUsuario struct {
+	Espaco
+	Role Role `json:"role"`
+}
+
+// ConviteEspaco representa um convite enviado por email para participar de um espaço
+type ConviteEspaco struct {
+	ID          string     `json:"id"`
+	EspacoId    string     `json:"espacoId"`
+	Email       string     `json:"email"`
+	Role        Role       `json:"role"`
+	InvitedById string     `json:"invitedById"`
+	ExpiresAt   time.Time  `json:"expiresAt"`
+	AcceptedAt  *time.Time `json:"acceptedAt,omitempty"`
+	RevokedAt   *time.Time `json:"revokedAt,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
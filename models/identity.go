@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity representa uma conta de provedor externo (login social) vinculada ao usuário
+type UserIdentity struct {
+	ID        string    `json:"id"`
+	UserId    string    `json:"userId"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"-"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// OIDCState representa um fluxo de login social iniciado e ainda não concluído
+type OIDCState struct {
+	Provider     string
+	CodeVerifier string
+	Nonce        string
+	UserId       string // Vazio no login; preenchido ao vincular um provedor à conta
+	ExpiresAt    time.Time
+}
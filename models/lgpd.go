@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// DataDeletionRequest representa um pedido de exclusão dos dados pessoais do titular (LGPD).
+// Os dados só são anonimizados em ScheduledFor; até lá o pedido pode ser cancelado.
+type DataDeletionRequest struct {
+	ID           string     `json:"id"`
+	UserId       string     `json:"userId"`
+	Reason       string     `json:"reason,omitempty"`
+	RequestedAt  time.Time  `json:"requestedAt"`
+	ScheduledFor time.Time  `json:"scheduledFor"`
+	CanceledAt   *time.Time `json:"canceledAt,omitempty"`
+	CompletedAt  *time.Time `json:"completedAt,omitempty"`
+}
+
+// Finalidades de consentimento registradas para o titular
+const (
+	ConsentTerms     = "terms"
+	ConsentPrivacy   = "privacy"
+	ConsentMarketing = "marketing"
+)
+
+// UserConsent é uma decisão do titular sobre uma finalidade: o aceite dos termos de uso e da
+// política de privacidade ou a opção por receber comunicações de marketing. Os registros
+// nunca são alterados; cada nova decisão é um novo registro, e a mais recente é a vigente.
+type UserConsent struct {
+	ID        string    `json:"id"`
+	UserId    string    `json:"userId"`
+	Purpose   string    `json:"purpose"`
+	Granted   bool      `json:"granted"`
+	Version   string    `json:"version,omitempty"`
+	IP        string    `json:"ip,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import (
+	"time"
+)
+
+// Classificações indicativas aceitas, em anos (ClassInd)
+const (
+	ClassificacaoLivre = 0
+	Classificacao10    = 10
+	Classificacao12    = 12
+	Classificacao14    = 14
+	Classificacao16    = 16
+	Classificacao18    = 18
+)
+
+// IsValidClassificacao informa se o valor é uma das classificações indicativas aceitas
+func IsValidClassificacao(classificacao int) bool {
+	switch classificacao {
+	case ClassificacaoLivre, Classificacao10, Classificacao12, Classificacao14, Classificacao16, Classificacao18:
+		return true
+	}
+	return false
+}
+
+// Livro representa um livro do catálogo de um espaço. Classificacao é a classificação
+// indicativa: a idade mínima recomendada, 0 para livre.
+type Livro struct {
+	ID            string    `json:"id"`
+	Titulo        string    `json:"titulo"`
+	Autor         string    `json:"autor,omitempty"`
+	Descricao     string    `json:"descricao,omitempty"`
+	Capa          string    `json:"capa,omitempty"`
+	Arquivo       string    `json:"arquivo,omitempty"`
+	Paginas       []string  `json:"paginas"`
+	Preco         float64   `json:"preco"`
+	CategoriaId   string    `json:"categoriaId,omitempty"`
+	Classificacao int       `json:"classificacao"`
+	Publicado     bool      `json:"publicado"`
+	EspacoId      string    `json:"espacoId"`
+	CreatedAt     time.Time `json:"createdAt"`
+	UpdatedAt     time.Time `json:"updatedAt"`
+}
+
+// Categoria representa uma categoria de livros de um espaço
+type Categoria struct {
+	ID        string    `json:"id"`
+	Nome      string    `json:"nome"`
+	Descricao string    `json:"descricao,omitempty"`
+	EspacoId  string    `json:"espacoId"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
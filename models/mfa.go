@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+)
+
+// UserTOTP representa a configuração de autenticação em dois fatores (TOTP) do usuário
+type UserTOTP struct {
+	UserId    string
+	Secret    string
+	EnabledAt *time.Time
+	LastStep  int64
+}
+
+// IsPrivileged informa se a role tem acesso a catálogo e dados de vendas, sujeita à exigência de 2FA
+func (r Role) IsPrivileged() bool {
+	return r == ADMIN || r == EMPLOYEE
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+)
+
+// Pedido representa uma compra feita pelo usuário na loja
+type Pedido struct {
+	ID          string       `json:"id"`
+	UsuarioId   string       `json:"usuarioId"`
+	Status      string       `json:"status"`
+	ValorTotal  float64      `json:"valorTotal"`
+	DataCriacao time.Time    `json:"dataCriacao"`
+	Itens       []ItemPedido `json:"itens"`
+}
+
+// ItemPedido representa um produto comprado em um pedido
+type ItemPedido struct {
+	ProdutoId  string `json:"produtoId"`
+	Categoria  string `json:"categoria,omitempty"`
+	Quantidade int    `json:"quantidade"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+)
+
+// Session representa um login ativo em um dispositivo.
+// O ID da sessão é o FamilyId dos tokens de atualização emitidos a partir deste login.
+type Session struct {
+	ID         string     `json:"id"`
+	UserId     string     `json:"userId"`
+	Device     string     `json:"device"`
+	UserAgent  string     `json:"userAgent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastSeenAt time.Time  `json:"lastSeenAt"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+	Current    bool       `json:"current"`
+}
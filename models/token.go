@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+)
+
+// RefreshToken representa um token de atualização emitido para o usuário.
+// Tokens rotacionados a partir do mesmo login compartilham o FamilyId.
+type RefreshToken struct {
+	ID           string     `json:"id"`
+	UserId       string     `json:"userId"`
+	FamilyId     string     `json:"familyId"`
+	ExpiresAt    time.Time  `json:"expiresAt"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	ReplacedById string     `json:"replacedById,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Finalidades dos tokens de uso único enviados por email
+const (
+	TokenPasswordReset     = "password_reset"
+	TokenEmailVerification = "email_verification"
+	TokenMagicLink         = "magic_link"
+	TokenEmailChange       = "email_change"
+	TokenEmailChangeUndo   = "email_change_undo"
+)
+
+// UserToken representa um token de uso único enviado ao usuário (redefinição de senha, etc.)
+type UserToken struct {
+	ID        string     `json:"id"`
+	UserId    string     `json:"userId"`
+	Purpose   string     `json:"purpose"`
+	Data      string     `json:"data,omitempty"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -15,38 +15,98 @@ const (
 	AFFILIATE Role = "AFFILIATE"
 )
 
+// IsValid informa se a role é uma das roles conhecidas pelo sistema
+func (r Role) IsValid() bool {
+	switch r {
+	case CLIENT, EMPLOYEE, ADMIN, AFFILIATE:
+		return true
+	}
+	return false
+}
+
 // User representa o modelo de usuário no banco de dados
 type User struct {
-	ID            string    `json:"id"`
-	Email         string    `json:"email"`
-	Password      string    `json:"-"` // Não retornamos a senha nas respostas JSON
-	Name          string    `json:"name"`
-	Role          Role      `json:"role"`
-	ProfileAvatar string    `json:"profileAvatar,omitempty"`
-	CreatedAt     time.Time `json:"createdAt"`
-	UpdatedAt     time.Time `json:"updatedAt"`
+	ID              string     `json:"id"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"` // Não retornamos a senha nas respostas JSON
+	Name            string     `json:"name"`
+	Role            Role       `json:"role"`
+	ProfileAvatar   string     `json:"profileAvatar,omitempty"`
+	EmailVerifiedAt *time.Time `json:"emailVerifiedAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	UpdatedAt       time.Time  `json:"updatedAt"`
+	// Miniaturas do avatar enviado, pelo lado em pixels; vazio quando o avatar não foi enviado pela aplicação
+	AvatarThumbnails map[string]string `json:"avatarThumbnails,omitempty"`
+	// Estado da conta definido pelos administradores
+	SuspendedAt           *time.Time `json:"suspendedAt,omitempty"`
+	SuspendedReason       string     `json:"suspendedReason,omitempty"`
+	DeletedAt             *time.Time `json:"deletedAt,omitempty"`
+	PasswordResetRequired bool       `json:"passwordResetRequired,omitempty"`
+}
+
+// IsActive informa se a conta pode entrar no sistema: não está suspensa nem excluída
+func (u *User) IsActive() bool {
+	return u.SuspendedAt == nil && u.DeletedAt == nil
+}
+
+// Situações da conta aceitas no filtro da listagem administrativa
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+	UserStatusDeleted   = "deleted"
+)
+
+// UserFilter reúne os filtros e a paginação da listagem administrativa de usuários.
+// Sem Status, a listagem traz as contas ativas e suspensas, mas não as excluídas.
+type UserFilter struct {
+	Search      string
+	Role        Role
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	Page        int
+	PageSize    int
+}
+
+// UserStats resume a base de usuários para o painel administrativo
+type UserStats struct {
+	Total         int          `json:"total"`
+	ByRole        map[Role]int `json:"byRole"`
+	Suspended     int          `json:"suspended"`
+	Deleted       int          `json:"deleted"`
+	NewLast30Days int          `json:"newLast30Days"`
+	Unverified    int          `json:"unverified"`
 }
 
 // UserResponse representa a resposta para o cliente após autenticação
 type UserResponse struct {
-	ID            string    `json:"id"`
-	Email         string    `json:"email"`
-	Name          string    `json:"name"`
-	Role          Role      `json:"role"`
-	ProfileAvatar string    `json:"profileAvatar,omitempty"`
-	Token         string    `json:"token"`
+	ID            string `json:"id"`
+	Email         string `json:"email"`
+	Name          string `json:"name"`
+	Role          Role   `json:"role"`
+	ProfileAvatar string `json:"profileAvatar,omitempty"`
+	EmailVerified bool   `json:"emailVerified"`
+	Token         string `json:"token,omitempty"`
+	RefreshToken  string `json:"refreshToken,omitempty"`
+	CSRFToken     string `json:"csrfToken,omitempty"` // Apenas no modo de sessão por cookie
 }
 
 // LoginRequest representa a requisição de login
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
+	// UseCookie entrega os tokens em cookies HttpOnly em vez do corpo da resposta
+	UseCookie bool `json:"useCookie,omitempty"`
 }
 
-// RegisterRequest representa a requisição de registro
+// RegisterRequest representa a requisição de registro.
+// A role não é aceita do cliente: contas que não sejam CLIENT exigem um código de convite.
 type RegisterRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-	Name     string `json:"name"`
-	Role     Role   `json:"role,omitempty"`
+	Email      string `json:"email"`
+	Password   string `json:"password"`
+	Name       string `json:"name"`
+	InviteCode string `json:"inviteCode,omitempty"`
+	UseCookie  bool   `json:"useCookie,omitempty"`
+	// MarketingOptIn registra a opção por receber comunicações de marketing
+	MarketingOptIn bool `json:"marketingOptIn,omitempty"`
 }
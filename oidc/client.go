@@ -0,0 +1,314 @@
+package oidc
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// keysRefreshInterval é o intervalo mínimo entre buscas do JWKS do provedor
+const keysRefreshInterval = 5 * time.Minute
+
+// discoveryDocument contém os campos usados do documento .well-known/openid-configuration
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// IDTokenClaims representa os claims do ID token usados no login
+type IDTokenClaims struct {
+	Email           string   `json:"email"`
+	EmailVerified   flexBool `json:"email_verified"`
+	Name            string   `json:"name"`
+	Nonce           string   `json:"nonce"`
+	AuthorizedParty string   `json:"azp,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// flexBool aceita booleanos enviados como true ou "true", como faz a Apple em email_verified
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	*b = flexBool(strings.Trim(string(data), `"`) == "true")
+	return nil
+}
+
+// Client executa o fluxo authorization code com PKCE contra um provedor OpenID Connect
+type Client struct {
+	Provider   *configs.OIDCProvider
+	HTTPClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *discoveryDocument
+	keys          map[string]crypto.PublicKey
+	keysFetchedAt time.Time
+}
+
+// NewClient cria um cliente para o provedor configurado
+func NewClient(provider *configs.OIDCProvider) *Client {
+	return &Client{
+		Provider:   provider,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GeneratePKCE gera o code_verifier e o code_challenge S256 (RFC 7636)
+func GeneratePKCE() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	verifier := base64.RawURLEncoding.EncodeToString(buf)
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+// AuthCodeURL monta a URL de autorização para onde o usuário deve ser redirecionado
+func (c *Client) AuthCodeURL(ctx context.Context, state string, nonce string, codeChallenge string) (string, error) {
+	doc, err := c.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", c.Provider.ClientID)
+	params.Set("redirect_uri", c.Provider.RedirectURL)
+	params.Set("scope", strings.Join(c.Provider.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange troca o código de autorização pelo ID token e o valida, incluindo o nonce
+func (c *Client) Exchange(ctx context.Context, code string, codeVerifier string, nonce string) (*IDTokenClaims, error) {
+	doc, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", c.Provider.RedirectURL)
+	form.Set("client_id", c.Provider.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if c.Provider.ClientSecret != "" {
+		form.Set("client_secret", c.Provider.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := c.doJSON(req, &tokenResponse); err != nil {
+		return nil, err
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("provedor não retornou id_token")
+	}
+
+	return c.VerifyIDToken(ctx, tokenResponse.IDToken, nonce)
+}
+
+// VerifyIDToken valida assinatura (JWKS do provedor), emissor, audiência, expiração e nonce do ID token
+func (c *Client) VerifyIDToken(ctx context.Context, rawIDToken string, nonce string) (*IDTokenClaims, error) {
+	doc, err := c.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := &IDTokenClaims{}
+	_, err = jwt.ParseWithClaims(rawIDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return c.publicKey(ctx, doc.JWKSURI, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+		jwt.WithIssuer(doc.Issuer),
+		jwt.WithAudience(c.Provider.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, errors.New("nonce do id_token inválido")
+	}
+	if claims.AuthorizedParty != "" && claims.AuthorizedParty != c.Provider.ClientID {
+		return nil, errors.New("azp do id_token inválido")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id_token sem sub")
+	}
+
+	return claims, nil
+}
+
+// discover busca e guarda o documento de descoberta do provedor
+func (c *Client) discover(ctx context.Context) (*discoveryDocument, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.discovery != nil {
+		return c.discovery, nil
+	}
+
+	issuer := strings.TrimRight(c.Provider.IssuerURL, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc discoveryDocument
+	if err := c.doJSON(req, &doc); err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(doc.Issuer, "/") != issuer {
+		return nil, fmt.Errorf("issuer da descoberta (%s) difere do configurado (%s)", doc.Issuer, issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("documento de descoberta incompleto")
+	}
+
+	c.discovery = &doc
+	return c.discovery, nil
+}
+
+// publicKey retorna a chave do kid informado, buscando o JWKS de novo quando o kid é desconhecido
+func (c *Client) publicKey(ctx context.Context, jwksURI string, kid string) (crypto.PublicKey, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.keys[kid]; ok {
+		return key, nil
+	}
+	if time.Since(c.keysFetchedAt) < keysRefreshInterval && c.keys != nil {
+		return nil, fmt.Errorf("chave desconhecida no id_token: %q", kid)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, jwksURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := c.doJSON(req, &jwks); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]crypto.PublicKey{}
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	c.keys = keys
+	c.keysFetchedAt = time.Now()
+
+	key, ok := c.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("chave desconhecida no id_token: %q", kid)
+	}
+	return key, nil
+}
+
+// doJSON executa a requisição e decodifica a resposta JSON, tratando status de erro
+func (c *Client) doJSON(req *http.Request, out interface{}) error {
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("provedor OIDC respondeu %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(out)
+}
+
+// jsonWebKey representa uma chave pública RSA ou EC (P-256) publicada pelo provedor
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// publicKey converte a JWK em chave pública
+func (k jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("curva não suportada: %s", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("ponto fora da curva")
+		}
+		return key, nil
+	}
+	return nil, fmt.Errorf("tipo de chave não suportado: %s", k.Kty)
+}
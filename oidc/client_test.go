@@ -0,0 +1,288 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/oidc/oidctest"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID    = "maiscrianca"
+	testRedirectURL = "http://localhost:3000/login/callback"
+	testEmail       = "responsavel@example.com"
+)
+
+// newMockProvider sobe o provedor de scripts/mock-oidc num servidor de teste e cria o cliente para ele
+func newMockProvider(t *testing.T) (*oidctest.Provider, *Client) {
+	t.Helper()
+	provider, err := oidctest.NewProvider("", testClientID, testEmail)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(provider.Handler())
+	t.Cleanup(server.Close)
+	provider.Issuer = server.URL
+
+	client := NewClient(&configs.OIDCProvider{
+		Name:        "mock",
+		IssuerURL:   server.URL,
+		ClientID:    testClientID,
+		RedirectURL: testRedirectURL,
+		Scopes:      []string{"openid", "email", "profile"},
+	})
+	return provider, client
+}
+
+// authorize segue a URL de autorização como o navegador e retorna o code e o state do redirecionamento
+func authorize(t *testing.T, authorizationURL string) (string, string) {
+	t.Helper()
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := noRedirect.Get(authorizationURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("autorização respondeu %d", resp.StatusCode)
+	}
+
+	location, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := location.Scheme + "://" + location.Host + location.Path; got != testRedirectURL {
+		t.Fatalf("redirecionado para %s, esperado %s", got, testRedirectURL)
+	}
+	return location.Query().Get("code"), location.Query().Get("state")
+}
+
+func TestExchangeWithMockProvider(t *testing.T) {
+	tests := []struct {
+		name       string
+		loginHint  string
+		verifier   func(verifier string) string
+		nonce      func(nonce string) string
+		wantEmail  string
+		wantFailed bool
+	}{
+		{name: "fluxo completo", wantEmail: testEmail},
+		{name: "login_hint", loginHint: "outro@example.com", wantEmail: "outro@example.com"},
+		{name: "code_verifier errado", verifier: func(string) string { return "outro-verifier" }, wantFailed: true},
+		{name: "nonce de outro fluxo", nonce: func(string) string { return "outro-nonce" }, wantFailed: true},
+		{name: "nonce vazio", nonce: func(string) string { return "" }, wantFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, client := newMockProvider(t)
+			ctx := context.Background()
+
+			verifier, challenge, err := GeneratePKCE()
+			if err != nil {
+				t.Fatal(err)
+			}
+			authorizationURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", challenge)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.loginHint != "" {
+				authorizationURL += "&login_hint=" + url.QueryEscape(tt.loginHint)
+			}
+
+			code, state := authorize(t, authorizationURL)
+			if state != "state-1" {
+				t.Fatalf("state %q, esperado state-1", state)
+			}
+
+			nonce := "nonce-1"
+			if tt.verifier != nil {
+				verifier = tt.verifier(verifier)
+			}
+			if tt.nonce != nil {
+				nonce = tt.nonce(nonce)
+			}
+
+			claims, err := client.Exchange(ctx, code, verifier, nonce)
+			if tt.wantFailed {
+				if err == nil {
+					t.Fatal("Exchange aceitou o login")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if claims.Email != tt.wantEmail || !bool(claims.EmailVerified) || claims.Subject != "mock|"+tt.wantEmail {
+				t.Errorf("claims inesperados: email=%q verificado=%v sub=%q", claims.Email, claims.EmailVerified, claims.Subject)
+			}
+		})
+	}
+}
+
+func TestExchangeCodeIsSingleUse(t *testing.T) {
+	_, client := newMockProvider(t)
+	ctx := context.Background()
+
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorizationURL, err := client.AuthCodeURL(ctx, "state-1", "nonce-1", challenge)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := authorize(t, authorizationURL)
+
+	if _, err := client.Exchange(ctx, code, verifier, "nonce-1"); err != nil {
+		t.Fatalf("primeira troca: %v", err)
+	}
+	if _, err := client.Exchange(ctx, code, verifier, "nonce-1"); err == nil {
+		t.Fatal("o mesmo code foi aceito duas vezes")
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	provider, client := newMockProvider(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// sign assina com a chave do provedor; signWith permite outro método, chave ou kid
+	sign := func(claims jwt.MapClaims) string {
+		token, err := provider.SignIDToken(claims)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	signWith := func(method jwt.SigningMethod, key interface{}, kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	claims := func(change func(jwt.MapClaims)) jwt.MapClaims {
+		c := provider.IDTokenClaims(testEmail, "nonce-1")
+		if change != nil {
+			change(c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+		valid bool
+	}{
+		{"válido", sign(claims(nil)), "nonce-1", true},
+		{"audiência em lista", sign(claims(func(c jwt.MapClaims) { c["aud"] = []string{testClientID, "outro"} })), "nonce-1", true},
+		{"azp do próprio cliente", sign(claims(func(c jwt.MapClaims) { c["azp"] = testClientID })), "nonce-1", true},
+		{"expirado dentro da tolerância", sign(claims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-30 * time.Second).Unix() })), "nonce-1", true},
+		{"expirado", sign(claims(func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-2 * time.Minute).Unix() })), "nonce-1", false},
+		{"sem exp", sign(claims(func(c jwt.MapClaims) { delete(c, "exp") })), "nonce-1", false},
+		{"outro emissor", sign(claims(func(c jwt.MapClaims) { c["iss"] = "https://outro.example.com" })), "nonce-1", false},
+		{"outra audiência", sign(claims(func(c jwt.MapClaims) { c["aud"] = "outro-cliente" })), "nonce-1", false},
+		{"azp de outro cliente", sign(claims(func(c jwt.MapClaims) { c["azp"] = "outro-cliente" })), "nonce-1", false},
+		{"sem sub", sign(claims(func(c jwt.MapClaims) { delete(c, "sub") })), "nonce-1", false},
+		{"nonce diferente", sign(claims(nil)), "nonce-2", false},
+		{"nonce esperado vazio", sign(claims(func(c jwt.MapClaims) { c["nonce"] = "" })), "", false},
+		{"assinado com outra chave", signWith(jwt.SigningMethodRS256, otherKey, oidctest.KeyID, claims(nil)), "nonce-1", false},
+		{"kid desconhecido", signWith(jwt.SigningMethodRS256, provider.Key, "outro", claims(nil)), "nonce-1", false},
+		{"HS256 fora dos algoritmos aceitos", signWith(jwt.SigningMethodHS256, []byte("segredo"), oidctest.KeyID, claims(nil)), "nonce-1", false},
+		{"RS512 fora dos algoritmos aceitos", signWith(jwt.SigningMethodRS512, provider.Key, oidctest.KeyID, claims(nil)), "nonce-1", false},
+		{"token malformado", "não.é.jwt", "nonce-1", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := client.VerifyIDToken(context.Background(), tt.token, tt.nonce)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("id_token recusado: %v", err)
+				}
+				if got.Email != testEmail {
+					t.Errorf("email %q, esperado %s", got.Email, testEmail)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("id_token aceito")
+			}
+		})
+	}
+}
+
+func TestDiscoverRejectsIssuerMismatch(t *testing.T) {
+	provider, client := newMockProvider(t)
+	provider.Issuer = "https://outro.example.com"
+
+	if _, err := client.AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge"); err == nil {
+		t.Fatal("descoberta aceitou issuer diferente do configurado")
+	}
+}
+
+func TestGeneratePKCE(t *testing.T) {
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(verifier) < 43 || len(verifier) > 128 {
+		t.Errorf("code_verifier com %d caracteres, fora do intervalo da RFC 7636", len(verifier))
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	if want := base64.RawURLEncoding.EncodeToString(sum[:]); challenge != want {
+		t.Errorf("code_challenge %q, esperado %q", challenge, want)
+	}
+
+	other, _, err := GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == verifier {
+		t.Error("GeneratePKCE repetiu o code_verifier")
+	}
+}
+
+func TestFlexBool(t *testing.T) {
+	tests := []struct {
+		json string
+		want bool
+	}{
+		{`true`, true},
+		{`"true"`, true},
+		{`false`, false},
+		{`"false"`, false},
+		{`null`, false},
+	}
+
+	for _, tt := range tests {
+		var got struct {
+			EmailVerified flexBool `json:"email_verified"`
+		}
+		if err := json.Unmarshal([]byte(`{"email_verified":`+tt.json+`}`), &got); err != nil {
+			t.Fatalf("%s: %v", tt.json, err)
+		}
+		if bool(got.EmailVerified) != tt.want {
+			t.Errorf("email_verified %s = %v, esperado %v", tt.json, got.EmailVerified, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package password
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// prefixLength é o tamanho do prefixo do SHA-1 enviado ao provedor; com 5 caracteres hexadecimais
+// cada consulta corresponde a centenas de senhas, e o provedor não descobre qual foi verificada
+const prefixLength = 5
+
+// BreachProvider consulta uma base de senhas vazadas pelo modelo de k-anonimato: recebe apenas
+// o prefixo do SHA-1 da senha, em hexadecimal maiúsculo, e retorna os sufixos conhecidos com ele
+type BreachProvider interface {
+	Range(ctx context.Context, prefix string) ([]string, error)
+}
+
+// IsBreached informa se a senha aparece na base do provedor. Só o prefixo do hash sai da
+// aplicação; a comparação com os sufixos retornados é feita localmente.
+func IsBreached(ctx context.Context, provider BreachProvider, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := provider.Range(ctx, hash[:prefixLength])
+	if err != nil {
+		return false, err
+	}
+	for _, suffix := range suffixes {
+		if strings.EqualFold(suffix, hash[prefixLength:]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LocalList é um provedor com a base carregada em memória, agrupada pelo prefixo do hash
+type LocalList struct {
+	suffixes map[string][]string
+}
+
+// NewLocalList cria uma base local a partir de senhas em claro
+func NewLocalList(passwords []string) *LocalList {
+	list := &LocalList{suffixes: map[string][]string{}}
+	for _, password := range passwords {
+		sum := sha1.Sum([]byte(password))
+		list.add(hex.EncodeToString(sum[:]))
+	}
+	return list
+}
+
+// LoadLocalList lê uma base local de hashes SHA-1, um por linha, no formato das listas
+// públicas de senhas vazadas ("HASH" ou "HASH:ocorrências"). Linhas vazias e iniciadas
+// por # são ignoradas.
+func LoadLocalList(path string) (*LocalList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &LocalList{suffixes: map[string][]string{}}
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, _, _ := strings.Cut(text, ":")
+		if _, err := hex.DecodeString(hash); err != nil || len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("%s:%d: hash SHA-1 inválido", path, line)
+		}
+		list.add(hash)
+	}
+	return list, scanner.Err()
+}
+
+// add inclui o hash na base
+func (l *LocalList) add(hash string) {
+	hash = strings.ToUpper(hash)
+	prefix := hash[:prefixLength]
+	l.suffixes[prefix] = append(l.suffixes[prefix], hash[prefixLength:])
+}
+
+// Range retorna os sufixos da base com o prefixo informado
+func (l *LocalList) Range(ctx context.Context, prefix string) ([]string, error) {
+	return l.suffixes[strings.ToUpper(prefix)], nil
+}
+
+// RangeAPI é um provedor HTTP compatível com a API de intervalos do Have I Been Pwned:
+// GET <URL><prefixo> responde com linhas "SUFIXO:ocorrências"
+type RangeAPI struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewRangeAPI cria o provedor HTTP com um tempo limite curto, já que a consulta acontece
+// durante o cadastro e a troca de senha
+func NewRangeAPI(url string) *RangeAPI {
+	return &RangeAPI{
+		URL:    url,
+		Client: &http.Client{Timeout: 3 * time.Second},
+	}
+}
+
+// Range consulta os sufixos do prefixo na API. O cabeçalho Add-Padding pede respostas de
+// tamanho uniforme; as linhas de preenchimento vêm com zero ocorrências e são descartadas.
+func (a *RangeAPI) Range(ctx context.Context, prefix string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL+prefix, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Add-Padding", "true")
+
+	resp, err := a.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("consulta de senhas vazadas retornou %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var suffixes []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		suffix, count, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		if suffix != "" && count != "0" {
+			suffixes = append(suffixes, suffix)
+		}
+	}
+	return suffixes, scanner.Err()
+}
@@ -0,0 +1,153 @@
+package password
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sha1Hex retorna o SHA-1 da senha em hexadecimal maiúsculo, como nas listas de senhas vazadas
+func sha1Hex(password string) string {
+	sum := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestLoadLocalList(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		breached []string
+		clean    []string
+		wantErr  bool
+	}{
+		{
+			name:     "hashes com e sem ocorrências",
+			content:  "# senhas vazadas\n\n" + sha1Hex("senha123") + ":42\n" + strings.ToLower(sha1Hex("brasil2014")) + "\n",
+			breached: []string{"senha123", "brasil2014"},
+			clean:    []string{"correct horse battery staple"},
+		},
+		{
+			name:    "arquivo vazio",
+			content: "",
+			clean:   []string{"senha123"},
+		},
+		{
+			name:    "hash curto",
+			content: sha1Hex("senha123")[:39] + "\n",
+			wantErr: true,
+		},
+		{
+			name:    "hash não hexadecimal",
+			content: "Z" + sha1Hex("senha123")[1:] + ":1\n",
+			wantErr: true,
+		},
+		{
+			name:    "senha em claro",
+			content: "senha123\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "vazadas.txt")
+			if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			list, err := LoadLocalList(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadLocalList aceitou a lista inválida")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadLocalList: %v", err)
+			}
+			assertBreached(t, list, tt.breached, true)
+			assertBreached(t, list, tt.clean, false)
+		})
+	}
+
+	if _, err := LoadLocalList(filepath.Join(t.TempDir(), "inexistente.txt")); err == nil {
+		t.Error("LoadLocalList aceitou um arquivo inexistente")
+	}
+}
+
+func TestNewLocalList(t *testing.T) {
+	list := NewLocalList(strings.Fields(commonList))
+	assertBreached(t, list, []string{"123456", "senha", "password"}, true)
+	assertBreached(t, list, []string{"correct horse battery staple", "Senha"}, false)
+}
+
+func TestRangeAPI(t *testing.T) {
+	hash := sha1Hex("senha123")
+	padded := sha1Hex("brasil2014")
+
+	var gotPath, gotPadding string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotPadding = r.URL.Path, r.Header.Get("Add-Padding")
+		if strings.HasSuffix(r.URL.Path, "/FFFFF") {
+			http.Error(w, "limite de requisições", http.StatusTooManyRequests)
+			return
+		}
+		// O mesmo prefixo responde com o sufixo vazado, uma linha de preenchimento e uma com CRLF
+		fmt.Fprintf(w, "%s:3\r\n%s:0\r\n\r\n", hash[prefixLength:], padded[prefixLength:])
+	}))
+	defer server.Close()
+
+	api := NewRangeAPI(server.URL + "/range/")
+	suffixes, err := api.Range(context.Background(), hash[:prefixLength])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotPath != "/range/"+hash[:prefixLength] || gotPadding != "true" {
+		t.Errorf("requisição para %s com Add-Padding %q", gotPath, gotPadding)
+	}
+	if len(suffixes) != 1 || suffixes[0] != hash[prefixLength:] {
+		t.Errorf("sufixos %v, esperado só %s", suffixes, hash[prefixLength:])
+	}
+
+	if _, err := api.Range(context.Background(), "FFFFF"); err == nil {
+		t.Error("Range aceitou uma resposta de erro")
+	}
+
+	tests := []struct {
+		password string
+		want     bool
+	}{
+		{"senha123", true},
+		{"brasil2014", false},
+	}
+	for _, tt := range tests {
+		got, err := IsBreached(context.Background(), api, tt.password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("IsBreached(%q) = %v, esperado %v", tt.password, got, tt.want)
+		}
+	}
+}
+
+// assertBreached verifica IsBreached para cada senha da lista
+func assertBreached(t *testing.T, provider BreachProvider, passwords []string, want bool) {
+	t.Helper()
+	for _, password := range passwords {
+		got, err := IsBreached(context.Background(), provider, password)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("IsBreached(%q) = %v, esperado %v", password, got, want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Package password define a política de senhas usada no cadastro, na redefinição e na troca
+// de senha: tamanho, força estimada no modelo do zxcvbn e, opcionalmente, a consulta a uma
+// base de senhas vazadas por k-anonimato.
+package password
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/WBianchi/maiscrianca/configs"
+)
+
+const (
+	// DefaultMinLength é o tamanho mínimo da senha, em caracteres
+	DefaultMinLength = 8
+	// DefaultMinScore é a pontuação mínima (0 a 4) exigida pela política
+	DefaultMinScore = 3
+	// MaxLength é o tamanho máximo, em bytes: o bcrypt ignora o que passa de 72 bytes
+	MaxLength = 72
+)
+
+var (
+	// ErrTooShort indica uma senha abaixo do tamanho mínimo
+	ErrTooShort = errors.New("a senha deve ter pelo menos 8 caracteres")
+	// ErrTooLong indica uma senha acima do limite do bcrypt
+	ErrTooLong = errors.New("a senha deve ter no máximo 72 bytes")
+	// ErrTooWeak indica uma senha fácil de adivinhar; a política retorna um *WeakError com o motivo
+	ErrTooWeak = errors.New("senha fraca")
+	// ErrBreached indica uma senha presente na base de senhas vazadas
+	ErrBreached = errors.New("esta senha apareceu em vazamentos de dados e não pode ser usada: escolha outra")
+)
+
+// WeakError indica uma senha com pontuação abaixo do mínimo da política
+type WeakError struct {
+	Strength Strength
+}
+
+// Error explica o motivo da recusa com o aviso da avaliação
+func (e *WeakError) Error() string {
+	return "senha fraca: " + e.Strength.Warning + ". Prefira uma frase com palavras pouco comuns"
+}
+
+// Is permite comparar o erro com ErrTooWeak
+func (e *WeakError) Is(target error) bool {
+	return target == ErrTooWeak
+}
+
+// Policy é a política de senhas. Breaches nil desativa a consulta a senhas vazadas.
+type Policy struct {
+	MinLength int
+	MinScore  int
+	Breaches  BreachProvider
+}
+
+// NewPolicy cria a política com o provedor de senhas vazadas definido por PASSWORD_BREACH_CHECK:
+// "local" usa a lista de PASSWORD_BREACH_LIST ou, sem ela, a lista embutida de senhas comuns;
+// "api" consulta PASSWORD_BREACH_API_URL; vazio desativa a consulta
+func NewPolicy(config *configs.Config) (*Policy, error) {
+	policy := &Policy{MinLength: DefaultMinLength, MinScore: DefaultMinScore}
+
+	switch config.PasswordBreachCheck {
+	case "local":
+		if config.PasswordBreachList == "" {
+			policy.Breaches = NewLocalList(strings.Fields(commonList))
+			break
+		}
+		list, err := LoadLocalList(config.PasswordBreachList)
+		if err != nil {
+			return nil, err
+		}
+		policy.Breaches = list
+	case "api":
+		policy.Breaches = NewRangeAPI(config.PasswordBreachAPIURL)
+	}
+	return policy, nil
+}
+
+// Check valida a senha pela política. userInputs são dados do usuário, como nome e email, que
+// não devem servir de base para a senha. Erros da política são ErrTooShort, ErrTooLong,
+// ErrBreached ou um *WeakError; qualquer outro erro vem da consulta ao provedor de senhas vazadas.
+func (p *Policy) Check(ctx context.Context, password string, userInputs ...string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return ErrTooShort
+	}
+	if len(password) > MaxLength {
+		return ErrTooLong
+	}
+
+	if strength := Estimate(password, userInputs...); strength.Score < p.MinScore {
+		return &WeakError{Strength: strength}
+	}
+
+	if p.Breaches != nil {
+		breached, err := IsBreached(ctx, p.Breaches, password)
+		if err != nil {
+			return fmt.Errorf("erro ao consultar senhas vazadas: %w", err)
+		}
+		if breached {
+			return ErrBreached
+		}
+	}
+	return nil
+}
+
+// IsPolicyError informa se o erro de Check é uma recusa da política, e não uma falha do provedor
+func IsPolicyError(err error) bool {
+	return errors.Is(err, ErrTooShort) || errors.Is(err, ErrTooLong) || errors.Is(err, ErrTooWeak) || errors.Is(err, ErrBreached)
+}
@@ -0,0 +1,80 @@
+package password
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingProvider simula uma falha na consulta de senhas vazadas
+type failingProvider struct{}
+
+func (failingProvider) Range(ctx context.Context, prefix string) ([]string, error) {
+	return nil, errors.New("provedor indisponível")
+}
+
+// errAny marca nos casos de teste um erro qualquer, que não vem da política
+var errAny = errors.New("qualquer erro")
+
+func TestPolicyCheck(t *testing.T) {
+	breached := NewLocalList([]string{"girafa-tomate-violino-nuvem"})
+
+	tests := []struct {
+		name       string
+		breaches   BreachProvider
+		password   string
+		userInputs []string
+		wantErr    error
+		policy     bool
+	}{
+		{name: "senha forte", password: "correct horse battery staple"},
+		{name: "senha forte fora da base de vazadas", breaches: breached, password: "correct horse battery staple"},
+		{name: "curta", password: "xK9#mQ2", wantErr: ErrTooShort, policy: true},
+		{name: "curta conta caracteres e não bytes", password: "ção-ção", wantErr: ErrTooShort, policy: true},
+		{name: "acima de 72 bytes", password: strings.Repeat("girafa-", 11), wantErr: ErrTooLong, policy: true},
+		{name: "acentos contam como mais de um byte", password: strings.Repeat("ç", 37), wantErr: ErrTooLong, policy: true},
+		{name: "fraca", password: "password", wantErr: ErrTooWeak, policy: true},
+		{name: "fraca com dados do usuário", password: "mariana.souza82", userInputs: []string{"Mariana Souza", "mariana.souza@example.com"}, wantErr: ErrTooWeak, policy: true},
+		{name: "vazada", breaches: breached, password: "girafa-tomate-violino-nuvem", wantErr: ErrBreached, policy: true},
+		{name: "falha do provedor", breaches: failingProvider{}, password: "correct horse battery staple", wantErr: errAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := &Policy{MinLength: DefaultMinLength, MinScore: DefaultMinScore, Breaches: tt.breaches}
+			err := policy.Check(context.Background(), tt.password, tt.userInputs...)
+			switch {
+			case tt.wantErr == nil:
+				if err != nil {
+					t.Fatalf("Check recusou a senha: %v", err)
+				}
+				return
+			case tt.wantErr == errAny:
+				if err == nil {
+					t.Fatal("Check aceitou a senha")
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Fatalf("Check = %v, esperado %v", err, tt.wantErr)
+			}
+			if IsPolicyError(err) != tt.policy {
+				t.Errorf("IsPolicyError(%v) = %v, esperado %v", err, IsPolicyError(err), tt.policy)
+			}
+		})
+	}
+}
+
+func TestWeakError(t *testing.T) {
+	err := (&Policy{MinLength: DefaultMinLength, MinScore: DefaultMinScore}).Check(context.Background(), "password")
+
+	var weak *WeakError
+	if !errors.As(err, &weak) {
+		t.Fatalf("Check = %v, esperado *WeakError", err)
+	}
+	if weak.Strength.Score >= DefaultMinScore || weak.Strength.Warning == "" {
+		t.Errorf("avaliação inesperada: %+v", weak.Strength)
+	}
+	if !strings.Contains(err.Error(), weak.Strength.Warning) {
+		t.Errorf("mensagem %q sem o aviso %q", err.Error(), weak.Strength.Warning)
+	}
+}
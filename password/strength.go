@@ -0,0 +1,398 @@
+package password
+
+import (
+	_ "embed"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Limites de pontuação em número de tentativas, no mesmo modelo do zxcvbn: abaixo de 10^3
+// a senha cai em segundos mesmo com limite de tentativas; acima de 10^10 resiste a ataques
+// offline contra hashes lentos como o bcrypt.
+var scoreThresholds = []float64{1e3 + 5, 1e6 + 5, 1e8 + 5, 1e10 + 5}
+
+const (
+	// minSingleCharGuesses e minMultiCharGuesses são as tentativas mínimas de um trecho reconhecido, para que padrões
+	// muito curtos não pareçam mais fáceis que a força bruta
+	minSingleCharGuesses = 10
+	minMultiCharGuesses  = 50
+	// minGuessesBeforeGrowing penaliza a divisão da senha em muitos trechos
+	minGuessesBeforeGrowing = 10000
+	// minYearSpace é a distância mínima, em anos, considerada para datas próximas do ano atual
+	minYearSpace = 20
+)
+
+//go:embed common.txt
+var commonList string
+
+// commonRanks é a posição de cada senha comum na lista embutida, da mais usada para a menos usada
+var commonRanks = rankList(strings.Fields(commonList))
+
+// sequences são as sequências óbvias procuradas na senha, em ordem crescente
+var sequences = []string{
+	"abcdefghijklmnopqrstuvwxyz",
+	"0123456789",
+	"qwertyuiop",
+	"asdfghjkl",
+	"zxcvbnm",
+}
+
+// l33t desfaz as trocas de letras por símbolos mais comuns
+var l33t = strings.NewReplacer("4", "a", "@", "a", "3", "e", "1", "i", "!", "i", "0", "o", "5", "s", "$", "s", "7", "t")
+
+// Tipos de padrão reconhecidos na senha
+const (
+	patternCommon     = "common"
+	patternUserInput  = "user_input"
+	patternSequence   = "sequence"
+	patternRepeat     = "repeat"
+	patternYear       = "year"
+	patternBruteforce = "bruteforce"
+)
+
+// warnings explica ao usuário por que cada padrão enfraquece a senha
+var warnings = map[string]string{
+	patternCommon:     "esta é uma senha muito usada",
+	patternUserInput:  "evite usar seu nome ou seu email na senha",
+	patternSequence:   "sequências como abc, 123 ou qwerty são fáceis de adivinhar",
+	patternRepeat:     "repetições como aaa ou abcabc são fáceis de adivinhar",
+	patternYear:       "anos e datas são fáceis de adivinhar",
+	patternBruteforce: "use uma senha mais longa",
+}
+
+// Strength é a avaliação da força de uma senha. Score vai de 0 (adivinhada em poucas tentativas)
+// a 4 (muito forte); Warning explica o padrão que mais enfraquece a senha, quando há um.
+type Strength struct {
+	Score   int     `json:"score"`
+	Guesses float64 `json:"guesses"`
+	Warning string  `json:"warning,omitempty"`
+}
+
+// match é um trecho [i, j) da senha reconhecido como padrão, com as tentativas para adivinhá-lo
+type match struct {
+	i, j    int
+	pattern string
+	guesses float64
+}
+
+// Estimate avalia a senha no modelo do zxcvbn: procura senhas comuns, dados do próprio usuário,
+// sequências, repetições e anos, e estima as tentativas da combinação de trechos mais fácil de
+// adivinhar. userInputs são dados do usuário, como nome e email, tratados como palavras conhecidas.
+func Estimate(password string, userInputs ...string) Strength {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return Strength{Score: 0, Guesses: 1, Warning: warnings[patternBruteforce]}
+	}
+
+	guesses, matches := mostGuessable(runes, findMatches(runes, userInputRanks(userInputs), map[string]float64{}))
+
+	strength := Strength{Guesses: guesses}
+	for strength.Score < len(scoreThresholds) && guesses >= scoreThresholds[strength.Score] {
+		strength.Score++
+	}
+
+	// O aviso vem do maior trecho reconhecido, como no zxcvbn
+	var longest *match
+	for k := range matches {
+		if longest == nil || matches[k].j-matches[k].i > longest.j-longest.i {
+			longest = &matches[k]
+		}
+	}
+	if strength.Score < len(scoreThresholds) {
+		strength.Warning = warnings[patternBruteforce]
+		if longest != nil {
+			strength.Warning = warnings[longest.pattern]
+		}
+	}
+	return strength
+}
+
+// mostGuessable escolhe, por programação dinâmica, a divisão da senha em trechos com o menor
+// número de tentativas. Trechos sem padrão reconhecido são adivinhados por força bruta.
+func mostGuessable(runes []rune, matches []match) (float64, []match) {
+	n := len(runes)
+	byEnd := make([][]match, n+1)
+	for _, m := range matches {
+		byEnd[m.j] = append(byEnd[m.j], m)
+	}
+
+	// best[k][j] é o menor produto de tentativas cobrindo runes[:j] com k trechos
+	best := make([][]float64, n+1)
+	back := make([][]match, n+1)
+	for k := range best {
+		best[k] = make([]float64, n+1)
+		back[k] = make([]match, n+1)
+		for j := range best[k] {
+			best[k][j] = math.Inf(1)
+		}
+	}
+	best[0][0] = 1
+
+	for j := 1; j <= n; j++ {
+		for k := 1; k <= j; k++ {
+			for _, m := range byEnd[j] {
+				if g := best[k-1][m.i] * m.guesses; g < best[k][j] {
+					best[k][j], back[k][j] = g, m
+				}
+			}
+			for i := 0; i < j; i++ {
+				m := match{i: i, j: j, pattern: patternBruteforce, guesses: bruteforceGuesses(j - i)}
+				if g := best[k-1][i] * m.guesses; g < best[k][j] {
+					best[k][j], back[k][j] = g, m
+				}
+			}
+		}
+	}
+
+	guesses, bestK := math.Inf(1), 0
+	for k := 1; k <= n; k++ {
+		if math.IsInf(best[k][n], 1) {
+			continue
+		}
+		g := factorial(k)*best[k][n] + math.Pow(minGuessesBeforeGrowing, float64(k-1))
+		if g < guesses {
+			guesses, bestK = g, k
+		}
+	}
+
+	var path []match
+	for k, j := bestK, n; k > 0; k-- {
+		m := back[k][j]
+		if m.pattern != patternBruteforce {
+			path = append(path, m)
+		}
+		j = m.i
+	}
+	return guesses, path
+}
+
+// findMatches procura na senha todos os trechos que seguem algum padrão conhecido.
+// repeated guarda as tentativas já calculadas para os trechos base de repetições.
+func findMatches(runes []rune, userRanks map[string]int, repeated map[string]float64) []match {
+	lower := make([]rune, len(runes))
+	for k, r := range runes {
+		lower[k] = unicode.ToLower(r)
+	}
+	var matches []match
+	matches = append(matches, dictionaryMatches(runes, lower, commonRanks, patternCommon)...)
+	matches = append(matches, dictionaryMatches(runes, lower, userRanks, patternUserInput)...)
+	matches = append(matches, sequenceMatches(lower)...)
+	matches = append(matches, repeatMatches(runes, lower, userRanks, repeated)...)
+	matches = append(matches, yearMatches(lower)...)
+	return matches
+}
+
+// dictionaryMatches procura trechos presentes na lista, também escritos de trás para frente
+// ou com letras trocadas por símbolos (l33t). Cada variação multiplica as tentativas.
+func dictionaryMatches(runes []rune, lower []rune, ranks map[string]int, pattern string) []match {
+	var matches []match
+	for i := 0; i < len(lower); i++ {
+		for j := i + 3; j <= len(lower); j++ {
+			word := string(lower[i:j])
+			variations := uppercaseVariations(runes[i:j])
+
+			rank, ok := ranks[word]
+			if !ok {
+				if rank, ok = ranks[l33t.Replace(word)]; ok {
+					variations *= 2
+				}
+			}
+			if !ok {
+				if rank, ok = ranks[reverse(word)]; ok {
+					variations *= 2
+				}
+			}
+			if ok {
+				matches = append(matches, match{i: i, j: j, pattern: pattern, guesses: minGuesses(float64(rank)*variations, j-i)})
+			}
+		}
+	}
+	return matches
+}
+
+// sequenceMatches procura trechos de 3 ou mais caracteres seguidos de uma sequência óbvia,
+// em ordem crescente ou decrescente
+func sequenceMatches(lower []rune) []match {
+	var matches []match
+	for _, seq := range sequences {
+		for i := 0; i < len(lower)-2; {
+			j, delta := i+1, 0
+			for j < len(lower) {
+				prev, cur := strings.IndexRune(seq, lower[j-1]), strings.IndexRune(seq, lower[j])
+				d := cur - prev
+				if prev < 0 || cur < 0 || (d != 1 && d != -1) || (delta != 0 && d != delta) {
+					break
+				}
+				delta = d
+				j++
+			}
+			if j-i >= 3 {
+				base := float64(len(seq))
+				if strings.ContainsRune("az019q", lower[i]) {
+					base = 4
+				}
+				guesses := base * float64(j-i)
+				if delta < 0 {
+					guesses *= 2
+				}
+				matches = append(matches, match{i: i, j: j, pattern: patternSequence, guesses: minGuesses(guesses, j-i)})
+				i = j - 1
+			} else {
+				i++
+			}
+		}
+	}
+	return matches
+}
+
+// repeatMatches procura um mesmo trecho repetido seguidamente, como "aaa" ou "abcabc".
+// As tentativas são as do trecho base multiplicadas pelo número de repetições.
+func repeatMatches(runes []rune, lower []rune, userRanks map[string]int, repeated map[string]float64) []match {
+	var matches []match
+	for i := 0; i < len(lower); i++ {
+		for size := 1; i+2*size <= len(lower); size++ {
+			base := lower[i : i+size]
+			count := 1
+			for i+(count+1)*size <= len(lower) && string(lower[i+count*size:i+(count+1)*size]) == string(base) {
+				count++
+			}
+			if count < 2 || (size == 1 && count < 3) {
+				continue
+			}
+			key := string(runes[i : i+size])
+			baseGuesses, ok := repeated[key]
+			if !ok {
+				baseGuesses, _ = mostGuessable(runes[i:i+size], findMatches(runes[i:i+size], userRanks, repeated))
+				repeated[key] = baseGuesses
+			}
+			j := i + count*size
+			matches = append(matches, match{i: i, j: j, pattern: patternRepeat, guesses: minGuesses(baseGuesses*float64(count), j-i)})
+		}
+	}
+	return matches
+}
+
+// yearMatches procura anos entre 1900 e 2099; quanto mais perto do ano atual, mais fácil de adivinhar
+func yearMatches(lower []rune) []match {
+	var matches []match
+	now := time.Now().Year()
+	for i := 0; i+4 <= len(lower); i++ {
+		year := 0
+		for _, r := range lower[i : i+4] {
+			if r < '0' || r > '9' {
+				year = -1
+				break
+			}
+			year = year*10 + int(r-'0')
+		}
+		if year < 1900 || year > 2099 {
+			continue
+		}
+		space := math.Max(math.Abs(float64(year-now)), minYearSpace)
+		matches = append(matches, match{i: i, j: i + 4, pattern: patternYear, guesses: minGuesses(space, 4)})
+	}
+	return matches
+}
+
+// userInputRanks transforma nome, email e demais dados do usuário em palavras conhecidas
+func userInputRanks(inputs []string) map[string]int {
+	var words []string
+	for _, input := range inputs {
+		input = strings.ToLower(input)
+		if at := strings.LastIndex(input, "@"); at >= 0 {
+			words = append(words, input[:at])
+			input = input[:at]
+		}
+		words = append(words, strings.FieldsFunc(input, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})...)
+	}
+
+	var kept []string
+	for _, word := range words {
+		if len([]rune(word)) >= 3 {
+			kept = append(kept, word)
+		}
+	}
+	return rankList(kept)
+}
+
+// rankList numera as palavras a partir de 1, mantendo a primeira posição das repetidas
+func rankList(words []string) map[string]int {
+	ranks := make(map[string]int, len(words))
+	for _, word := range words {
+		if _, ok := ranks[word]; !ok {
+			ranks[word] = len(ranks) + 1
+		}
+	}
+	return ranks
+}
+
+// uppercaseVariations conta as formas de escrever o trecho com as maiúsculas usadas. Só a primeira
+// letra ou todas maiúsculas são as variações mais comuns e apenas dobram as tentativas.
+func uppercaseVariations(runes []rune) float64 {
+	upper, lower := 0, 0
+	for _, r := range runes {
+		switch {
+		case unicode.IsUpper(r):
+			upper++
+		case unicode.IsLower(r):
+			lower++
+		}
+	}
+	if upper == 0 {
+		return 1
+	}
+	if lower == 0 || (upper == 1 && unicode.IsUpper(runes[0])) {
+		return 2
+	}
+
+	variations := 0.0
+	for k := 1; k <= upper && k <= lower; k++ {
+		variations += binomial(upper+lower, k)
+	}
+	return math.Max(variations, 2)
+}
+
+// bruteforceGuesses estima as tentativas de força bruta para um trecho com o tamanho dado
+func bruteforceGuesses(size int) float64 {
+	return minGuesses(math.Pow(10, float64(size)), size)
+}
+
+// minGuesses aplica o mínimo de tentativas de um trecho com o tamanho dado
+func minGuesses(guesses float64, size int) float64 {
+	min := float64(minMultiCharGuesses)
+	if size == 1 {
+		min = minSingleCharGuesses
+	}
+	return math.Max(guesses, min+1)
+}
+
+// reverse inverte a palavra
+func reverse(word string) string {
+	runes := []rune(word)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// factorial calcula n!
+func factorial(n int) float64 {
+	f := 1.0
+	for i := 2; i <= n; i++ {
+		f *= float64(i)
+	}
+	return f
+}
+
+// binomial calcula o número de combinações de n elementos tomados k a k
+func binomial(n int, k int) float64 {
+	b := 1.0
+	for i := 1; i <= k; i++ {
+		b = b * float64(n-k+i) / float64(i)
+	}
+	return b
+}
@@ -0,0 +1,57 @@
+package password
+
+import "testing"
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name        string
+		password    string
+		userInputs  []string
+		wantScore   int
+		wantWarning string
+	}{
+		{"vazia", "", nil, 0, patternBruteforce},
+		{"um caractere", "a", nil, 0, patternBruteforce},
+		{"senha comum", "password", nil, 0, patternCommon},
+		{"números em sequência da lista", "12345678", nil, 0, patternCommon},
+		{"senha comum com l33t", "P@ssw0rd", nil, 0, patternCommon},
+		{"sequência do alfabeto", "abcdefghij", nil, 0, patternSequence},
+		{"caractere repetido", "aaaaaaaaaa", nil, 0, patternRepeat},
+		{"bloco repetido", "abcabcabcabc", nil, 0, patternRepeat},
+		{"nome com ano", "mariana2015", nil, 1, patternCommon},
+		{"aleatória curta", "xK9#mQ2$vL", nil, 3, patternBruteforce},
+		{"frase com palavras incomuns", "girafa-tomate-violino-nuvem", nil, 4, ""},
+		{"frase longa", "correct horse battery staple", nil, 4, ""},
+		{"sem dados do usuário", "mariana.souza82", nil, 4, ""},
+		{"com dados do usuário", "mariana.souza82", []string{"Mariana Souza", "mariana.souza@example.com"}, 1, patternUserInput},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Estimate(tt.password, tt.userInputs...)
+			if got.Score != tt.wantScore {
+				t.Errorf("Score = %d (%.3g tentativas), esperado %d", got.Score, got.Guesses, tt.wantScore)
+			}
+			if want := warnings[tt.wantWarning]; got.Warning != want {
+				t.Errorf("Warning = %q, esperado %q", got.Warning, want)
+			}
+		})
+	}
+}
+
+func TestEstimateScoreThresholds(t *testing.T) {
+	// A pontuação só cresce com as tentativas e cada limite separa duas pontuações vizinhas
+	previous := Estimate("a")
+	for _, password := range []string{"password", "mariana2015", "Mariana1990!", "xK9#mQ2$vL", "Tr0ub4dor&3", "correct horse battery staple"} {
+		got := Estimate(password)
+		if got.Guesses < previous.Guesses || got.Score < previous.Score {
+			t.Fatalf("%q: %d (%.3g) abaixo da senha anterior %d (%.3g)", password, got.Score, got.Guesses, previous.Score, previous.Guesses)
+		}
+		for score, threshold := range scoreThresholds {
+			if (got.Guesses >= threshold) != (got.Score > score) {
+				t.Errorf("%q: %.3g tentativas com pontuação %d, limite %.3g", password, got.Guesses, got.Score, threshold)
+			}
+		}
+		previous = got
+	}
+}
@@ -0,0 +1,182 @@
+package policy
+
+import (
+	"sort"
+
+	"github.com/WBianchi/maiscrianca/models"
+)
+
+// Permission identifica uma ação permitida no formato recurso:ação
+type Permission string
+
+// Permissões verificadas pelas rotas da API
+const (
+	LivrosRead      Permission = "livros:read"
+	LivrosWrite     Permission = "livros:write"
+	LivrosDelete    Permission = "livros:delete"
+	CategoriasRead  Permission = "categorias:read"
+	CategoriasWrite Permission = "categorias:write"
+	DashboardRead   Permission = "dashboard:read"
+	PedidosRead     Permission = "pedidos:read"
+	EspacosManage   Permission = "espacos:manage"
+	UsersManage     Permission = "users:manage"
+)
+
+// espacoPermissions são as permissões que podem compor um papel personalizado de espaço.
+// users:manage, dashboard:read e pedidos:read valem para a plataforma inteira e ficam com as roles globais.
+var espacoPermissions = []Permission{
+	LivrosRead, LivrosWrite, LivrosDelete, CategoriasRead, CategoriasWrite, EspacosManage,
+}
+
+// apiKeyPermissions são os escopos que podem ser concedidos a chaves de API. Gestão de
+// usuários e de espaços fica de fora para que integrações não administrem contas.
+var apiKeyPermissions = []Permission{
+	LivrosRead, LivrosWrite, LivrosDelete, CategoriasRead, CategoriasWrite, DashboardRead, PedidosRead,
+}
+
+// defaultLandingPage é a página inicial de roles sem política definida
+const defaultLandingPage = "/"
+
+// RolePolicy descreve o que uma role pode acessar: a página inicial após o login,
+// as áreas do frontend liberadas e as permissões na API
+type RolePolicy struct {
+	LandingPage string       `json:"landingPage"`
+	RouteGroups []string     `json:"routeGroups"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// roles é a política de cada role; é a única fonte para redirecionamentos e permissões
+var roles = map[models.Role]RolePolicy{
+	models.ADMIN: {
+		LandingPage: "/dashboard",
+		RouteGroups: []string{"/dashboard"},
+		Permissions: []Permission{
+			LivrosRead, LivrosWrite, LivrosDelete, CategoriasRead, CategoriasWrite,
+			DashboardRead, PedidosRead, EspacosManage, UsersManage,
+		},
+	},
+	models.EMPLOYEE: {
+		LandingPage: "/funcionario/visao-geral",
+		RouteGroups: []string{"/funcionario"},
+		Permissions: []Permission{LivrosRead, LivrosWrite, LivrosDelete, CategoriasRead, CategoriasWrite, DashboardRead, PedidosRead},
+	},
+	models.AFFILIATE: {
+		LandingPage: "/afiliado/visao-geral",
+		RouteGroups: []string{"/afiliado"},
+		Permissions: []Permission{LivrosRead, CategoriasRead},
+	},
+	models.CLIENT: {
+		LandingPage: "/cliente/visao-geral",
+		RouteGroups: []string{"/cliente"},
+		Permissions: []Permission{LivrosRead, CategoriasRead},
+	},
+}
+
+// For retorna a política da role. Roles desconhecidas não têm permissões
+// e são enviadas para a página pública.
+func For(role models.Role) RolePolicy {
+	if p, ok := roles[role]; ok {
+		return p
+	}
+	return RolePolicy{LandingPage: defaultLandingPage, RouteGroups: []string{}, Permissions: []Permission{}}
+}
+
+// LandingPage retorna a página inicial do frontend para a role
+func LandingPage(role models.Role) string {
+	return For(role).LandingPage
+}
+
+// EspacoPermissions retorna as permissões que podem ser atribuídas a papéis personalizados
+func EspacoPermissions() []Permission {
+	return append([]Permission{}, espacoPermissions...)
+}
+
+// IsEspacoPermission informa se a permissão pode ser atribuída a um papel personalizado
+func IsEspacoPermission(permission Permission) bool {
+	return contains(espacoPermissions, permission)
+}
+
+// APIKeyPermissions retorna os escopos que podem ser concedidos a chaves de API
+func APIKeyPermissions() []Permission {
+	return append([]Permission{}, apiKeyPermissions...)
+}
+
+// IsAPIKeyPermission informa se a permissão pode ser concedida a uma chave de API
+func IsAPIKeyPermission(permission Permission) bool {
+	return contains(apiKeyPermissions, permission)
+}
+
+// contains informa se a lista inclui a permissão
+func contains(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// Set é um conjunto de permissões
+type Set map[Permission]bool
+
+// NewSet cria um conjunto com as permissões informadas
+func NewSet(permissions ...Permission) Set {
+	set := Set{}
+	set.Add(permissions...)
+	return set
+}
+
+// Add inclui as permissões no conjunto
+func (s Set) Add(permissions ...Permission) {
+	for _, p := range permissions {
+		s[p] = true
+	}
+}
+
+// Has informa se o conjunto contém a permissão
+func (s Set) Has(permission Permission) bool {
+	return s[permission]
+}
+
+// Intersect retorna as permissões presentes nos dois conjuntos
+func (s Set) Intersect(other Set) Set {
+	set := Set{}
+	for p := range s {
+		if other.Has(p) {
+			set.Add(p)
+		}
+	}
+	return set
+}
+
+// List retorna as permissões do conjunto em ordem alfabética
+func (s Set) List() []Permission {
+	permissions := make([]Permission, 0, len(s))
+	for p := range s {
+		permissions = append(permissions, p)
+	}
+	sort.Slice(permissions, func(i, j int) bool { return permissions[i] < permissions[j] })
+	return permissions
+}
+
+// Resolve calcula as permissões efetivas do usuário. Sem espaço (membro nil) valem as da role
+// global. Dentro de um espaço valem as do papel personalizado do membro ou, sem papel, as da
+// role no espaço; a role global só se soma quando é ADMIN, que administra a plataforma inteira.
+func Resolve(userRole models.Role, membro *models.EspacoMembro) Set {
+	if membro == nil {
+		return NewSet(For(userRole).Permissions...)
+	}
+
+	set := Set{}
+	if membro.PapelId != "" {
+		for _, p := range membro.Permissions {
+			set.Add(Permission(p))
+		}
+	} else {
+		set.Add(For(membro.Role).Permissions...)
+	}
+	if userRole == models.ADMIN {
+		set.Add(For(models.ADMIN).Permissions...)
+	}
+	return set
+}
@@ -0,0 +1,176 @@
+package policy
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/WBianchi/maiscrianca/models"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name     string
+		userRole models.Role
+		membro   *models.EspacoMembro
+		want     []Permission
+	}{
+		{
+			name:     "cliente fora de espaço",
+			userRole: models.CLIENT,
+			want:     []Permission{CategoriasRead, LivrosRead},
+		},
+		{
+			name:     "role desconhecida fora de espaço",
+			userRole: models.Role("VISITANTE"),
+			want:     []Permission{},
+		},
+		{
+			name:     "cliente como funcionário do espaço",
+			userRole: models.CLIENT,
+			membro:   &models.EspacoMembro{Role: models.EMPLOYEE},
+			want:     []Permission{CategoriasRead, CategoriasWrite, DashboardRead, LivrosDelete, LivrosRead, LivrosWrite, PedidosRead},
+		},
+		{
+			name:     "funcionário global como cliente do espaço",
+			userRole: models.EMPLOYEE,
+			membro:   &models.EspacoMembro{Role: models.CLIENT},
+			want:     []Permission{CategoriasRead, LivrosRead},
+		},
+		{
+			name:     "papel personalizado substitui a role do espaço",
+			userRole: models.CLIENT,
+			membro: &models.EspacoMembro{
+				Role:        models.EMPLOYEE,
+				PapelId:     "papel-1",
+				Permissions: []string{string(LivrosRead), string(LivrosWrite)},
+			},
+			want: []Permission{LivrosRead, LivrosWrite},
+		},
+		{
+			name:     "papel personalizado sem permissões",
+			userRole: models.CLIENT,
+			membro:   &models.EspacoMembro{Role: models.EMPLOYEE, PapelId: "papel-1"},
+			want:     []Permission{},
+		},
+		{
+			name:     "admin global soma as próprias permissões no espaço",
+			userRole: models.ADMIN,
+			membro: &models.EspacoMembro{
+				Role:        models.CLIENT,
+				PapelId:     "papel-1",
+				Permissions: []string{string(LivrosRead)},
+			},
+			want: For(models.ADMIN).Permissions,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resolve(tt.userRole, tt.membro).List()
+			if want := NewSet(tt.want...).List(); !reflect.DeepEqual(got, want) {
+				t.Errorf("Resolve = %v, esperado %v", got, want)
+			}
+		})
+	}
+}
+
+func TestAssignablePermissions(t *testing.T) {
+	tests := []struct {
+		permission Permission
+		espaco     bool
+		apiKey     bool
+	}{
+		{LivrosRead, true, true},
+		{LivrosWrite, true, true},
+		{LivrosDelete, true, true},
+		{CategoriasRead, true, true},
+		{CategoriasWrite, true, true},
+		{DashboardRead, false, true},
+		{PedidosRead, false, true},
+		{EspacosManage, true, false},
+		{UsersManage, false, false},
+		{Permission("livros:admin"), false, false},
+	}
+
+	for _, tt := range tests {
+		if got := IsEspacoPermission(tt.permission); got != tt.espaco {
+			t.Errorf("IsEspacoPermission(%s) = %v, esperado %v", tt.permission, got, tt.espaco)
+		}
+		if got := IsAPIKeyPermission(tt.permission); got != tt.apiKey {
+			t.Errorf("IsAPIKeyPermission(%s) = %v, esperado %v", tt.permission, got, tt.apiKey)
+		}
+	}
+
+	// As listas retornadas são cópias e não alteram as permissões do pacote
+	EspacoPermissions()[0] = UsersManage
+	APIKeyPermissions()[0] = UsersManage
+	if IsEspacoPermission(UsersManage) || IsAPIKeyPermission(UsersManage) {
+		t.Error("alterar a lista retornada mudou as permissões atribuíveis")
+	}
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		role        models.Role
+		landingPage string
+		routeGroups []string
+	}{
+		{models.ADMIN, "/dashboard", []string{"/dashboard"}},
+		{models.EMPLOYEE, "/funcionario/visao-geral", []string{"/funcionario"}},
+		{models.AFFILIATE, "/afiliado/visao-geral", []string{"/afiliado"}},
+		{models.CLIENT, "/cliente/visao-geral", []string{"/cliente"}},
+		{models.Role("VISITANTE"), "/", []string{}},
+	}
+
+	for _, tt := range tests {
+		p := For(tt.role)
+		if LandingPage(tt.role) != tt.landingPage || p.LandingPage != tt.landingPage {
+			t.Errorf("LandingPage(%s) = %s, esperado %s", tt.role, p.LandingPage, tt.landingPage)
+		}
+		if !reflect.DeepEqual(p.RouteGroups, tt.routeGroups) {
+			t.Errorf("RouteGroups(%s) = %v, esperado %v", tt.role, p.RouteGroups, tt.routeGroups)
+		}
+		if p.Permissions == nil {
+			t.Errorf("Permissions(%s) é nil; o JSON deve ser uma lista", tt.role)
+		}
+	}
+}
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		a     Set
+		b     Set
+		want  []Permission
+		empty bool
+	}{
+		{"interseção parcial", NewSet(LivrosRead, LivrosWrite), NewSet(LivrosWrite, CategoriasRead), []Permission{LivrosWrite}, false},
+		{"sem interseção", NewSet(LivrosRead), NewSet(CategoriasRead), []Permission{}, true},
+		{"conjunto vazio", Set{}, NewSet(LivrosRead), []Permission{}, true},
+		{"conjunto nil", nil, NewSet(LivrosRead), []Permission{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.a.Intersect(tt.b)
+			if !reflect.DeepEqual(got.List(), tt.want) {
+				t.Errorf("Intersect = %v, esperado %v", got.List(), tt.want)
+			}
+			if (len(got) == 0) != tt.empty {
+				t.Errorf("Intersect com %d permissões", len(got))
+			}
+		})
+	}
+
+	set := NewSet(UsersManage, CategoriasRead, LivrosRead, CategoriasRead)
+	if want := []Permission{CategoriasRead, LivrosRead, UsersManage}; !reflect.DeepEqual(set.List(), want) {
+		t.Errorf("List = %v, esperado %v em ordem alfabética sem repetições", set.List(), want)
+	}
+	if !set.Has(UsersManage) || set.Has(DashboardRead) {
+		t.Errorf("Has inconsistente com %v", set.List())
+	}
+	var empty Set
+	if empty.Has(LivrosRead) {
+		t.Error("conjunto nil contém permissão")
+	}
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// apiKeyTouchInterval limita a frequência de atualização de lastUsedAt
+const apiKeyTouchInterval = time.Minute
+
+const apiKeyColumns = `id, name, prefix, "userId", "espacoId", scopes, "expiresAt", "lastUsedAt", "revokedAt", COALESCE("createdById", ''), "createdAt"`
+
+// scanAPIKey lê uma linha selecionada com apiKeyColumns
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*models.APIKey, error) {
+	var key models.APIKey
+	var lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.UserId,
+		&key.EspacoId,
+		pq.Array(&key.Scopes),
+		&key.ExpiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedById,
+		&key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return &key, nil
+}
+
+// CreateAPIKey grava uma nova chave de API com o hash informado
+func CreateAPIKey(key *models.APIKey, keyHash string) error {
+	key.ID = uuid.New().String()
+
+	return db.QueryRow(
+		`INSERT INTO "ApiKey" (id, name, prefix, "keyHash", "userId", "espacoId", scopes, "expiresAt", "createdById", "createdAt")
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NOW())
+		 RETURNING "createdAt"`,
+		key.ID, key.Name, key.Prefix, keyHash, key.UserId, key.EspacoId, pq.Array(key.Scopes), key.ExpiresAt, key.CreatedById,
+	).Scan(&key.CreatedAt)
+}
+
+// GetActiveAPIKeyByHash retorna a chave com o hash informado, se não revogada nem expirada
+func GetActiveAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	row := db.QueryRow(
+		`SELECT `+apiKeyColumns+` FROM "ApiKey"
+		 WHERE "keyHash" = $1 AND "revokedAt" IS NULL AND "expiresAt" > NOW()`,
+		keyHash,
+	)
+
+	key, err := scanAPIKey(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return key, err
+}
+
+// GetAPIKeys retorna todas as chaves de API, das mais recentes para as mais antigas
+func GetAPIKeys() ([]models.APIKey, error) {
+	rows, err := db.Query(`SELECT ` + apiKeyColumns + ` FROM "ApiKey" ORDER BY "createdAt" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey revoga a chave. Retorna ErrNotFound se ela não existir ou já estiver revogada.
+func RevokeAPIKey(id string) error {
+	result, err := db.Exec(`UPDATE "ApiKey" SET "revokedAt" = NOW() WHERE id = $1 AND "revokedAt" IS NULL`, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// TouchAPIKey registra o uso da chave, gravando no máximo uma vez por apiKeyTouchInterval
+func TouchAPIKey(key *models.APIKey) error {
+	if key.LastUsedAt != nil && time.Since(*key.LastUsedAt) < apiKeyTouchInterval {
+		return nil
+	}
+	_, err := db.Exec(`UPDATE "ApiKey" SET "lastUsedAt" = NOW() WHERE id = $1`, key.ID)
+	return err
+}
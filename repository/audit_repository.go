@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+// CreateAuditLog grava um registro no log de auditoria
+func CreateAuditLog(entry *models.AuditLog) error {
+	return insertAuditLog(db, entry)
+}
+
+// queryRower é satisfeito tanto por *sql.DB quanto por *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// insertAuditLog grava o registro de auditoria na conexão ou transação informada
+func insertAuditLog(q queryRower, entry *models.AuditLog) error {
+	entry.ID = uuid.New().String()
+
+	details := entry.Details
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	detailsJSON, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+
+	return q.QueryRow(
+		`INSERT INTO "AuditLog" (id, "actorId", action, "targetUserId", details, ip, "createdAt")
+		 VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), $5, NULLIF($6, ''), NOW())
+		 RETURNING "createdAt"`,
+		entry.ID, entry.ActorId, entry.Action, entry.TargetUserId, detailsJSON, entry.IP,
+	).Scan(&entry.CreatedAt)
+}
+
+// GetAuditLogsByTargetUserId retorna os registros de auditoria sobre o usuário, dos mais recentes aos mais antigos
+func GetAuditLogsByTargetUserId(userId string) ([]models.AuditLog, error) {
+	rows, err := db.Query(
+		`SELECT id, COALESCE("actorId", ''), action, COALESCE("targetUserId", ''), details, COALESCE(ip, ''), "createdAt"
+		 FROM "AuditLog" WHERE "targetUserId" = $1 ORDER BY "createdAt" DESC`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.AuditLog{}
+	for rows.Next() {
+		var entry models.AuditLog
+		var details []byte
+		err := rows.Scan(&entry.ID, &entry.ActorId, &entry.Action, &entry.TargetUserId, &details, &entry.IP, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(details, &entry.Details); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const categoriaColumns = `id, nome, COALESCE(descricao, ''), "espacoId", "createdAt", "updatedAt"`
+
+// scanCategoria lê uma linha de categoria selecionada com categoriaColumns
+func scanCategoria(row interface{ Scan(...interface{}) error }) (*models.Categoria, error) {
+	var categoria models.Categoria
+	err := row.Scan(
+		&categoria.ID,
+		&categoria.Nome,
+		&categoria.Descricao,
+		&categoria.EspacoId,
+		&categoria.CreatedAt,
+		&categoria.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &categoria, nil
+}
+
+// GetCategoriasByEspacoId retorna todas as categorias de um espaço
+func GetCategoriasByEspacoId(espacoId string) ([]models.Categoria, error) {
+	rows, err := db.Query(`SELECT `+categoriaColumns+` FROM "Categoria" WHERE "espacoId" = $1 ORDER BY nome`, espacoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categorias := []models.Categoria{}
+	for rows.Next() {
+		categoria, err := scanCategoria(rows)
+		if err != nil {
+			return nil, err
+		}
+		categorias = append(categorias, *categoria)
+	}
+
+	return categorias, rows.Err()
+}
+
+// GetCategoriaById retorna uma categoria do espaço informado
+func GetCategoriaById(id string, espacoId string) (*models.Categoria, error) {
+	row := db.QueryRow(`SELECT `+categoriaColumns+` FROM "Categoria" WHERE id = $1 AND "espacoId" = $2`, id, espacoId)
+
+	categoria, err := scanCategoria(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return categoria, err
+}
+
+// CreateCategoria insere uma nova categoria e preenche o ID e as datas geradas
+func CreateCategoria(categoria *models.Categoria) error {
+	categoria.ID = uuid.New().String()
+
+	query := `INSERT INTO "Categoria" (id, nome, descricao, "espacoId", "createdAt", "updatedAt")
+	          VALUES ($1, $2, $3, $4, NOW(), NOW())
+	          RETURNING "createdAt", "updatedAt"`
+
+	return db.QueryRow(
+		query,
+		categoria.ID,
+		categoria.Nome,
+		categoria.Descricao,
+		categoria.EspacoId,
+	).Scan(&categoria.CreatedAt, &categoria.UpdatedAt)
+}
+
+// UpdateCategoria atualiza os campos editáveis de uma categoria
+func UpdateCategoria(categoria *models.Categoria) error {
+	query := `UPDATE "Categoria" SET nome = $1, descricao = $2, "updatedAt" = NOW()
+	          WHERE id = $3 AND "espacoId" = $4
+	          RETURNING "createdAt", "updatedAt"`
+
+	err := db.QueryRow(
+		query,
+		categoria.Nome,
+		categoria.Descricao,
+		categoria.ID,
+		categoria.EspacoId,
+	).Scan(&categoria.CreatedAt, &categoria.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// DeleteCategoria exclui uma categoria do espaço informado
+func DeleteCategoria(id string, espacoId string) error {
+	result, err := db.Exec(`DELETE FROM "Categoria" WHERE id = $1 AND "espacoId" = $2`, id, espacoId)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
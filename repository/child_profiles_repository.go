@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const childProfileColumns = `id, "userId", name, "birthDate", COALESCE("ageBand", ''), COALESCE(avatar, ''),
+	"pinHash" IS NOT NULL, "maxClassificacao", "blockedCategoriaIds", "dailyLimitMinutes", "createdAt", "updatedAt"`
+
+// scanChildProfile lê uma linha selecionada com childProfileColumns
+func scanChildProfile(row interface{ Scan(...interface{}) error }) (*models.ChildProfile, error) {
+	var profile models.ChildProfile
+	var birthDate sql.NullTime
+	var maxClassificacao, dailyLimitMinutes sql.NullInt64
+	err := row.Scan(
+		&profile.ID,
+		&profile.UserId,
+		&profile.Name,
+		&birthDate,
+		&profile.AgeBand,
+		&profile.Avatar,
+		&profile.HasPin,
+		&maxClassificacao,
+		pq.Array(&profile.Controls.BlockedCategoriaIds),
+		&dailyLimitMinutes,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	profile.Controls.MaxClassificacao = nullableInt(maxClassificacao)
+	profile.Controls.DailyLimitMinutes = nullableInt(dailyLimitMinutes)
+	if profile.Controls.BlockedCategoriaIds == nil {
+		profile.Controls.BlockedCategoriaIds = []string{}
+	}
+	if birthDate.Valid {
+		profile.BirthDate = &birthDate.Time
+		profile.AgeBand = models.AgeBandFor(birthDate.Time, time.Now())
+	}
+	return &profile, nil
+}
+
+// GetChildProfilesByUserId retorna os perfis infantis da conta na ordem de criação
+func GetChildProfilesByUserId(userId string) ([]models.ChildProfile, error) {
+	rows, err := db.Query(
+		`SELECT `+childProfileColumns+` FROM "ChildProfile" WHERE "userId" = $1 ORDER BY "createdAt"`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := []models.ChildProfile{}
+	for rows.Next() {
+		profile, err := scanChildProfile(rows)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+// GetChildProfile retorna um perfil infantil da conta
+func GetChildProfile(userId string, id string) (*models.ChildProfile, error) {
+	row := db.QueryRow(
+		`SELECT `+childProfileColumns+` FROM "ChildProfile" WHERE "userId" = $1 AND id = $2`,
+		userId, id,
+	)
+
+	profile, err := scanChildProfile(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return profile, err
+}
+
+// CountChildProfiles retorna quantos perfis infantis a conta possui
+func CountChildProfiles(userId string) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM "ChildProfile" WHERE "userId" = $1`, userId).Scan(&count)
+	return count, err
+}
+
+// CreateChildProfile cria um perfil infantil; pinHash vazio cria o perfil sem PIN.
+// Com data de nascimento, a faixa etária não é gravada e passa a ser calculada.
+func CreateChildProfile(profile *models.ChildProfile, pinHash string) error {
+	profile.ID = uuid.New().String()
+
+	ageBand := profile.AgeBand
+	if profile.BirthDate != nil {
+		ageBand = ""
+	}
+
+	err := db.QueryRow(
+		`INSERT INTO "ChildProfile" (id, "userId", name, "birthDate", "ageBand", avatar, "pinHash", "createdAt", "updatedAt")
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, ''), NOW(), NOW())
+		 RETURNING "createdAt", "updatedAt"`,
+		profile.ID, profile.UserId, profile.Name, profile.BirthDate, ageBand, profile.Avatar, pinHash,
+	).Scan(&profile.CreatedAt, &profile.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	profile.HasPin = pinHash != ""
+	profile.Controls.BlockedCategoriaIds = []string{}
+	return nil
+}
+
+// UpdateChildProfile atualiza nome, data de nascimento ou faixa etária e avatar do perfil infantil
+// e preenche o perfil com os demais dados gravados
+func UpdateChildProfile(profile *models.ChildProfile) error {
+	ageBand := profile.AgeBand
+	if profile.BirthDate != nil {
+		ageBand = ""
+	}
+
+	row := db.QueryRow(
+		`UPDATE "ChildProfile" SET name = $1, "birthDate" = $2, "ageBand" = NULLIF($3, ''), avatar = NULLIF($4, ''),
+		 "updatedAt" = NOW()
+		 WHERE "userId" = $5 AND id = $6
+		 RETURNING `+childProfileColumns,
+		profile.Name, profile.BirthDate, ageBand, profile.Avatar, profile.UserId, profile.ID,
+	)
+
+	updated, err := scanChildProfile(row)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	*profile = *updated
+	return nil
+}
+
+// UpdateChildProfileControls grava os controles parentais do perfil infantil
+func UpdateChildProfileControls(userId string, id string, controls models.ParentalControls) error {
+	result, err := db.Exec(
+		`UPDATE "ChildProfile" SET "maxClassificacao" = $1, "blockedCategoriaIds" = $2, "dailyLimitMinutes" = $3,
+		 "updatedAt" = NOW()
+		 WHERE "userId" = $4 AND id = $5`,
+		controls.MaxClassificacao, pq.Array(controls.BlockedCategoriaIds), controls.DailyLimitMinutes, userId, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// SetChildProfilePin define o PIN do perfil infantil; pinHash vazio remove o PIN
+func SetChildProfilePin(userId string, id string, pinHash string) error {
+	result, err := db.Exec(
+		`UPDATE "ChildProfile" SET "pinHash" = NULLIF($1, ''), "updatedAt" = NOW() WHERE "userId" = $2 AND id = $3`,
+		pinHash, userId, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// GetChildProfilePinHash retorna o hash do PIN do perfil infantil, ou vazio se ele não tiver PIN
+func GetChildProfilePinHash(userId string, id string) (string, error) {
+	var pinHash sql.NullString
+	err := db.QueryRow(
+		`SELECT "pinHash" FROM "ChildProfile" WHERE "userId" = $1 AND id = $2`,
+		userId, id,
+	).Scan(&pinHash)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return pinHash.String, err
+}
+
+// DeleteChildProfile remove o perfil infantil com seu progresso de leitura e favoritos
+func DeleteChildProfile(userId string, id string) error {
+	result, err := db.Exec(`DELETE FROM "ChildProfile" WHERE "userId" = $1 AND id = $2`, userId, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// GetParentalPinHash retorna o hash do PIN do responsável, ou vazio se a conta não tiver PIN
+func GetParentalPinHash(userId string) (string, error) {
+	var pinHash sql.NullString
+	err := db.QueryRow(`SELECT "parentalPinHash" FROM "User" WHERE id = $1`, userId).Scan(&pinHash)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return pinHash.String, err
+}
+
+// SetParentalPin define o PIN do responsável; pinHash vazio remove o PIN
+func SetParentalPin(userId string, pinHash string) error {
+	result, err := db.Exec(
+		`UPDATE "User" SET "parentalPinHash" = NULLIF($1, ''), "updatedAt" = NOW() WHERE id = $2`,
+		pinHash, userId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// nullableInt converte um inteiro opcional lido do banco
+func nullableInt(value sql.NullInt64) *int {
+	if !value.Valid {
+		return nil
+	}
+	v := int(value.Int64)
+	return &v
+}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const codigoConviteColumns = `id, role, "createdById", "expiresAt", "usedAt", COALESCE("usedById", ''), "createdAt"`
+
+// scanCodigoConvite lê uma linha de código selecionada com codigoConviteColumns
+func scanCodigoConvite(row interface{ Scan(...interface{}) error }) (*models.CodigoConvite, error) {
+	var codigo models.CodigoConvite
+	var usedAt sql.NullTime
+	err := row.Scan(
+		&codigo.ID,
+		&codigo.Role,
+		&codigo.CreatedById,
+		&codigo.ExpiresAt,
+		&usedAt,
+		&codigo.UsedById,
+		&codigo.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		codigo.UsedAt = &usedAt.Time
+	}
+	return &codigo, nil
+}
+
+// CreateCodigoConvite insere um código de convite guardando apenas o hash
+func CreateCodigoConvite(codigo *models.CodigoConvite, codeHash string) error {
+	codigo.ID = uuid.New().String()
+
+	return db.QueryRow(
+		`INSERT INTO "CodigoConvite" (id, "codeHash", role, "createdById", "expiresAt", "createdAt")
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 RETURNING "createdAt"`,
+		codigo.ID, codeHash, string(codigo.Role), codigo.CreatedById, codigo.ExpiresAt,
+	).Scan(&codigo.CreatedAt)
+}
+
+// GetCodigosConvitePendentes retorna os códigos ainda não usados nem expirados
+func GetCodigosConvitePendentes() ([]models.CodigoConvite, error) {
+	rows, err := db.Query(`SELECT ` + codigoConviteColumns + ` FROM "CodigoConvite"
+		WHERE "usedAt" IS NULL AND "expiresAt" > NOW()
+		ORDER BY "createdAt" DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	codigos := []models.CodigoConvite{}
+	for rows.Next() {
+		codigo, err := scanCodigoConvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		codigos = append(codigos, *codigo)
+	}
+
+	return codigos, rows.Err()
+}
+
+// GetCodigoConviteByHash retorna o código correspondente ao hash
+func GetCodigoConviteByHash(codeHash string) (*models.CodigoConvite, error) {
+	row := db.QueryRow(`SELECT `+codigoConviteColumns+` FROM "CodigoConvite" WHERE "codeHash" = $1`, codeHash)
+
+	codigo, err := scanCodigoConvite(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return codigo, err
+}
+
+// UseCodigoConvite marca o código como usado dentro da transação de cadastro.
+// Retorna ErrNotFound se o código já tiver sido usado ou estiver expirado.
+func UseCodigoConvite(tx *sql.Tx, id string, userId string) error {
+	result, err := tx.Exec(
+		`UPDATE "CodigoConvite" SET "usedAt" = NOW(), "usedById" = $1
+		 WHERE id = $2 AND "usedAt" IS NULL AND "expiresAt" > NOW()`,
+		userId, id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// RevokeCodigoConvite expira imediatamente um código ainda não usado
+func RevokeCodigoConvite(id string) error {
+	result, err := db.Exec(
+		`UPDATE "CodigoConvite" SET "expiresAt" = NOW() WHERE id = $1 AND "usedAt" IS NULL AND "expiresAt" > NOW()`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
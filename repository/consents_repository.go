@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const userConsentColumns = `id, "userId", purpose, granted, COALESCE(version, ''), COALESCE(ip, ''), "createdAt"`
+
+// InsertUserConsents registra as decisões do titular dentro da transação de cadastro
+func InsertUserConsents(tx *sql.Tx, userId string, consents []models.UserConsent) error {
+	for i := range consents {
+		if err := insertUserConsent(tx, userId, &consents[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddUserConsent registra uma nova decisão do titular sobre uma finalidade
+func AddUserConsent(consent *models.UserConsent) error {
+	return insertUserConsent(db, consent.UserId, consent)
+}
+
+// insertUserConsent grava a decisão e preenche ID, UserId e CreatedAt
+func insertUserConsent(q queryRower, userId string, consent *models.UserConsent) error {
+	consent.ID = uuid.New().String()
+	consent.UserId = userId
+	return q.QueryRow(
+		`INSERT INTO "UserConsent" (id, "userId", purpose, granted, version, ip, "createdAt")
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''), NOW())
+		 RETURNING "createdAt"`,
+		consent.ID, userId, consent.Purpose, consent.Granted, consent.Version, consent.IP,
+	).Scan(&consent.CreatedAt)
+}
+
+// GetUserConsents retorna o histórico de consentimentos do usuário, do mais antigo para o mais recente
+func GetUserConsents(userId string) ([]models.UserConsent, error) {
+	rows, err := db.Query(
+		`SELECT `+userConsentColumns+` FROM "UserConsent" WHERE "userId" = $1 ORDER BY "createdAt", id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	consents := []models.UserConsent{}
+	for rows.Next() {
+		var consent models.UserConsent
+		err := rows.Scan(
+			&consent.ID,
+			&consent.UserId,
+			&consent.Purpose,
+			&consent.Granted,
+			&consent.Version,
+			&consent.IP,
+			&consent.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		consents = append(consents, consent)
+	}
+
+	return consents, rows.Err()
+}
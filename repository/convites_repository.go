@@ -0,0 +1,147 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const conviteColumns = `id, "espacoId", email, role, "invitedById", "expiresAt", "acceptedAt", "revokedAt", "createdAt"`
+
+// scanConvite lê uma linha de convite selecionada com conviteColumns
+func scanConvite(row interface{ Scan(...interface{}) error }) (*models.ConviteEspaco, error) {
+	var convite models.ConviteEspaco
+	var acceptedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&convite.ID,
+		&convite.EspacoId,
+		&convite.Email,
+		&convite.Role,
+		&convite.InvitedById,
+		&convite.ExpiresAt,
+		&acceptedAt,
+		&revokedAt,
+		&convite.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if acceptedAt.Valid {
+		convite.AcceptedAt = &acceptedAt.Time
+	}
+	if revokedAt.Valid {
+		convite.RevokedAt = &revokedAt.Time
+	}
+	return &convite, nil
+}
+
+// queryConvites executa uma consulta de convites e lê todas as linhas
+func queryConvites(query string, args ...interface{}) ([]models.ConviteEspaco, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	convites := []models.ConviteEspaco{}
+	for rows.Next() {
+		convite, err := scanConvite(rows)
+		if err != nil {
+			return nil, err
+		}
+		convites = append(convites, *convite)
+	}
+
+	return convites, rows.Err()
+}
+
+// CreateConvite insere um convite guardando apenas o hash do token enviado ao convidado
+func CreateConvite(convite *models.ConviteEspaco, tokenHash string) error {
+	convite.ID = uuid.New().String()
+
+	query := `INSERT INTO "ConviteEspaco" (id, "espacoId", email, role, "tokenHash", "invitedById", "expiresAt", "createdAt")
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+	          RETURNING "createdAt"`
+
+	return db.QueryRow(
+		query,
+		convite.ID,
+		convite.EspacoId,
+		convite.Email,
+		string(convite.Role),
+		tokenHash,
+		convite.InvitedById,
+		convite.ExpiresAt,
+	).Scan(&convite.CreatedAt)
+}
+
+// GetConvitesPendentesByEspacoId retorna os convites ainda não aceitos nem revogados do espaço
+func GetConvitesPendentesByEspacoId(espacoId string) ([]models.ConviteEspaco, error) {
+	return queryConvites(`SELECT `+conviteColumns+` FROM "ConviteEspaco"
+		WHERE "espacoId" = $1 AND "acceptedAt" IS NULL AND "revokedAt" IS NULL
+		ORDER BY "createdAt" DESC`, espacoId)
+}
+
+// GetConvitesPendentesByEmail retorna os convites válidos endereçados ao email informado
+func GetConvitesPendentesByEmail(email string) ([]models.ConviteEspaco, error) {
+	return queryConvites(`SELECT `+conviteColumns+` FROM "ConviteEspaco"
+		WHERE LOWER(email) = LOWER($1) AND "acceptedAt" IS NULL AND "revokedAt" IS NULL AND "expiresAt" > NOW()
+		ORDER BY "createdAt" DESC`, email)
+}
+
+// GetConviteByTokenHash retorna o convite correspondente ao hash do token
+func GetConviteByTokenHash(tokenHash string) (*models.ConviteEspaco, error) {
+	row := db.QueryRow(`SELECT `+conviteColumns+` FROM "ConviteEspaco" WHERE "tokenHash" = $1`, tokenHash)
+
+	convite, err := scanConvite(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return convite, err
+}
+
+// AcceptConvite marca o convite como aceito e vincula o usuário ao espaço com a role do convite
+func AcceptConvite(convite *models.ConviteEspaco, userId string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE "ConviteEspaco" SET "acceptedAt" = NOW()
+		 WHERE id = $1 AND "acceptedAt" IS NULL AND "revokedAt" IS NULL`,
+		convite.ID,
+	)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO "EspacoMembro" ("espacoId", "userId", role, "createdAt") VALUES ($1, $2, $3, NOW())
+		 ON CONFLICT ("espacoId", "userId") DO UPDATE SET role = EXCLUDED.role`,
+		convite.EspacoId, userId, string(convite.Role),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// RevokeConvite revoga um convite pendente do espaço
+func RevokeConvite(id string, espacoId string) error {
+	result, err := db.Exec(
+		`UPDATE "ConviteEspaco" SET "revokedAt" = NOW()
+		 WHERE id = $1 AND "espacoId" = $2 AND "acceptedAt" IS NULL AND "revokedAt" IS NULL`,
+		id, espacoId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
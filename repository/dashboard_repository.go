@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/WBianchi/maiscrianca/models"
+)
+
+// GetDashboardMetrics calcula as métricas de vendas e de acesso da loja, com o gráfico dos
+// últimos 7 dias. Pedidos cancelados não entram nos totais.
+func GetDashboardMetrics() (*models.DashboardMetrics, error) {
+	metrics := &models.DashboardMetrics{GraficoMetricas: []models.MetricaData{}}
+
+	err := db.QueryRow("SELECT COALESCE(SUM(valor_total), 0) FROM pedidos WHERE status != 'cancelado'").Scan(&metrics.TotalVendas)
+	if err != nil {
+		return nil, fmt.Errorf("total de vendas: %w", err)
+	}
+
+	err = db.QueryRow(`
+		SELECT COALESCE(SUM(ip.quantidade), 0)
+		FROM itens_pedido ip
+		JOIN produtos p ON ip.produto_id = p.id
+		JOIN pedidos pe ON ip.pedido_id = pe.id
+		WHERE p.categoria = 'livro' AND pe.status != 'cancelado'
+	`).Scan(&metrics.LivrosVendidos)
+	if err != nil {
+		return nil, fmt.Errorf("livros vendidos: %w", err)
+	}
+
+	err = db.QueryRow(`
+		SELECT COALESCE(SUM(quantidade), 0)
+		FROM itens_pedido ip
+		JOIN pedidos p ON ip.pedido_id = p.id
+		WHERE p.status != 'cancelado'
+	`).Scan(&metrics.ProdutosVendidos)
+	if err != nil {
+		return nil, fmt.Errorf("produtos vendidos: %w", err)
+	}
+
+	err = db.QueryRow("SELECT COALESCE(SUM(visualizacoes), 0), COALESCE(SUM(cliques), 0) FROM metricas_site").
+		Scan(&metrics.Visualizacoes, &metrics.Cliques)
+	if err != nil {
+		return nil, fmt.Errorf("visualizações e cliques: %w", err)
+	}
+
+	rows, err := db.Query(`
+		WITH dias AS (
+			SELECT date(generate_series(current_date - interval '6 days', current_date, '1 day')) AS data
+		)
+		SELECT
+			to_char(d.data, 'DD/MM/YYYY') as data,
+			COALESCE(SUM(p.valor_total), 0) as vendas,
+			COALESCE(SUM(CASE WHEN pr.categoria = 'livro' THEN ip.quantidade ELSE 0 END), 0) as livros,
+			COALESCE(SUM(ip.quantidade), 0) as produtos,
+			COALESCE(SUM(m.visualizacoes), 0) as views,
+			COALESCE(SUM(m.cliques), 0) as cliques
+		FROM
+			dias d
+		LEFT JOIN
+			pedidos p ON date(p.data_criacao) = d.data AND p.status != 'cancelado'
+		LEFT JOIN
+			itens_pedido ip ON p.id = ip.pedido_id
+		LEFT JOIN
+			produtos pr ON ip.produto_id = pr.id
+		LEFT JOIN
+			metricas_site m ON date(m.data) = d.data
+		GROUP BY
+			d.data
+		ORDER BY
+			d.data
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("gráfico: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var metrica models.MetricaData
+		err := rows.Scan(&metrica.Data, &metrica.Vendas, &metrica.Livros, &metrica.Produtos, &metrica.Views, &metrica.Cliques)
+		if err != nil {
+			return nil, fmt.Errorf("gráfico: %w", err)
+		}
+		metrics.GraficoMetricas = append(metrics.GraficoMetricas, metrica)
+	}
+
+	return metrics, rows.Err()
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// espacoMembroColumns seleciona o vínculo junto com o papel personalizado, se houver
+const espacoMembroColumns = `m."espacoId", m."userId", m.role, COALESCE(p.id, ''), COALESCE(p.nome, ''), p.permissions, m."createdAt"`
+
+// espacoMembroFrom é a junção usada com espacoMembroColumns
+const espacoMembroFrom = `"EspacoMembro" m LEFT JOIN "EspacoPapel" p ON p.id = m."papelId"`
+
+// scanEspacoMembro lê uma linha de vínculo selecionada com espacoMembroColumns
+func scanEspacoMembro(row interface{ Scan(...interface{}) error }) (*models.EspacoMembro, error) {
+	var membro models.EspacoMembro
+	err := row.Scan(
+		&membro.EspacoId,
+		&membro.UserId,
+		&membro.Role,
+		&membro.PapelId,
+		&membro.PapelNome,
+		pq.Array(&membro.Permissions),
+		&membro.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &membro, nil
+}
+
+// GetEspacoMembro retorna o vínculo do usuário com o espaço informado
+func GetEspacoMembro(espacoId string, userId string) (*models.EspacoMembro, error) {
+	row := db.QueryRow(
+		`SELECT `+espacoMembroColumns+` FROM `+espacoMembroFrom+` WHERE m."espacoId" = $1 AND m."userId" = $2`,
+		espacoId, userId,
+	)
+
+	membro, err := scanEspacoMembro(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return membro, err
+}
+
+// GetDefaultEspacoMembro retorna o vínculo mais antigo do usuário, usado quando nenhum espaço é informado
+func GetDefaultEspacoMembro(userId string) (*models.EspacoMembro, error) {
+	row := db.QueryRow(
+		`SELECT `+espacoMembroColumns+` FROM `+espacoMembroFrom+` WHERE m."userId" = $1 ORDER BY m."createdAt" LIMIT 1`,
+		userId,
+	)
+
+	membro, err := scanEspacoMembro(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return membro, err
+}
+
+// GetEspacoById retorna um espaço pelo ID
+func GetEspacoById(id string) (*models.Espaco, error) {
+	var espaco models.Espaco
+	err := db.QueryRow(
+		`SELECT id, nome, "exigir2FA", "createdAt", "updatedAt" FROM "Espaco" WHERE id = $1`, id,
+	).Scan(&espaco.ID, &espaco.Nome, &espaco.Exigir2FA, &espaco.CreatedAt, &espaco.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &espaco, nil
+}
+
+// GetEspacosByUserId retorna os espaços aos quais o usuário pertence, com a role em cada um
+func GetEspacosByUserId(userId string) ([]models.EspacoDoUsuario, error) {
+	rows, err := db.Query(`
+		SELECT e.id, e.nome, e."exigir2FA", e."createdAt", e."updatedAt", m.role
+		FROM "Espaco" e
+		JOIN "EspacoMembro" m ON m."espacoId" = e.id
+		WHERE m."userId" = $1
+		ORDER BY m."createdAt"`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	espacos := []models.EspacoDoUsuario{}
+	for rows.Next() {
+		var espaco models.EspacoDoUsuario
+		if err := rows.Scan(&espaco.ID, &espaco.Nome, &espaco.Exigir2FA, &espaco.CreatedAt, &espaco.UpdatedAt, &espaco.Role); err != nil {
+			return nil, err
+		}
+		espacos = append(espacos, espaco)
+	}
+
+	return espacos, rows.Err()
+}
+
+// CreateEspaco cria um espaço e vincula o criador como ADMIN do espaço
+func CreateEspaco(espaco *models.Espaco, ownerId string) error {
+	espaco.ID = uuid.New().String()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(
+		`INSERT INTO "Espaco" (id, nome, "exigir2FA", "createdAt", "updatedAt") VALUES ($1, $2, $3, NOW(), NOW())
+		 RETURNING "createdAt", "updatedAt"`,
+		espaco.ID, espaco.Nome, espaco.Exigir2FA,
+	).Scan(&espaco.CreatedAt, &espaco.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO "EspacoMembro" ("espacoId", "userId", role, "createdAt") VALUES ($1, $2, $3, NOW())`,
+		espaco.ID, ownerId, string(models.ADMIN),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateEspaco atualiza os campos editáveis de um espaço
+func UpdateEspaco(espaco *models.Espaco) error {
+	err := db.QueryRow(
+		`UPDATE "Espaco" SET nome = $1, "exigir2FA" = $2, "updatedAt" = NOW() WHERE id = $3 RETURNING "createdAt", "updatedAt"`,
+		espaco.Nome, espaco.Exigir2FA, espaco.ID,
+	).Scan(&espaco.CreatedAt, &espaco.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// GetMembrosByEspacoId retorna os membros de um espaço com nome e email
+func GetMembrosByEspacoId(espacoId string) ([]models.EspacoMembro, error) {
+	rows, err := db.Query(`
+		SELECT m."espacoId", m."userId", m.role, COALESCE(p.id, ''), COALESCE(p.nome, ''), m."createdAt", u.name, u.email
+		FROM "EspacoMembro" m
+		JOIN "User" u ON u.id = m."userId"
+		LEFT JOIN "EspacoPapel" p ON p.id = m."papelId"
+		WHERE m."espacoId" = $1
+		ORDER BY u.name`, espacoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	membros := []models.EspacoMembro{}
+	for rows.Next() {
+		var membro models.EspacoMembro
+		err := rows.Scan(
+			&membro.EspacoId,
+			&membro.UserId,
+			&membro.Role,
+			&membro.PapelId,
+			&membro.PapelNome,
+			&membro.CreatedAt,
+			&membro.Name,
+			&membro.Email,
+		)
+		if err != nil {
+			return nil, err
+		}
+		membros = append(membros, membro)
+	}
+
+	return membros, rows.Err()
+}
+
+// UpdateEspacoMembroRole altera a role de um membro no espaço
+func UpdateEspacoMembroRole(espacoId string, userId string, role models.Role) error {
+	result, err := db.Exec(
+		`UPDATE "EspacoMembro" SET role = $1 WHERE "espacoId" = $2 AND "userId" = $3`,
+		string(role), espacoId, userId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// RemoveEspacoMembro desvincula um usuário do espaço
+func RemoveEspacoMembro(espacoId string, userId string) error {
+	result, err := db.Exec(`DELETE FROM "EspacoMembro" WHERE "espacoId" = $1 AND "userId" = $2`, espacoId, userId)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// CountEspacoAdmins conta quantos membros têm a role ADMIN no espaço
+func CountEspacoAdmins(espacoId string) (int, error) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM "EspacoMembro" WHERE "espacoId" = $1 AND role = $2`,
+		espacoId, string(models.ADMIN),
+	).Scan(&count)
+	return count, err
+}
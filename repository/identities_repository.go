@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const userIdentityColumns = `id, "userId", provider, subject, email, "createdAt"`
+
+// scanUserIdentity lê uma linha selecionada com userIdentityColumns
+func scanUserIdentity(row interface{ Scan(...interface{}) error }) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := row.Scan(
+		&identity.ID,
+		&identity.UserId,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+// CreateOIDCState guarda o estado de um fluxo de login social iniciado
+func CreateOIDCState(state *models.OIDCState, stateHash string) error {
+	var userId sql.NullString
+	if state.UserId != "" {
+		userId = sql.NullString{String: state.UserId, Valid: true}
+	}
+
+	_, err := db.Exec(
+		`INSERT INTO "OIDCState" ("stateHash", provider, "codeVerifier", nonce, "userId", "expiresAt", "createdAt")
+		 VALUES ($1, $2, $3, $4, $5, $6, NOW())`,
+		stateHash, state.Provider, state.CodeVerifier, state.Nonce, userId, state.ExpiresAt,
+	)
+	return err
+}
+
+// ConsumeOIDCState remove e retorna o estado ainda válido do fluxo, que só pode ser usado uma vez
+func ConsumeOIDCState(stateHash string, provider string) (*models.OIDCState, error) {
+	var state models.OIDCState
+	var userId sql.NullString
+	err := db.QueryRow(
+		`DELETE FROM "OIDCState" WHERE "stateHash" = $1 AND provider = $2 AND "expiresAt" > NOW()
+		 RETURNING provider, "codeVerifier", nonce, "userId", "expiresAt"`,
+		stateHash, provider,
+	).Scan(&state.Provider, &state.CodeVerifier, &state.Nonce, &userId, &state.ExpiresAt)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	state.UserId = userId.String
+	return &state, nil
+}
+
+// GetUserIdentity retorna a conta vinculada ao sub do provedor
+func GetUserIdentity(provider string, subject string) (*models.UserIdentity, error) {
+	row := db.QueryRow(
+		`SELECT `+userIdentityColumns+` FROM "UserIdentity" WHERE provider = $1 AND subject = $2`,
+		provider, subject,
+	)
+
+	identity, err := scanUserIdentity(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return identity, err
+}
+
+// GetUserIdentitiesByUserId retorna os provedores vinculados ao usuário
+func GetUserIdentitiesByUserId(userId string) ([]models.UserIdentity, error) {
+	rows, err := db.Query(
+		`SELECT `+userIdentityColumns+` FROM "UserIdentity" WHERE "userId" = $1 ORDER BY "createdAt"`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		identity, err := scanUserIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, *identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// CreateUserIdentity vincula uma conta de provedor ao usuário
+func CreateUserIdentity(identity *models.UserIdentity) error {
+	return insertUserIdentity(db, identity)
+}
+
+// insertUserIdentity grava o vínculo usando a conexão ou a transação informada
+func insertUserIdentity(q queryRower, identity *models.UserIdentity) error {
+	identity.ID = uuid.New().String()
+
+	return q.QueryRow(
+		`INSERT INTO "UserIdentity" (id, "userId", provider, subject, email, "createdAt")
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 RETURNING "createdAt"`,
+		identity.ID, identity.UserId, identity.Provider, identity.Subject, identity.Email,
+	).Scan(&identity.CreatedAt)
+}
+
+// DeleteUserIdentity desvincula o provedor da conta do usuário
+func DeleteUserIdentity(userId string, provider string) error {
+	result, err := db.Exec(`DELETE FROM "UserIdentity" WHERE "userId" = $1 AND provider = $2`, userId, provider)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// CreateUserWithIdentity cria um usuário CLIENT sem senha, com o email já verificado pelo provedor,
+// e o vincula à conta do provedor, registrando os consentimentos na mesma transação
+func CreateUserWithIdentity(user *models.User, identity *models.UserIdentity, consents []models.UserConsent) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	user.ID = uuid.New().String()
+	row := tx.QueryRow(
+		`INSERT INTO "User" (id, email, password, name, role, "emailVerifiedAt", "createdAt", "updatedAt")
+		 VALUES ($1, $2, '', $3, $4, NOW(), NOW(), NOW())
+		 RETURNING `+userColumns,
+		user.ID, user.Email, user.Name, string(user.Role),
+	)
+	created, err := scanUser(row)
+	if err != nil {
+		return err
+	}
+	*user = *created
+
+	identity.UserId = user.ID
+	if err := insertUserIdentity(tx, identity); err != nil {
+		return err
+	}
+
+	if err := InsertUserConsents(tx, user.ID, consents); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const dataDeletionRequestColumns = `id, "userId", COALESCE(reason, ''), "requestedAt", "scheduledFor", "canceledAt", "completedAt"`
+
+// scanDataDeletionRequest lê uma linha selecionada com dataDeletionRequestColumns
+func scanDataDeletionRequest(row interface{ Scan(...interface{}) error }) (*models.DataDeletionRequest, error) {
+	var request models.DataDeletionRequest
+	var canceledAt, completedAt sql.NullTime
+	err := row.Scan(
+		&request.ID,
+		&request.UserId,
+		&request.Reason,
+		&request.RequestedAt,
+		&request.ScheduledFor,
+		&canceledAt,
+		&completedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if canceledAt.Valid {
+		request.CanceledAt = &canceledAt.Time
+	}
+	if completedAt.Valid {
+		request.CompletedAt = &completedAt.Time
+	}
+	return &request, nil
+}
+
+// CreateDataDeletionRequest registra um pedido de exclusão de dados.
+// Retorna ErrDuplicate se o usuário já tiver um pedido pendente.
+func CreateDataDeletionRequest(request *models.DataDeletionRequest) error {
+	request.ID = uuid.New().String()
+
+	err := db.QueryRow(
+		`INSERT INTO "DataDeletionRequest" (id, "userId", reason, "requestedAt", "scheduledFor")
+		 VALUES ($1, $2, NULLIF($3, ''), NOW(), $4)
+		 RETURNING "requestedAt"`,
+		request.ID, request.UserId, request.Reason, request.ScheduledFor,
+	).Scan(&request.RequestedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// GetPendingDataDeletionRequest retorna o pedido de exclusão ainda não cancelado nem executado do usuário
+func GetPendingDataDeletionRequest(userId string) (*models.DataDeletionRequest, error) {
+	row := db.QueryRow(
+		`SELECT `+dataDeletionRequestColumns+` FROM "DataDeletionRequest"
+		 WHERE "userId" = $1 AND "canceledAt" IS NULL AND "completedAt" IS NULL`,
+		userId,
+	)
+
+	request, err := scanDataDeletionRequest(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return request, err
+}
+
+// CancelDataDeletionRequest cancela o pedido de exclusão pendente do usuário
+func CancelDataDeletionRequest(userId string) error {
+	result, err := db.Exec(
+		`UPDATE "DataDeletionRequest" SET "canceledAt" = NOW()
+		 WHERE "userId" = $1 AND "canceledAt" IS NULL AND "completedAt" IS NULL`,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// GetDueDataDeletionRequests retorna os pedidos pendentes cujo período de carência já terminou
+func GetDueDataDeletionRequests() ([]models.DataDeletionRequest, error) {
+	rows, err := db.Query(
+		`SELECT ` + dataDeletionRequestColumns + ` FROM "DataDeletionRequest"
+		 WHERE "canceledAt" IS NULL AND "completedAt" IS NULL AND "scheduledFor" <= NOW()
+		 ORDER BY "scheduledFor"`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []models.DataDeletionRequest{}
+	for rows.Next() {
+		request, err := scanDataDeletionRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, *request)
+	}
+
+	return requests, rows.Err()
+}
+
+// AnonymizeUser executa o pedido de exclusão: remove os dados pessoais e as credenciais do usuário
+// e conclui o pedido, gravando a auditoria na mesma transação. A linha de "User" é mantida,
+// anonimizada, para que os registros que a lei obriga a guardar continuem referenciando a conta.
+func AnonymizeUser(request *models.DataDeletionRequest, audit *models.AuditLog) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var email string
+	err = tx.QueryRow(`SELECT email FROM "User" WHERE id = $1 FOR UPDATE`, request.UserId).Scan(&email)
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	// Credenciais, vínculos, sessões e perfis infantis (com a leitura registrada) não têm valor a preservar
+	for _, table := range []string{
+		"RecoveryCode", "UserToken", "UserIdentity", "RefreshToken", "Session", "OIDCState", "EspacoMembro", "ApiKey", "ChildProfile",
+	} {
+		if _, err := tx.Exec(`DELETE FROM "`+table+`" WHERE "userId" = $1`, request.UserId); err != nil {
+			return err
+		}
+	}
+
+	// Convites pendentes e contadores de login também guardam o email
+	if _, err := tx.Exec(
+		`DELETE FROM "ConviteEspaco" WHERE LOWER(email) = LOWER($1) AND "acceptedAt" IS NULL`, email,
+	); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM "LoginThrottle" WHERE RIGHT(key, LENGTH($1) + 1) = ':' || LOWER($1)`, email); err != nil {
+		return err
+	}
+
+	// A auditoria é mantida por segurança, sem os emails em claro
+	if _, err := tx.Exec(
+		`UPDATE "AuditLog" SET details = details - 'email' - 'previousEmail'
+		 WHERE "targetUserId" = $1 AND details ?| ARRAY['email', 'previousEmail']`,
+		request.UserId,
+	); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE "User" SET
+			email = 'removido-' || id || '@anonimizado.invalid',
+			name = 'Usuário removido',
+			password = '',
+			"profileAvatar" = NULL,
+			"avatarThumbnails" = NULL,
+			"emailVerifiedAt" = NULL,
+			"totpSecret" = NULL,
+			"totpEnabledAt" = NULL,
+			"parentalPinHash" = NULL,
+			"suspendedReason" = NULL,
+			"passwordResetRequired" = FALSE,
+			"deletedAt" = COALESCE("deletedAt", NOW()),
+			"anonymizedAt" = NOW(),
+			"updatedAt" = NOW()
+		 WHERE id = $1`,
+		request.UserId,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Os consentimentos são a prova do aceite e ficam guardados, sem o IP
+	if _, err := tx.Exec(`UPDATE "UserConsent" SET ip = NULL WHERE "userId" = $1`, request.UserId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		`UPDATE "DataDeletionRequest" SET "completedAt" = NOW(), reason = NULL WHERE id = $1`, request.ID,
+	); err != nil {
+		return err
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const livroColumns = `id, titulo, COALESCE(autor, ''), COALESCE(descricao, ''), COALESCE(capa, ''),
+	COALESCE(arquivo, ''), paginas, preco, COALESCE("categoriaId", ''), classificacao, publicado, "espacoId",
+	"createdAt", "updatedAt"`
+
+// scanLivro lê uma linha de livro selecionada com livroColumns
+func scanLivro(row interface{ Scan(...interface{}) error }) (*models.Livro, error) {
+	var livro models.Livro
+	err := row.Scan(
+		&livro.ID,
+		&livro.Titulo,
+		&livro.Autor,
+		&livro.Descricao,
+		&livro.Capa,
+		&livro.Arquivo,
+		pq.Array(&livro.Paginas),
+		&livro.Preco,
+		&livro.CategoriaId,
+		&livro.Classificacao,
+		&livro.Publicado,
+		&livro.EspacoId,
+		&livro.CreatedAt,
+		&livro.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if livro.Paginas == nil {
+		livro.Paginas = []string{}
+	}
+	return &livro, nil
+}
+
+// GetLivrosByEspacoId retorna todos os livros de um espaço
+func GetLivrosByEspacoId(espacoId string) ([]models.Livro, error) {
+	rows, err := db.Query(`SELECT `+livroColumns+` FROM "Livro" WHERE "espacoId" = $1 ORDER BY titulo`, espacoId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	livros := []models.Livro{}
+	for rows.Next() {
+		livro, err := scanLivro(rows)
+		if err != nil {
+			return nil, err
+		}
+		livros = append(livros, *livro)
+	}
+
+	return livros, rows.Err()
+}
+
+// GetLivroById retorna um livro do espaço informado
+func GetLivroById(id string, espacoId string) (*models.Livro, error) {
+	row := db.QueryRow(`SELECT `+livroColumns+` FROM "Livro" WHERE id = $1 AND "espacoId" = $2`, id, espacoId)
+
+	livro, err := scanLivro(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return livro, err
+}
+
+// CreateLivro insere um novo livro e preenche o ID e as datas geradas
+func CreateLivro(livro *models.Livro) error {
+	livro.ID = uuid.New().String()
+	if livro.Paginas == nil {
+		livro.Paginas = []string{}
+	}
+
+	query := `INSERT INTO "Livro" (id, titulo, autor, descricao, capa, arquivo, paginas, preco,
+	              "categoriaId", classificacao, publicado, "espacoId", "createdAt", "updatedAt")
+	          VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, NOW(), NOW())
+	          RETURNING "createdAt", "updatedAt"`
+
+	return db.QueryRow(
+		query,
+		livro.ID,
+		livro.Titulo,
+		livro.Autor,
+		livro.Descricao,
+		livro.Capa,
+		livro.Arquivo,
+		pq.Array(livro.Paginas),
+		livro.Preco,
+		livro.CategoriaId,
+		livro.Classificacao,
+		livro.Publicado,
+		livro.EspacoId,
+	).Scan(&livro.CreatedAt, &livro.UpdatedAt)
+}
+
+// UpdateLivro atualiza todos os campos editáveis de um livro
+func UpdateLivro(livro *models.Livro) error {
+	if livro.Paginas == nil {
+		livro.Paginas = []string{}
+	}
+
+	query := `UPDATE "Livro" SET titulo = $1, autor = $2, descricao = $3, capa = $4, arquivo = $5,
+	              paginas = $6, preco = $7, "categoriaId" = NULLIF($8, ''), classificacao = $9, publicado = $10,
+	              "updatedAt" = NOW()
+	          WHERE id = $11 AND "espacoId" = $12
+	          RETURNING "createdAt", "updatedAt"`
+
+	err := db.QueryRow(
+		query,
+		livro.Titulo,
+		livro.Autor,
+		livro.Descricao,
+		livro.Capa,
+		livro.Arquivo,
+		pq.Array(livro.Paginas),
+		livro.Preco,
+		livro.CategoriaId,
+		livro.Classificacao,
+		livro.Publicado,
+		livro.ID,
+		livro.EspacoId,
+	).Scan(&livro.CreatedAt, &livro.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	return err
+}
+
+// DeleteLivro exclui um livro do espaço informado
+func DeleteLivro(id string, espacoId string) error {
+	result, err := db.Exec(`DELETE FROM "Livro" WHERE id = $1 AND "espacoId" = $2`, id, espacoId)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// HasLivrosByCategoria informa se existem livros associados à categoria
+func HasLivrosByCategoria(categoriaId string, espacoId string) (bool, error) {
+	var exists bool
+	err := db.QueryRow(
+		`SELECT EXISTS(SELECT 1 FROM "Livro" WHERE "categoriaId" = $1 AND "espacoId" = $2)`,
+		categoriaId, espacoId,
+	).Scan(&exists)
+	return exists, err
+}
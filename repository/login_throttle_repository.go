@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// GetLoginLock retorna o fim do bloqueio mais longo ainda ativo entre as chaves informadas
+func GetLoginLock(keys ...string) (*time.Time, error) {
+	var lockedUntil sql.NullTime
+	err := db.QueryRow(
+		`SELECT MAX("lockedUntil") FROM "LoginThrottle" WHERE key = ANY($1) AND "lockedUntil" > NOW()`,
+		pq.Array(keys),
+	).Scan(&lockedUntil)
+	if err != nil {
+		return nil, err
+	}
+	if !lockedUntil.Valid {
+		return nil, nil
+	}
+	return &lockedUntil.Time, nil
+}
+
+// IncrementLoginFailures soma uma falha à chave e retorna o total de falhas consecutivas.
+// Falhas mais antigas que a janela informada são descartadas antes da contagem.
+func IncrementLoginFailures(key string, window time.Duration) (int, error) {
+	var failures int
+	err := db.QueryRow(
+		`INSERT INTO "LoginThrottle" (key, failures, "lastFailureAt") VALUES ($1, 1, NOW())
+		 ON CONFLICT (key) DO UPDATE SET
+		     failures = CASE
+		         WHEN "LoginThrottle"."lastFailureAt" < NOW() - make_interval(secs => $2) THEN 1
+		         ELSE "LoginThrottle".failures + 1
+		     END,
+		     "lastFailureAt" = NOW()
+		 RETURNING failures`,
+		key, window.Seconds(),
+	).Scan(&failures)
+	return failures, err
+}
+
+// LockLoginKey bloqueia novas tentativas de login para a chave até o instante informado
+func LockLoginKey(key string, until time.Time) error {
+	_, err := db.Exec(`UPDATE "LoginThrottle" SET "lockedUntil" = $1 WHERE key = $2`, until, key)
+	return err
+}
+
+// ClearLoginFailures zera o contador e remove o bloqueio da chave
+func ClearLoginFailures(key string) error {
+	_, err := db.Exec(`DELETE FROM "LoginThrottle" WHERE key = $1`, key)
+	return err
+}
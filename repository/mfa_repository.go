@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+// GetUserTOTP retorna a configuração de TOTP do usuário
+func GetUserTOTP(userId string) (*models.UserTOTP, error) {
+	totp := models.UserTOTP{UserId: userId}
+	var enabledAt sql.NullTime
+	err := db.QueryRow(
+		`SELECT COALESCE("totpSecret", ''), "totpEnabledAt", "totpLastStep" FROM "User" WHERE id = $1`,
+		userId,
+	).Scan(&totp.Secret, &enabledAt, &totp.LastStep)
+
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if enabledAt.Valid {
+		totp.EnabledAt = &enabledAt.Time
+	}
+	return &totp, nil
+}
+
+// SetUserTOTPSecret guarda um novo segredo ainda não confirmado, desativando o 2FA até a confirmação
+func SetUserTOTPSecret(userId string, secret string) error {
+	result, err := db.Exec(
+		`UPDATE "User" SET "totpSecret" = $1, "totpEnabledAt" = NULL, "totpLastStep" = 0, "updatedAt" = NOW() WHERE id = $2`,
+		secret, userId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// EnableUserTOTP ativa o 2FA, registra o step do código de confirmação e substitui os códigos de recuperação
+func EnableUserTOTP(userId string, step int64, recoveryCodeHashes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE "User" SET "totpEnabledAt" = NOW(), "totpLastStep" = $1, "updatedAt" = NOW() WHERE id = $2`,
+		step, userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceRecoveryCodes(tx, userId, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// DisableUserTOTP remove o segredo e os códigos de recuperação do usuário
+func DisableUserTOTP(userId string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE "User" SET "totpSecret" = NULL, "totpEnabledAt" = NULL, "totpLastStep" = 0, "updatedAt" = NOW() WHERE id = $1`,
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "RecoveryCode" WHERE "userId" = $1`, userId); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ReplaceRecoveryCodes descarta os códigos de recuperação atuais e grava os novos
+func ReplaceRecoveryCodes(userId string, recoveryCodeHashes []string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := replaceRecoveryCodes(tx, userId, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// replaceRecoveryCodes substitui os códigos de recuperação dentro da transação
+func replaceRecoveryCodes(tx *sql.Tx, userId string, recoveryCodeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM "RecoveryCode" WHERE "userId" = $1`, userId); err != nil {
+		return err
+	}
+
+	for _, hash := range recoveryCodeHashes {
+		_, err := tx.Exec(
+			`INSERT INTO "RecoveryCode" (id, "userId", "codeHash", "createdAt") VALUES ($1, $2, $3, NOW())`,
+			uuid.New().String(), userId, hash,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UseTOTPStep registra o step de um código aceito.
+// Retorna ErrNotFound se o código (ou um posterior) já tiver sido usado.
+func UseTOTPStep(userId string, step int64) error {
+	result, err := db.Exec(
+		`UPDATE "User" SET "totpLastStep" = $1 WHERE id = $2 AND "totpLastStep" < $1`,
+		step, userId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// UseRecoveryCode consome um código de recuperação ainda não usado
+func UseRecoveryCode(userId string, codeHash string) error {
+	result, err := db.Exec(
+		`UPDATE "RecoveryCode" SET "usedAt" = NOW() WHERE "userId" = $1 AND "codeHash" = $2 AND "usedAt" IS NULL`,
+		userId, codeHash,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// CountUnusedRecoveryCodes conta os códigos de recuperação ainda disponíveis do usuário
+func CountUnusedRecoveryCodes(userId string) (int, error) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM "RecoveryCode" WHERE "userId" = $1 AND "usedAt" IS NULL`,
+		userId,
+	).Scan(&count)
+	return count, err
+}
+
+// RequiresMFAEnrollment informa se o espaço exige 2FA e o usuário ainda não o ativou
+func RequiresMFAEnrollment(espacoId string, userId string) (bool, error) {
+	var required bool
+	err := db.QueryRow(
+		`SELECT e."exigir2FA" AND u."totpEnabledAt" IS NULL
+		 FROM "Espaco" e, "User" u
+		 WHERE e.id = $1 AND u.id = $2`,
+		espacoId, userId,
+	).Scan(&required)
+	if err == sql.ErrNoRows {
+		return false, ErrNotFound
+	}
+	return required, err
+}
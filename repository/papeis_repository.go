@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const espacoPapelColumns = `id, "espacoId", nome, COALESCE(descricao, ''), permissions, "createdAt", "updatedAt"`
+
+// scanEspacoPapel lê uma linha selecionada com espacoPapelColumns
+func scanEspacoPapel(row interface{ Scan(...interface{}) error }) (*models.EspacoPapel, error) {
+	var papel models.EspacoPapel
+	err := row.Scan(
+		&papel.ID,
+		&papel.EspacoId,
+		&papel.Nome,
+		&papel.Descricao,
+		pq.Array(&papel.Permissions),
+		&papel.CreatedAt,
+		&papel.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if papel.Permissions == nil {
+		papel.Permissions = []string{}
+	}
+	return &papel, nil
+}
+
+// GetPapeisByEspacoId retorna os papéis personalizados do espaço ordenados pelo nome
+func GetPapeisByEspacoId(espacoId string) ([]models.EspacoPapel, error) {
+	rows, err := db.Query(
+		`SELECT `+espacoPapelColumns+` FROM "EspacoPapel" WHERE "espacoId" = $1 ORDER BY nome`,
+		espacoId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	papeis := []models.EspacoPapel{}
+	for rows.Next() {
+		papel, err := scanEspacoPapel(rows)
+		if err != nil {
+			return nil, err
+		}
+		papeis = append(papeis, *papel)
+	}
+
+	return papeis, rows.Err()
+}
+
+// GetEspacoPapel retorna um papel personalizado do espaço
+func GetEspacoPapel(espacoId string, id string) (*models.EspacoPapel, error) {
+	row := db.QueryRow(
+		`SELECT `+espacoPapelColumns+` FROM "EspacoPapel" WHERE "espacoId" = $1 AND id = $2`,
+		espacoId, id,
+	)
+
+	papel, err := scanEspacoPapel(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return papel, err
+}
+
+// CreateEspacoPapel cria um papel personalizado.
+// Retorna ErrDuplicate se o espaço já tiver um papel com o mesmo nome.
+func CreateEspacoPapel(papel *models.EspacoPapel) error {
+	papel.ID = uuid.New().String()
+
+	err := db.QueryRow(
+		`INSERT INTO "EspacoPapel" (id, "espacoId", nome, descricao, permissions, "createdAt", "updatedAt")
+		 VALUES ($1, $2, $3, NULLIF($4, ''), $5, NOW(), NOW())
+		 RETURNING "createdAt", "updatedAt"`,
+		papel.ID, papel.EspacoId, papel.Nome, papel.Descricao, pq.Array(papel.Permissions),
+	).Scan(&papel.CreatedAt, &papel.UpdatedAt)
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// UpdateEspacoPapel atualiza nome, descrição e permissões de um papel personalizado.
+// Retorna ErrDuplicate se o novo nome já estiver em uso no espaço.
+func UpdateEspacoPapel(papel *models.EspacoPapel) error {
+	err := db.QueryRow(
+		`UPDATE "EspacoPapel" SET nome = $1, descricao = NULLIF($2, ''), permissions = $3, "updatedAt" = NOW()
+		 WHERE "espacoId" = $4 AND id = $5
+		 RETURNING "createdAt", "updatedAt"`,
+		papel.Nome, papel.Descricao, pq.Array(papel.Permissions), papel.EspacoId, papel.ID,
+	).Scan(&papel.CreatedAt, &papel.UpdatedAt)
+
+	if err == sql.ErrNoRows {
+		return ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return ErrDuplicate
+	}
+	return err
+}
+
+// DeleteEspacoPapel remove um papel personalizado; os membros com ele voltam às permissões da role
+func DeleteEspacoPapel(espacoId string, id string) error {
+	result, err := db.Exec(`DELETE FROM "EspacoPapel" WHERE "espacoId" = $1 AND id = $2`, espacoId, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// SetEspacoMembroPapel atribui um papel personalizado ao membro; papelId vazio remove a atribuição.
+// O papel precisa pertencer ao mesmo espaço do membro.
+func SetEspacoMembroPapel(espacoId string, userId string, papelId string) error {
+	result, err := db.Exec(
+		`UPDATE "EspacoMembro" SET "papelId" = NULLIF($1, '')
+		 WHERE "espacoId" = $2 AND "userId" = $3
+		   AND ($1 = '' OR EXISTS (SELECT 1 FROM "EspacoPapel" WHERE id = $1 AND "espacoId" = $2))`,
+		papelId, espacoId, userId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
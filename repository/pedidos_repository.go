@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+)
+
+// pedidoColumns são as colunas de pedidos e itens lidas por scanPedidos, com o pedido em p,
+// os itens em ip e os produtos em pr
+const pedidoColumns = `p.id, COALESCE(p.usuario_id, ''), p.status, COALESCE(p.valor_total, 0), p.data_criacao,
+	ip.produto_id, COALESCE(pr.categoria, ''), ip.quantidade`
+
+// GetPedidosByUserId retorna os pedidos do usuário, dos mais recentes para os mais antigos,
+// com os itens de cada um
+func GetPedidosByUserId(userId string) ([]models.Pedido, error) {
+	rows, err := db.Query(
+		`SELECT `+pedidoColumns+`
+		 FROM pedidos p
+		 LEFT JOIN itens_pedido ip ON ip.pedido_id = p.id
+		 LEFT JOIN produtos pr ON pr.id = ip.produto_id
+		 WHERE p.usuario_id = $1
+		 ORDER BY p.data_criacao DESC, p.id`,
+		userId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanPedidos(rows)
+}
+
+// ListPedidos retorna uma página dos pedidos da loja, dos mais recentes para os mais antigos,
+// com os itens de cada um, e o total de pedidos
+func ListPedidos(page int, pageSize int) ([]models.Pedido, int, error) {
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pedidos`).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := db.Query(
+		`WITH pagina AS (
+			SELECT * FROM pedidos ORDER BY data_criacao DESC, id LIMIT $1 OFFSET $2
+		 )
+		 SELECT `+pedidoColumns+`
+		 FROM pagina p
+		 LEFT JOIN itens_pedido ip ON ip.pedido_id = p.id
+		 LEFT JOIN produtos pr ON pr.id = ip.produto_id
+		 ORDER BY p.data_criacao DESC, p.id`,
+		pageSize, (page-1)*pageSize,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	pedidos, err := scanPedidos(rows)
+	return pedidos, total, err
+}
+
+// scanPedidos agrupa em pedidos as linhas de pedidoColumns, ordenadas por pedido
+func scanPedidos(rows *sql.Rows) ([]models.Pedido, error) {
+	pedidos := []models.Pedido{}
+	for rows.Next() {
+		var pedido models.Pedido
+		var produtoId sql.NullString
+		var quantidade sql.NullInt64
+		var categoria string
+		err := rows.Scan(
+			&pedido.ID,
+			&pedido.UsuarioId,
+			&pedido.Status,
+			&pedido.ValorTotal,
+			&pedido.DataCriacao,
+			&produtoId,
+			&categoria,
+			&quantidade,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// As linhas de um mesmo pedido chegam seguidas, uma por item
+		if n := len(pedidos); n == 0 || pedidos[n-1].ID != pedido.ID {
+			pedido.Itens = []models.ItemPedido{}
+			pedidos = append(pedidos, pedido)
+		}
+		if produtoId.Valid {
+			last := &pedidos[len(pedidos)-1]
+			last.Itens = append(last.Itens, models.ItemPedido{
+				ProdutoId:  produtoId.String,
+				Categoria:  categoria,
+				Quantidade: int(quantidade.Int64),
+			})
+		}
+	}
+
+	return pedidos, rows.Err()
+}
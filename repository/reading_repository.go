@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/lib/pq"
+)
+
+// GetReadingProgress retorna o progresso de leitura do perfil infantil, dos livros lidos mais recentemente
+func GetReadingProgress(childProfileId string) ([]models.ReadingProgress, error) {
+	rows, err := db.Query(`
+		SELECT r."childProfileId", r."livroId", l.titulo, COALESCE(l.capa, ''), r.pagina, r.concluido,
+		       r."startedAt", r."updatedAt"
+		FROM "ReadingProgress" r
+		JOIN "Livro" l ON l.id = r."livroId"
+		WHERE r."childProfileId" = $1
+		ORDER BY r."updatedAt" DESC`, childProfileId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	progress := []models.ReadingProgress{}
+	for rows.Next() {
+		var p models.ReadingProgress
+		err := rows.Scan(
+			&p.ChildProfileId,
+			&p.LivroId,
+			&p.LivroTitulo,
+			&p.LivroCapa,
+			&p.Pagina,
+			&p.Concluido,
+			&p.StartedAt,
+			&p.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, p)
+	}
+
+	return progress, rows.Err()
+}
+
+// SaveReadingProgress registra a página atual do livro para o perfil infantil.
+// Um livro concluído continua concluído mesmo que a leitura seja retomada.
+func SaveReadingProgress(progress *models.ReadingProgress) error {
+	return db.QueryRow(
+		`INSERT INTO "ReadingProgress" ("childProfileId", "livroId", pagina, concluido, "startedAt", "updatedAt")
+		 VALUES ($1, $2, $3, $4, NOW(), NOW())
+		 ON CONFLICT ("childProfileId", "livroId") DO UPDATE
+		 SET pagina = EXCLUDED.pagina, concluido = "ReadingProgress".concluido OR EXCLUDED.concluido, "updatedAt" = NOW()
+		 RETURNING concluido, "startedAt", "updatedAt"`,
+		progress.ChildProfileId, progress.LivroId, progress.Pagina, progress.Concluido,
+	).Scan(&progress.Concluido, &progress.StartedAt, &progress.UpdatedAt)
+}
+
+// GetChildFavorites retorna os livros favoritos do perfil infantil, dos mais recentes aos mais antigos
+func GetChildFavorites(childProfileId string) ([]models.ChildFavorite, error) {
+	rows, err := db.Query(`
+		SELECT f."childProfileId", f."livroId", l.titulo, COALESCE(l.capa, ''), f."createdAt"
+		FROM "ChildFavorite" f
+		JOIN "Livro" l ON l.id = f."livroId"
+		WHERE f."childProfileId" = $1
+		ORDER BY f."createdAt" DESC`, childProfileId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	favorites := []models.ChildFavorite{}
+	for rows.Next() {
+		var f models.ChildFavorite
+		if err := rows.Scan(&f.ChildProfileId, &f.LivroId, &f.LivroTitulo, &f.LivroCapa, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		favorites = append(favorites, f)
+	}
+
+	return favorites, rows.Err()
+}
+
+// AddChildFavorite marca o livro como favorito do perfil infantil; marcar de novo não tem efeito
+func AddChildFavorite(childProfileId string, livroId string) error {
+	_, err := db.Exec(
+		`INSERT INTO "ChildFavorite" ("childProfileId", "livroId", "createdAt") VALUES ($1, $2, NOW())
+		 ON CONFLICT DO NOTHING`,
+		childProfileId, livroId,
+	)
+	return err
+}
+
+// RemoveChildFavorite desmarca o livro como favorito do perfil infantil
+func RemoveChildFavorite(childProfileId string, livroId string) error {
+	result, err := db.Exec(
+		`DELETE FROM "ChildFavorite" WHERE "childProfileId" = $1 AND "livroId" = $2`,
+		childProfileId, livroId,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// GetRecommendedLivros sugere livros publicados do espaço que o perfil infantil ainda não concluiu
+// nem favoritou e que os controles parentais permitem, começando pelas categorias dos livros que ele
+// já leu ou favoritou e, dentro delas, pelos mais recentes
+func GetRecommendedLivros(profile *models.ChildProfile, espacoId string, limit int) ([]models.Livro, error) {
+	rows, err := db.Query(`
+		WITH interesses AS (
+			SELECT l."categoriaId" FROM "ReadingProgress" r JOIN "Livro" l ON l.id = r."livroId"
+			WHERE r."childProfileId" = $1 AND l."categoriaId" IS NOT NULL
+			UNION
+			SELECT l."categoriaId" FROM "ChildFavorite" f JOIN "Livro" l ON l.id = f."livroId"
+			WHERE f."childProfileId" = $1 AND l."categoriaId" IS NOT NULL
+		)
+		SELECT `+livroColumns+` FROM "Livro"
+		WHERE "Livro"."espacoId" = $2 AND "Livro".publicado
+		  AND NOT EXISTS (
+		    SELECT 1 FROM "ReadingProgress" r
+		    WHERE r."childProfileId" = $1 AND r."livroId" = "Livro".id AND r.concluido
+		  )
+		  AND NOT EXISTS (
+		    SELECT 1 FROM "ChildFavorite" f WHERE f."childProfileId" = $1 AND f."livroId" = "Livro".id
+		  )
+		  AND ($4::INTEGER IS NULL OR "Livro".classificacao <= $4)
+		  AND ("Livro"."categoriaId" IS NULL OR "Livro"."categoriaId" <> ALL($5))
+		ORDER BY COALESCE("Livro"."categoriaId" IN (SELECT "categoriaId" FROM interesses), FALSE) DESC,
+		         "Livro"."createdAt" DESC
+		LIMIT $3`, profile.ID, espacoId, limit, profile.Controls.MaxClassificacao, pq.Array(profile.Controls.BlockedCategoriaIds))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	livros := []models.Livro{}
+	for rows.Next() {
+		livro, err := scanLivro(rows)
+		if err != nil {
+			return nil, err
+		}
+		livros = append(livros, *livro)
+	}
+
+	return livros, rows.Err()
+}
+
+// RecordReadingActivity registra uma atividade de leitura do perfil infantil no livro e soma ao
+// tempo de hoje o intervalo desde a atividade anterior no mesmo livro, limitado a maxSeconds para
+// que pausas longas não contem como leitura. Retorna o total de segundos lidos no dia.
+func RecordReadingActivity(childProfileId string, livroId string, maxSeconds int) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var elapsed int
+	err = tx.QueryRow(
+		`SELECT LEAST(GREATEST(FLOOR(EXTRACT(EPOCH FROM NOW() - "lastReadAt")), 0), $3)::int
+		 FROM "ReadingActivity" WHERE "childProfileId" = $1 AND "livroId" = $2 FOR UPDATE`,
+		childProfileId, livroId, maxSeconds,
+	).Scan(&elapsed)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO "ReadingActivity" ("childProfileId", "livroId", "lastReadAt") VALUES ($1, $2, NOW())
+		 ON CONFLICT ("childProfileId", "livroId") DO UPDATE SET "lastReadAt" = NOW()`,
+		childProfileId, livroId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	err = tx.QueryRow(
+		`INSERT INTO "ReadingTime" ("childProfileId", day, seconds) VALUES ($1, CURRENT_DATE, $2)
+		 ON CONFLICT ("childProfileId", day) DO UPDATE SET seconds = "ReadingTime".seconds + EXCLUDED.seconds
+		 RETURNING seconds`,
+		childProfileId, elapsed,
+	).Scan(&total)
+	if err != nil {
+		return 0, err
+	}
+
+	return total, tx.Commit()
+}
+
+// GetReadingTimeToday retorna quantos segundos o perfil infantil leu hoje
+func GetReadingTimeToday(childProfileId string) (int, error) {
+	var total int
+	err := db.QueryRow(
+		`SELECT COALESCE(SUM(seconds), 0) FROM "ReadingTime" WHERE "childProfileId" = $1 AND day = CURRENT_DATE`,
+		childProfileId,
+	).Scan(&total)
+	return total, err
+}
+
+// GetReadingTimeHistory retorna o tempo de leitura diário do perfil infantil, dos dias mais recentes aos mais antigos
+func GetReadingTimeHistory(childProfileId string) ([]models.ReadingTime, error) {
+	rows, err := db.Query(
+		`SELECT TO_CHAR(day, 'YYYY-MM-DD'), seconds FROM "ReadingTime" WHERE "childProfileId" = $1 ORDER BY day DESC`,
+		childProfileId,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []models.ReadingTime{}
+	for rows.Next() {
+		var t models.ReadingTime
+		if err := rows.Scan(&t.Day, &t.Seconds); err != nil {
+			return nil, err
+		}
+		history = append(history, t)
+	}
+
+	return history, rows.Err()
+}
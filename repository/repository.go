@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// ErrNotFound é retornado quando o registro procurado não existe
+var ErrNotFound = errors.New("registro não encontrado")
+
+// ErrDuplicate é retornado quando o registro viola uma restrição de unicidade
+var ErrDuplicate = errors.New("registro duplicado")
+
+var db *sql.DB
+
+// SetDB define a conexão com o banco usada pelo repositório
+func SetDB(database *sql.DB) {
+	db = database
+}
+
+// GetDB retorna a conexão com o banco usada pelo repositório
+func GetDB() *sql.DB {
+	return db
+}
+
+// expectAffected retorna ErrNotFound quando o comando não alterou nenhuma linha
+func expectAffected(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// isUniqueViolation informa se o erro do banco é uma violação de restrição UNIQUE
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
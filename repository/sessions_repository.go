@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+// sessionTouchInterval evita gravar lastSeenAt a cada requisição
+const sessionTouchInterval = time.Minute
+
+const sessionColumns = `id, "userId", device, "userAgent", ip, "createdAt", "lastSeenAt", "revokedAt"`
+
+// scanSession lê uma linha de sessão selecionada com sessionColumns
+func scanSession(row interface{ Scan(...interface{}) error }) (*models.Session, error) {
+	var session models.Session
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&session.ID,
+		&session.UserId,
+		&session.Device,
+		&session.UserAgent,
+		&session.IP,
+		&session.CreatedAt,
+		&session.LastSeenAt,
+		&revokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return &session, nil
+}
+
+// CreateSession registra uma nova sessão de login
+func CreateSession(session *models.Session) error {
+	session.ID = uuid.New().String()
+
+	return db.QueryRow(
+		`INSERT INTO "Session" (id, "userId", device, "userAgent", ip, "createdAt", "lastSeenAt")
+		 VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+		 RETURNING "createdAt", "lastSeenAt"`,
+		session.ID, session.UserId, session.Device, session.UserAgent, session.IP,
+	).Scan(&session.CreatedAt, &session.LastSeenAt)
+}
+
+// GetActiveSessionsByUserId retorna as sessões não revogadas que ainda podem ser renovadas
+func GetActiveSessionsByUserId(userId string) ([]models.Session, error) {
+	rows, err := db.Query(`
+		SELECT `+sessionColumns+` FROM "Session" s
+		WHERE s."userId" = $1 AND s."revokedAt" IS NULL
+		  AND EXISTS (
+		    SELECT 1 FROM "RefreshToken" r
+		    WHERE r."familyId" = s.id AND r."revokedAt" IS NULL AND r."expiresAt" > NOW()
+		  )
+		ORDER BY s."lastSeenAt" DESC`, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Session{}
+	for rows.Next() {
+		session, err := scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// TouchSession informa se a sessão está ativa e atualiza lastSeenAt, no máximo uma vez por minuto
+func TouchSession(id string) (bool, error) {
+	var active bool
+	var lastSeenAt time.Time
+	err := db.QueryRow(
+		`SELECT "revokedAt" IS NULL, "lastSeenAt" FROM "Session" WHERE id = $1`, id,
+	).Scan(&active, &lastSeenAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if active && time.Since(lastSeenAt) > sessionTouchInterval {
+		if _, err := db.Exec(`UPDATE "Session" SET "lastSeenAt" = NOW() WHERE id = $1`, id); err != nil {
+			return false, err
+		}
+	}
+	return active, nil
+}
+
+// RevokeSession encerra a sessão do usuário e revoga seus tokens de atualização.
+// Retorna ErrNotFound se a sessão não existir, for de outro usuário ou já estiver encerrada.
+func RevokeSession(id string, userId string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		`UPDATE "Session" SET "revokedAt" = NOW() WHERE id = $1 AND "userId" = $2 AND "revokedAt" IS NULL`,
+		id, userId,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE "RefreshToken" SET "revokedAt" = NOW() WHERE "familyId" = $1 AND "userId" = $2 AND "revokedAt" IS NULL`,
+		id, userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// RevokeUserSessions encerra todas as sessões do usuário, exceto exceptId (vazio encerra todas),
+// e retorna quantas sessões foram encerradas
+func RevokeUserSessions(userId string, exceptId string) (int64, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	count, err := revokeUserSessions(tx, userId, exceptId)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, tx.Commit()
+}
+
+// revokeUserSessions encerra as sessões e os tokens de atualização do usuário dentro da transação
+func revokeUserSessions(tx *sql.Tx, userId string, exceptId string) (int64, error) {
+	result, err := tx.Exec(
+		`UPDATE "Session" SET "revokedAt" = NOW() WHERE "userId" = $1 AND id <> $2 AND "revokedAt" IS NULL`,
+		userId, exceptId,
+	)
+	if err != nil {
+		return 0, err
+	}
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE "RefreshToken" SET "revokedAt" = NOW() WHERE "userId" = $1 AND "familyId" <> $2 AND "revokedAt" IS NULL`,
+		userId, exceptId,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// SetSessionChildProfile grava o perfil infantil selecionado na sessão; vazio volta à conta do responsável
+func SetSessionChildProfile(id string, childProfileId string) error {
+	_, err := db.Exec(`UPDATE "Session" SET "childProfileId" = NULLIF($1, '') WHERE id = $2`, childProfileId, id)
+	return err
+}
+
+// GetSessionChildProfileId retorna o perfil infantil selecionado na sessão, ou vazio
+func GetSessionChildProfileId(id string) (string, error) {
+	var childProfileId sql.NullString
+	err := db.QueryRow(`SELECT "childProfileId" FROM "Session" WHERE id = $1`, id).Scan(&childProfileId)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return childProfileId.String, err
+}
+
+// SetSessionEspaco grava o espaço ativo selecionado na sessão
+func SetSessionEspaco(id string, espacoId string) error {
+	_, err := db.Exec(`UPDATE "Session" SET "espacoId" = NULLIF($1, '') WHERE id = $2`, espacoId, id)
+	return err
+}
+
+// GetSessionEspacoId retorna o espaço ativo selecionado na sessão, ou vazio
+func GetSessionEspacoId(id string) (string, error) {
+	var espacoId sql.NullString
+	err := db.QueryRow(`SELECT "espacoId" FROM "Session" WHERE id = $1`, id).Scan(&espacoId)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return espacoId.String, err
+}
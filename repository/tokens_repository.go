@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const refreshTokenColumns = `id, "userId", "familyId", "expiresAt", "revokedAt", COALESCE("replacedById", ''), "createdAt"`
+
+// scanRefreshToken lê uma linha de token selecionada com refreshTokenColumns
+func scanRefreshToken(row interface{ Scan(...interface{}) error }) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	var revokedAt sql.NullTime
+	err := row.Scan(
+		&token.ID,
+		&token.UserId,
+		&token.FamilyId,
+		&token.ExpiresAt,
+		&revokedAt,
+		&token.ReplacedById,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	return &token, nil
+}
+
+// CreateRefreshToken insere um token de atualização; um familyId vazio inicia uma nova família
+func CreateRefreshToken(userId string, familyId string, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error) {
+	if familyId == "" {
+		familyId = uuid.New().String()
+	}
+
+	row := db.QueryRow(
+		`INSERT INTO "RefreshToken" (id, "userId", "familyId", "tokenHash", "expiresAt", "createdAt")
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 RETURNING `+refreshTokenColumns,
+		uuid.New().String(), userId, familyId, tokenHash, expiresAt,
+	)
+	return scanRefreshToken(row)
+}
+
+// GetRefreshTokenByHash retorna o token de atualização correspondente ao hash
+func GetRefreshTokenByHash(tokenHash string) (*models.RefreshToken, error) {
+	row := db.QueryRow(`SELECT `+refreshTokenColumns+` FROM "RefreshToken" WHERE "tokenHash" = $1`, tokenHash)
+
+	token, err := scanRefreshToken(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return token, err
+}
+
+// RotateRefreshToken revoga o token atual e emite o próximo da mesma família.
+// Retorna ErrNotFound se o token já tiver sido usado ou revogado por outra requisição.
+func RotateRefreshToken(current *models.RefreshToken, tokenHash string, expiresAt time.Time) (*models.RefreshToken, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	nextId := uuid.New().String()
+	result, err := tx.Exec(
+		`UPDATE "RefreshToken" SET "revokedAt" = NOW(), "replacedById" = $1 WHERE id = $2 AND "revokedAt" IS NULL`,
+		nextId, current.ID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := expectAffected(result); err != nil {
+		return nil, err
+	}
+
+	row := tx.QueryRow(
+		`INSERT INTO "RefreshToken" (id, "userId", "familyId", "tokenHash", "expiresAt", "createdAt")
+		 VALUES ($1, $2, $3, $4, $5, NOW())
+		 RETURNING `+refreshTokenColumns,
+		nextId, current.UserId, current.FamilyId, tokenHash, expiresAt,
+	)
+	next, err := scanRefreshToken(row)
+	if err != nil {
+		return nil, err
+	}
+
+	return next, tx.Commit()
+}
+
+// RevokeAccessToken adiciona o jti de um token de acesso à lista de revogados até sua expiração
+func RevokeAccessToken(jti string, expiresAt time.Time) error {
+	_, err := db.Exec(
+		`INSERT INTO "RevokedToken" (jti, "expiresAt") VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`,
+		jti, expiresAt,
+	)
+	return err
+}
+
+// IsAccessTokenRevoked informa se o jti está na lista de tokens revogados
+func IsAccessTokenRevoked(jti string) (bool, error) {
+	var revoked bool
+	err := db.QueryRow(`SELECT EXISTS(SELECT 1 FROM "RevokedToken" WHERE jti = $1)`, jti).Scan(&revoked)
+	return revoked, err
+}
+
+// DeleteExpiredTokens remove tokens de atualização, revogações e estados de login social que já
+// expiraram, além das sessões que ficaram sem nenhum token de atualização
+func DeleteExpiredTokens() error {
+	if _, err := db.Exec(`DELETE FROM "RefreshToken" WHERE "expiresAt" < NOW()`); err != nil {
+		return err
+	}
+	_, err := db.Exec(
+		`DELETE FROM "Session" s WHERE NOT EXISTS (SELECT 1 FROM "RefreshToken" r WHERE r."familyId" = s.id)`,
+	)
+	if err != nil {
+		return err
+	}
+	if _, err := db.Exec(`DELETE FROM "OIDCState" WHERE "expiresAt" < NOW()`); err != nil {
+		return err
+	}
+	_, err = db.Exec(`DELETE FROM "RevokedToken" WHERE "expiresAt" < NOW()`)
+	return err
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/google/uuid"
+)
+
+const userTokenColumns = `id, "userId", purpose, COALESCE(data, ''), "expiresAt", "usedAt", "createdAt"`
+
+// scanUserToken lê uma linha de token selecionada com userTokenColumns
+func scanUserToken(row interface{ Scan(...interface{}) error }) (*models.UserToken, error) {
+	var token models.UserToken
+	var usedAt sql.NullTime
+	err := row.Scan(
+		&token.ID,
+		&token.UserId,
+		&token.Purpose,
+		&token.Data,
+		&token.ExpiresAt,
+		&usedAt,
+		&token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if usedAt.Valid {
+		token.UsedAt = &usedAt.Time
+	}
+	return &token, nil
+}
+
+// CreateUserToken invalida os tokens anteriores com a mesma finalidade e insere um novo
+func CreateUserToken(userId string, purpose string, tokenHash string, data string, expiresAt time.Time) (*models.UserToken, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		`UPDATE "UserToken" SET "usedAt" = NOW() WHERE "userId" = $1 AND purpose = $2 AND "usedAt" IS NULL`,
+		userId, purpose,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := insertUserToken(tx, userId, purpose, tokenHash, data, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, tx.Commit()
+}
+
+// AddUserToken insere um novo token sem invalidar os anteriores com a mesma finalidade,
+// para links que devem continuar valendo mesmo depois de outros pedidos
+func AddUserToken(userId string, purpose string, tokenHash string, data string, expiresAt time.Time) (*models.UserToken, error) {
+	return insertUserToken(db, userId, purpose, tokenHash, data, expiresAt)
+}
+
+// insertUserToken grava o token e retorna a linha inserida
+func insertUserToken(q queryRower, userId string, purpose string, tokenHash string, data string, expiresAt time.Time) (*models.UserToken, error) {
+	row := q.QueryRow(
+		`INSERT INTO "UserToken" (id, "userId", purpose, "tokenHash", data, "expiresAt", "createdAt")
+		 VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, NOW())
+		 RETURNING `+userTokenColumns,
+		uuid.New().String(), userId, purpose, tokenHash, data, expiresAt,
+	)
+	return scanUserToken(row)
+}
+
+// GetValidUserToken retorna o token com o hash e a finalidade informados, se ainda não usado nem expirado
+func GetValidUserToken(tokenHash string, purpose string) (*models.UserToken, error) {
+	row := db.QueryRow(
+		`SELECT `+userTokenColumns+` FROM "UserToken"
+		 WHERE "tokenHash" = $1 AND purpose = $2 AND "usedAt" IS NULL AND "expiresAt" > NOW()`,
+		tokenHash, purpose,
+	)
+
+	token, err := scanUserToken(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return token, err
+}
+
+// useUserToken marca o token como usado dentro da transação.
+// Retorna ErrNotFound se outra requisição já o tiver consumido.
+func useUserToken(tx *sql.Tx, id string) error {
+	result, err := tx.Exec(
+		`UPDATE "UserToken" SET "usedAt" = NOW() WHERE id = $1 AND "usedAt" IS NULL AND "expiresAt" > NOW()`,
+		id,
+	)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// CountRecentUserTokens conta os tokens com a finalidade informada criados para o usuário desde o instante dado
+func CountRecentUserTokens(userId string, purpose string, since time.Time) (int, error) {
+	var count int
+	err := db.QueryRow(
+		`SELECT COUNT(*) FROM "UserToken" WHERE "userId" = $1 AND purpose = $2 AND "createdAt" >= $3`,
+		userId, purpose, since,
+	).Scan(&count)
+	return count, err
+}
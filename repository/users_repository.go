@@ -0,0 +1,516 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/WBianchi/maiscrianca/models"
+)
+
+const userColumns = `id, email, name, role, COALESCE("profileAvatar", ''), "emailVerifiedAt", "createdAt", "updatedAt",
+	"suspendedAt", COALESCE("suspendedReason", ''), "deletedAt", "passwordResetRequired", "avatarThumbnails"`
+
+// scanUser lê uma linha de usuário selecionada com userColumns
+func scanUser(row interface{ Scan(...interface{}) error }) (*models.User, error) {
+	var user models.User
+	var emailVerifiedAt, suspendedAt, deletedAt sql.NullTime
+	var avatarThumbnails []byte
+	err := row.Scan(
+		&user.ID,
+		&user.Email,
+		&user.Name,
+		&user.Role,
+		&user.ProfileAvatar,
+		&emailVerifiedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&suspendedAt,
+		&user.SuspendedReason,
+		&deletedAt,
+		&user.PasswordResetRequired,
+		&avatarThumbnails,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if avatarThumbnails != nil {
+		if err := json.Unmarshal(avatarThumbnails, &user.AvatarThumbnails); err != nil {
+			return nil, err
+		}
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if suspendedAt.Valid {
+		user.SuspendedAt = &suspendedAt.Time
+	}
+	if deletedAt.Valid {
+		user.DeletedAt = &deletedAt.Time
+	}
+	return &user, nil
+}
+
+// GetUserById retorna um usuário pelo ID
+func GetUserById(id string) (*models.User, error) {
+	row := db.QueryRow(`SELECT `+userColumns+` FROM "User" WHERE id = $1`, id)
+
+	user, err := scanUser(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return user, err
+}
+
+// UpdateUserRole altera a role global do usuário e grava o registro de auditoria na mesma transação
+func UpdateUserRole(id string, role models.Role, audit *models.AuditLog) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE "User" SET role = $1, "updatedAt" = NOW() WHERE id = $2`, string(role), id)
+	if err != nil {
+		return err
+	}
+	if err := expectAffected(result); err != nil {
+		return err
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetUserByEmail retorna um usuário pelo email
+func GetUserByEmail(email string) (*models.User, error) {
+	row := db.QueryRow(`SELECT `+userColumns+` FROM "User" WHERE email = $1`, email)
+
+	user, err := scanUser(row)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	return user, err
+}
+
+// IsEmailInUse informa se já existe uma conta cadastrada com o email
+func IsEmailInUse(email string) (bool, error) {
+	var inUse bool
+	err := db.QueryRow(`SELECT EXISTS (SELECT 1 FROM "User" WHERE email = $1)`, email).Scan(&inUse)
+	return inUse, err
+}
+
+// ResetUserPassword consome o token de redefinição, grava a nova senha e encerra
+// as sessões do usuário, tudo na mesma transação
+func ResetUserPassword(token *models.UserToken, hashedPassword string) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := useUserToken(tx, token.ID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE "User" SET password = $1, "passwordResetRequired" = FALSE, "updatedAt" = NOW() WHERE id = $2`,
+		hashedPassword, token.UserId,
+	)
+	if err != nil {
+		return err
+	}
+
+	if _, err := revokeUserSessions(tx, token.UserId, ""); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ChangeUserPassword grava a nova senha do usuário, encerra as demais sessões (exceto sessionId)
+// e grava a auditoria na mesma transação. Retorna quantas sessões foram encerradas.
+func ChangeUserPassword(id string, hashedPassword string, sessionId string, audit *models.AuditLog) (int64, error) {
+	var revoked int64
+	err := updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE "User" SET password = $1, "passwordResetRequired" = FALSE, "updatedAt" = NOW()
+			 WHERE id = $2 AND "deletedAt" IS NULL`,
+			hashedPassword, id,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return err
+		}
+		revoked, err = revokeUserSessions(tx, id, sessionId)
+		return err
+	})
+	return revoked, err
+}
+
+// GetUserPasswordHash retorna o hash da senha do usuário, para reconfirmar a senha em operações sensíveis
+func GetUserPasswordHash(id string) (string, error) {
+	var hash string
+	err := db.QueryRow(`SELECT password FROM "User" WHERE id = $1`, id).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", ErrNotFound
+	}
+	return hash, err
+}
+
+// IsEmailVerified informa se o usuário já confirmou o email
+func IsEmailVerified(id string) (bool, error) {
+	var verified bool
+	err := db.QueryRow(`SELECT "emailVerifiedAt" IS NOT NULL FROM "User" WHERE id = $1`, id).Scan(&verified)
+	if err == sql.ErrNoRows {
+		return false, ErrNotFound
+	}
+	return verified, err
+}
+
+// VerifyUserEmail consome o token de verificação e marca o email do usuário como verificado
+func VerifyUserEmail(token *models.UserToken) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := useUserToken(tx, token.ID); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`UPDATE "User" SET "emailVerifiedAt" = COALESCE("emailVerifiedAt", NOW()), "updatedAt" = NOW() WHERE id = $1`,
+		token.UserId,
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ChangeUserEmail consome o token de troca de email e passa a conta para o novo endereço,
+// já verificado pelo próprio link, gravando a auditoria na mesma transação. Os links ainda não
+// usados enviados ao endereço anterior (redefinição de senha, acesso sem senha, verificação)
+// são invalidados; só os de desfazer a troca continuam valendo.
+// Retorna ErrDuplicate se o novo email tiver sido cadastrado por outra conta nesse meio tempo.
+func ChangeUserEmail(token *models.UserToken, audit *models.AuditLog) error {
+	return updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		if err := useUserToken(tx, token.ID); err != nil {
+			return err
+		}
+		result, err := tx.Exec(
+			`UPDATE "User" SET email = $1, "emailVerifiedAt" = NOW(), "updatedAt" = NOW()
+			 WHERE id = $2 AND "deletedAt" IS NULL`,
+			token.Data, token.UserId,
+		)
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return err
+		}
+		_, err = tx.Exec(
+			`UPDATE "UserToken" SET "usedAt" = NOW() WHERE "userId" = $1 AND purpose <> $2 AND "usedAt" IS NULL`,
+			token.UserId, models.TokenEmailChangeUndo,
+		)
+		return err
+	})
+}
+
+// UndoEmailChange consome o token de desfazer enviado ao email anterior e devolve a conta a esse
+// endereço. Como a troca pode ter partido de quem roubou a senha, os demais links de troca são
+// invalidados, as sessões encerradas e uma nova senha passa a ser exigida, tudo na mesma transação.
+func UndoEmailChange(token *models.UserToken, audit *models.AuditLog) error {
+	return updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		if err := useUserToken(tx, token.ID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(
+			`UPDATE "UserToken" SET "usedAt" = NOW()
+			 WHERE "userId" = $1 AND purpose IN ($2, $3) AND "usedAt" IS NULL`,
+			token.UserId, models.TokenEmailChange, models.TokenEmailChangeUndo,
+		)
+		if err != nil {
+			return err
+		}
+		result, err := tx.Exec(
+			`UPDATE "User" SET
+				"emailVerifiedAt" = CASE WHEN email = $1 THEN "emailVerifiedAt" ELSE NOW() END,
+				email = $1,
+				"passwordResetRequired" = TRUE,
+				"updatedAt" = NOW()
+			 WHERE id = $2 AND "deletedAt" IS NULL`,
+			token.Data, token.UserId,
+		)
+		if isUniqueViolation(err) {
+			return ErrDuplicate
+		}
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return err
+		}
+		_, err = revokeUserSessions(tx, token.UserId, "")
+		return err
+	})
+}
+
+// ListUsers retorna a página de usuários que atende aos filtros, dos mais recentes para os mais
+// antigos, e o total de usuários encontrados. A busca compara nome e email sem diferenciar maiúsculas.
+func ListUsers(filter models.UserFilter) ([]models.User, int, error) {
+	var conditions []string
+	var args []interface{}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	switch filter.Status {
+	case models.UserStatusActive:
+		conditions = append(conditions, `"deletedAt" IS NULL AND "suspendedAt" IS NULL`)
+	case models.UserStatusSuspended:
+		conditions = append(conditions, `"deletedAt" IS NULL AND "suspendedAt" IS NOT NULL`)
+	case models.UserStatusDeleted:
+		conditions = append(conditions, `"deletedAt" IS NOT NULL`)
+	default:
+		conditions = append(conditions, `"deletedAt" IS NULL`)
+	}
+	if filter.Search != "" {
+		pattern := arg("%" + escapeLike(filter.Search) + "%")
+		conditions = append(conditions, `(name ILIKE `+pattern+` OR email ILIKE `+pattern+`)`)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, `role = `+arg(string(filter.Role)))
+	}
+	if filter.CreatedFrom != nil {
+		conditions = append(conditions, `"createdAt" >= `+arg(*filter.CreatedFrom))
+	}
+	if filter.CreatedTo != nil {
+		conditions = append(conditions, `"createdAt" < `+arg(*filter.CreatedTo))
+	}
+	where := ` WHERE ` + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM "User"`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := arg(filter.PageSize)
+	offset := arg((filter.Page - 1) * filter.PageSize)
+	rows, err := db.Query(
+		`SELECT `+userColumns+` FROM "User"`+where+` ORDER BY "createdAt" DESC, id LIMIT `+limit+` OFFSET `+offset,
+		args...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, *user)
+	}
+
+	return users, total, rows.Err()
+}
+
+// escapeLike escapa os curingas do LIKE para que a busca trate o texto literalmente
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// GetUserStats retorna os totais de usuários exibidos no painel administrativo.
+// Contas excluídas só entram no próprio contador.
+func GetUserStats() (*models.UserStats, error) {
+	stats := models.UserStats{ByRole: map[models.Role]int{}}
+	err := db.QueryRow(`
+		SELECT
+			COUNT(*) FILTER (WHERE "deletedAt" IS NULL),
+			COUNT(*) FILTER (WHERE "deletedAt" IS NULL AND "suspendedAt" IS NOT NULL),
+			COUNT(*) FILTER (WHERE "deletedAt" IS NOT NULL),
+			COUNT(*) FILTER (WHERE "deletedAt" IS NULL AND "createdAt" >= NOW() - INTERVAL '30 days'),
+			COUNT(*) FILTER (WHERE "deletedAt" IS NULL AND "emailVerifiedAt" IS NULL)
+		FROM "User"`,
+	).Scan(&stats.Total, &stats.Suspended, &stats.Deleted, &stats.NewLast30Days, &stats.Unverified)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.Query(`SELECT role, COUNT(*) FROM "User" WHERE "deletedAt" IS NULL GROUP BY role`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var role models.Role
+		var count int
+		if err := rows.Scan(&role, &count); err != nil {
+			return nil, err
+		}
+		stats.ByRole[role] = count
+	}
+
+	return &stats, rows.Err()
+}
+
+// SuspendUser suspende a conta, encerra suas sessões e grava a auditoria na mesma transação.
+// Retorna ErrNotFound se a conta não existir, já estiver suspensa ou tiver sido excluída.
+func SuspendUser(id string, reason string, audit *models.AuditLog) error {
+	return updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE "User" SET "suspendedAt" = NOW(), "suspendedReason" = NULLIF($1, ''), "updatedAt" = NOW()
+			 WHERE id = $2 AND "suspendedAt" IS NULL AND "deletedAt" IS NULL`,
+			reason, id,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return err
+		}
+		_, err = revokeUserSessions(tx, id, "")
+		return err
+	})
+}
+
+// ReactivateUser remove a suspensão da conta e grava a auditoria na mesma transação.
+// Retorna ErrNotFound se a conta não existir, não estiver suspensa ou tiver sido excluída.
+func ReactivateUser(id string, audit *models.AuditLog) error {
+	return updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE "User" SET "suspendedAt" = NULL, "suspendedReason" = NULL, "updatedAt" = NOW()
+			 WHERE id = $1 AND "suspendedAt" IS NOT NULL AND "deletedAt" IS NULL`,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		return expectAffected(result)
+	})
+}
+
+// RequirePasswordReset obriga o usuário a definir uma nova senha antes do próximo login com senha,
+// encerra suas sessões e grava a auditoria na mesma transação
+func RequirePasswordReset(id string, audit *models.AuditLog) error {
+	return updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE "User" SET "passwordResetRequired" = TRUE, "updatedAt" = NOW() WHERE id = $1 AND "deletedAt" IS NULL`,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return err
+		}
+		_, err = revokeUserSessions(tx, id, "")
+		return err
+	})
+}
+
+// SoftDeleteUser marca a conta como excluída, encerra suas sessões, revoga suas chaves de API
+// e grava a auditoria na mesma transação. Os dados são mantidos para auditoria.
+func SoftDeleteUser(id string, audit *models.AuditLog) error {
+	return updateUserWithAudit(audit, func(tx *sql.Tx) error {
+		result, err := tx.Exec(
+			`UPDATE "User" SET "deletedAt" = NOW(), "updatedAt" = NOW() WHERE id = $1 AND "deletedAt" IS NULL`,
+			id,
+		)
+		if err != nil {
+			return err
+		}
+		if err := expectAffected(result); err != nil {
+			return err
+		}
+		if _, err := revokeUserSessions(tx, id, ""); err != nil {
+			return err
+		}
+		_, err = tx.Exec(`UPDATE "ApiKey" SET "revokedAt" = NOW() WHERE "userId" = $1 AND "revokedAt" IS NULL`, id)
+		return err
+	})
+}
+
+// updateUserWithAudit executa a alteração da conta e grava o registro de auditoria na mesma transação
+func updateUserWithAudit(audit *models.AuditLog, update func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := update(tx); err != nil {
+		return err
+	}
+
+	if err := insertAuditLog(tx, audit); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateUserName altera o nome do usuário
+func UpdateUserName(id string, name string) error {
+	result, err := db.Exec(`UPDATE "User" SET name = $1, "updatedAt" = NOW() WHERE id = $2`, name, id)
+	if err != nil {
+		return err
+	}
+	return expectAffected(result)
+}
+
+// SetUserAvatar troca o avatar e as miniaturas do usuário em uma única atualização; avatar vazio
+// remove o avatar. Retorna as URLs das miniaturas anteriores, que deixam de ser usadas.
+func SetUserAvatar(id string, avatar string, thumbnails map[string]string) ([]string, error) {
+	var thumbnailsJSON []byte
+	if len(thumbnails) > 0 {
+		var err error
+		if thumbnailsJSON, err = json.Marshal(thumbnails); err != nil {
+			return nil, err
+		}
+	}
+
+	var previousJSON []byte
+	err := db.QueryRow(
+		`UPDATE "User" u SET "profileAvatar" = NULLIF($1, ''), "avatarThumbnails" = $2, "updatedAt" = NOW()
+		 FROM (SELECT id, "avatarThumbnails" FROM "User" WHERE id = $3 FOR UPDATE) previous
+		 WHERE u.id = previous.id
+		 RETURNING previous."avatarThumbnails"`,
+		avatar, thumbnailsJSON, id,
+	).Scan(&previousJSON)
+	if err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var previous map[string]string
+	if previousJSON != nil {
+		if err := json.Unmarshal(previousJSON, &previous); err != nil {
+			return nil, err
+		}
+	}
+	urls := make([]string, 0, len(previous))
+	for _, url := range previous {
+		urls = append(urls, url)
+	}
+	return urls, nil
+}
@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupAdminRoutes configura as rotas administrativas de gestão de usuários
+func SetupAdminRoutes(app *fiber.App, adminController *controllers.AdminController, apiKeyController *controllers.APIKeyController, config *configs.Config) {
+	admin := app.Group("/api/admin", middleware.AuthMiddleware(config), middleware.BlockChildMode(), middleware.RequirePermission(policy.UsersManage))
+
+	// Códigos de convite para cadastro de funcionários e afiliados
+	admin.Get("/invite-codes", adminController.ListCodigosConvite)
+	admin.Post("/invite-codes", adminController.CreateCodigoConvite)
+	admin.Delete("/invite-codes/:id", adminController.RevokeCodigoConvite)
+
+	// Totais do painel administrativo
+	admin.Get("/dashboard-data", adminController.GetDashboardData)
+
+	// Consulta de usuários, com busca, filtros e paginação
+	admin.Get("/users", adminController.ListUsers)
+	admin.Get("/users/:id", adminController.GetUser)
+
+	// Suspensão, reativação e exclusão lógica de contas
+	admin.Post("/users/:id/suspend", adminController.SuspendUser)
+	admin.Post("/users/:id/reactivate", adminController.ReactivateUser)
+	admin.Delete("/users/:id", adminController.DeleteUser)
+
+	// Redefinição de senha forçada, com envio do link ao usuário
+	admin.Post("/users/:id/password-reset", adminController.ForcePasswordReset)
+
+	// Alteração de role, única forma de conceder ADMIN
+	admin.Put("/users/:id/role", adminController.ChangeUserRole)
+
+	// Desbloqueio de contas bloqueadas por excesso de tentativas de login
+	admin.Post("/users/:id/unlock", adminController.UnlockUser)
+
+	// Encerramento forçado de todas as sessões do usuário
+	admin.Post("/users/:id/sessions/revoke", adminController.RevokeUserSessions)
+
+	// Personificação ("ver como usuário") para o suporte, com auditoria de cada requisição
+	admin.Post("/users/:id/impersonate", adminController.ImpersonateUser)
+
+	// Chaves de API para integrações servidor a servidor
+	admin.Get("/api-keys", apiKeyController.ListAPIKeys)
+	admin.Post("/api-keys", apiKeyController.CreateAPIKey)
+	admin.Delete("/api-keys/:id", apiKeyController.RevokeAPIKey)
+}
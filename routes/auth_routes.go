@@ -1,15 +1,33 @@
 package routes
 
 import (
+	"github.com/WBianchi/maiscrianca/configs"
 	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupAuthRoutes configura as rotas de autenticação
-func SetupAuthRoutes(app *fiber.App, authController *controllers.AuthController) {
+func SetupAuthRoutes(app *fiber.App, authController *controllers.AuthController, authStatusController *controllers.AuthStatusController, config *configs.Config) {
 	auth := app.Group("/api/auth")
 	
 	// Rotas públicas
 	auth.Post("/login", authController.Login)
 	auth.Post("/register", authController.Register)
+	auth.Post("/mfa/verify", authController.VerifyMFA)
+	auth.Post("/refresh", authController.Refresh)
+	auth.Post("/forgot-password", authController.ForgotPassword)
+	auth.Post("/reset-password", authController.ResetPassword)
+	auth.Post("/verify-email", authController.VerifyEmail)
+	auth.Post("/magic-link", authController.RequestMagicLink)
+	auth.Post("/magic-link/verify", authController.ConsumeMagicLink)
+	auth.Post("/change-email/confirm", authController.ConfirmEmailChange)
+	auth.Post("/change-email/undo", authController.UndoEmailChange)
+	auth.Get("/csrf", authController.CSRFToken)
+	
+	// Rotas autenticadas
+	auth.Post("/logout", middleware.AuthMiddleware(config), authController.Logout)
+	auth.Post("/resend-verification", middleware.AuthMiddleware(config), authController.ResendVerification)
+	auth.Post("/change-email", middleware.AuthMiddleware(config), middleware.BlockImpersonation(), middleware.BlockChildMode(), authController.RequestEmailChange)
+	auth.Get("/status", middleware.AuthMiddleware(config), authStatusController.GetAuthStatus)
 }
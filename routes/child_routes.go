@@ -0,0 +1,41 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/models"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupChildRoutes configura a gestão dos perfis infantis pelos responsáveis e as rotas de
+// leitura do perfil selecionado (progresso, favoritos, recomendações e tempo de leitura), sujeitas
+// aos controles parentais
+func SetupChildRoutes(app *fiber.App, childController *controllers.ChildController, readingController *controllers.ReadingController, config *configs.Config) {
+	children := app.Group("/api/user/children", middleware.AuthMiddleware(config), middleware.RoleGuard(models.CLIENT))
+	children.Get("/", childController.ListChildren)
+	children.Delete("/selected", childController.DeselectChild)
+	children.Get("/:id", childController.GetChild)
+	children.Post("/:id/select", childController.SelectChild)
+
+	// Controles parentais: exigem a senha ou o PIN do responsável, mesmo com um perfil infantil selecionado
+	children.Put("/:id/controls", childController.UpdateControls)
+
+	// Gestão dos perfis, indisponível enquanto uma criança estiver usando a conta
+	parent := middleware.BlockChildMode()
+	children.Put("/parental-pin", parent, childController.SetParentalPin)
+	children.Post("/", parent, childController.CreateChild)
+	children.Put("/:id", parent, childController.UpdateChild)
+	children.Delete("/:id", parent, childController.DeleteChild)
+
+	leitura := app.Group("/api/leitura", middleware.AuthMiddleware(config), middleware.EspacoMiddleware(),
+		middleware.RequireChildProfile(), middleware.RequirePermission(policy.LivrosRead))
+	leitura.Get("/progresso", readingController.ListProgress)
+	leitura.Put("/progresso/:livroId", middleware.EnforceScreenTime(), readingController.SaveProgress)
+	leitura.Get("/favoritos", readingController.ListFavorites)
+	leitura.Put("/favoritos/:livroId", readingController.AddFavorite)
+	leitura.Delete("/favoritos/:livroId", readingController.RemoveFavorite)
+	leitura.Get("/recomendacoes", readingController.GetRecommendations)
+	leitura.Get("/tempo", readingController.GetScreenTime)
+}
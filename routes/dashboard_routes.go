@@ -1,13 +1,16 @@
 package routes
 
 import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/policy"
 	"github.com/gofiber/fiber/v2"
-	"github.com/maiscrianca/backend/controllers"
-	"github.com/maiscrianca/backend/middleware"
 )
 
-// SetupDashboardRoutes configura as rotas do dashboard
-func SetupDashboardRoutes(app *fiber.App) {
-	dashboard := app.Group("/api/dashboard", middleware.AuthMiddleware)
+// SetupDashboardRoutes configura as rotas do dashboard. As métricas são da loja inteira, e não de um
+// espaço, então a permissão vem só da role global.
+func SetupDashboardRoutes(app *fiber.App, config *configs.Config) {
+	dashboard := app.Group("/api/dashboard", middleware.AllowAPIKey(), middleware.AuthMiddleware(config), middleware.BlockChildMode(), middleware.RequirePermission(policy.DashboardRead))
 	dashboard.Get("/metrics", controllers.GetDashboardMetrics)
 }
@@ -0,0 +1,46 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupEspacoRoutes configura as rotas de gestão de espaços, membros, convites e papéis personalizados
+func SetupEspacoRoutes(app *fiber.App, espacoController *controllers.EspacoController, papelController *controllers.PapelController, config *configs.Config) {
+	espacos := app.Group("/api/espacos", middleware.AuthMiddleware(config))
+
+	// Listar e escolher o espaço ativo continuam disponíveis com um perfil infantil selecionado,
+	// para que a criança leia o catálogo do espaço; a gestão é só do responsável
+	parent := middleware.BlockChildMode()
+
+	// Rotas do usuário autenticado
+	espacos.Get("/", espacoController.ListEspacos)
+	espacos.Post("/", parent, middleware.RequirePermission(policy.EspacosManage), espacoController.CreateEspaco)
+	espacos.Get("/convites", parent, espacoController.ListMeusConvites)
+	espacos.Post("/convites/aceitar", parent, espacoController.AcceptConvite)
+
+	// Rotas de membros do espaço
+	membro := middleware.EspacoMiddleware()
+	espacos.Post("/:espacoId/selecionar", membro, espacoController.SelectEspaco)
+
+	// Rotas restritas aos administradores do espaço
+	admin := middleware.RequirePermission(policy.EspacosManage)
+	espacos.Put("/:espacoId", parent, membro, admin, espacoController.UpdateEspaco)
+	espacos.Get("/:espacoId/membros", parent, membro, admin, espacoController.ListMembros)
+	espacos.Put("/:espacoId/membros/:userId", parent, membro, admin, espacoController.UpdateMembroRole)
+	espacos.Delete("/:espacoId/membros/:userId", parent, membro, admin, espacoController.RemoveMembro)
+	espacos.Get("/:espacoId/convites", parent, membro, admin, espacoController.ListConvites)
+	espacos.Post("/:espacoId/convites", parent, membro, admin, espacoController.CreateConvite)
+	espacos.Delete("/:espacoId/convites/:conviteId", parent, membro, admin, espacoController.RevokeConvite)
+
+	// Papéis personalizados do espaço e sua atribuição aos membros
+	espacos.Get("/:espacoId/papeis", parent, membro, admin, papelController.ListPapeis)
+	espacos.Get("/:espacoId/papeis/permissoes", parent, membro, admin, papelController.ListPermissions)
+	espacos.Post("/:espacoId/papeis", parent, membro, admin, papelController.CreatePapel)
+	espacos.Put("/:espacoId/papeis/:papelId", parent, membro, admin, papelController.UpdatePapel)
+	espacos.Delete("/:espacoId/papeis/:papelId", parent, membro, admin, papelController.DeletePapel)
+	espacos.Put("/:espacoId/membros/:userId/papel", parent, membro, admin, papelController.AssignPapel)
+}
@@ -0,0 +1,11 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupJWKSRoutes configura a rota pública com as chaves de verificação dos tokens
+func SetupJWKSRoutes(app *fiber.App) {
+	app.Get("/.well-known/jwks.json", controllers.GetJWKS)
+}
@@ -1,31 +1,40 @@
 package routes
 
 import (
-	"github.com/gofiber/fiber/v2"
+	"github.com/WBianchi/maiscrianca/configs"
 	"github.com/WBianchi/maiscrianca/controllers"
 	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/gofiber/fiber/v2"
 )
 
 // SetupLivrosRoutes configura as rotas para gestão de livros
-func SetupLivrosRoutes(app *fiber.App) {
-	livros := app.Group("/api/livros", middleware.AuthMiddleware)
+func SetupLivrosRoutes(app *fiber.App, config *configs.Config) {
+	// Todas as rotas do catálogo exigem permissão, então aceitam chaves de API limitadas aos escopos
+	livros := app.Group("/api/livros", middleware.AllowAPIKey(), middleware.AuthMiddleware(config), middleware.EspacoMiddleware(), middleware.ChildProfileContext())
 	
+	// Com um perfil infantil selecionado, o catálogo só é lido e a escrita fica com o responsável.
+	// O limite diário de tempo vale para abrir e baixar livros; a listagem não conta como leitura
+	// e continua disponível para que o app mostre o catálogo e o tempo restante.
+	parent := middleware.BlockChildMode()
+
 	// Rotas de livros
-	livros.Get("/", controllers.GetLivros)
-	livros.Post("/", controllers.CreateLivro)
-	livros.Get("/:id", controllers.GetLivro)
-	livros.Put("/:id", controllers.UpdateLivro)
-	livros.Delete("/:id", controllers.DeleteLivro)
+	livros.Get("/", middleware.RequirePermission(policy.LivrosRead), controllers.GetLivros)
+	livros.Post("/", parent, middleware.RequirePermission(policy.LivrosWrite), controllers.CreateLivro)
+	livros.Get("/:id", middleware.RequirePermission(policy.LivrosRead), middleware.EnforceScreenTime(), controllers.GetLivro)
+	livros.Put("/:id", parent, middleware.RequirePermission(policy.LivrosWrite), controllers.UpdateLivro)
+	livros.Delete("/:id", parent, middleware.RequirePermission(policy.LivrosDelete), controllers.DeleteLivro)
+	livros.Get("/:id/download", middleware.RequirePermission(policy.LivrosRead), middleware.RequireVerifiedEmail(config, "download"), middleware.EnforceScreenTime(), controllers.DownloadLivro)
 	
 	// Rotas para upload de imagens e arquivos usando vercel blob
-	livros.Post("/upload/capa", controllers.UploadCapa)
-	livros.Post("/upload/arquivo", controllers.UploadArquivo)
-	livros.Post("/upload/pagina", controllers.UploadPagina)
+	livros.Post("/upload/capa", parent, middleware.RequirePermission(policy.LivrosWrite), controllers.UploadCapa)
+	livros.Post("/upload/arquivo", parent, middleware.RequirePermission(policy.LivrosWrite), controllers.UploadArquivo)
+	livros.Post("/upload/pagina", parent, middleware.RequirePermission(policy.LivrosWrite), controllers.UploadPagina)
 	
 	// Rotas de categorias
-	categorias := app.Group("/api/categorias", middleware.AuthMiddleware)
-	categorias.Get("/", controllers.GetCategorias)
-	categorias.Post("/", controllers.CreateCategoria)
-	categorias.Put("/:id", controllers.UpdateCategoria)
-	categorias.Delete("/:id", controllers.DeleteCategoria)
+	categorias := app.Group("/api/categorias", middleware.AllowAPIKey(), middleware.AuthMiddleware(config), middleware.EspacoMiddleware(), middleware.ChildProfileContext())
+	categorias.Get("/", middleware.RequirePermission(policy.CategoriasRead), controllers.GetCategorias)
+	categorias.Post("/", parent, middleware.RequirePermission(policy.CategoriasWrite), controllers.CreateCategoria)
+	categorias.Put("/:id", parent, middleware.RequirePermission(policy.CategoriasWrite), controllers.UpdateCategoria)
+	categorias.Delete("/:id", parent, middleware.RequirePermission(policy.CategoriasWrite), controllers.DeleteCategoria)
 }
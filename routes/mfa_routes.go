@@ -0,0 +1,19 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupMFARoutes configura as rotas de autenticação em dois fatores do usuário autenticado
+func SetupMFARoutes(app *fiber.App, mfaController *controllers.MFAController, config *configs.Config) {
+	mfa := app.Group("/api/user/2fa", middleware.AuthMiddleware(config), middleware.BlockImpersonation(), middleware.BlockChildMode())
+
+	mfa.Get("/", mfaController.GetStatus)
+	mfa.Post("/setup", mfaController.Setup)
+	mfa.Post("/enable", mfaController.Enable)
+	mfa.Post("/disable", mfaController.Disable)
+	mfa.Post("/recovery-codes", mfaController.RegenerateRecoveryCodes)
+}
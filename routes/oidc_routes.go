@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupOIDCRoutes configura as rotas de login social e de provedores vinculados à conta
+func SetupOIDCRoutes(app *fiber.App, oidcController *controllers.OIDCController, config *configs.Config) {
+	// Rotas públicas do fluxo authorization code
+	oidc := app.Group("/api/auth/oidc")
+	oidc.Get("/providers", oidcController.ListProviders)
+	oidc.Post("/:provider/start", oidcController.StartLogin)
+	oidc.Post("/:provider/callback", oidcController.Callback)
+
+	// Provedores vinculados à conta do usuário autenticado
+	identities := app.Group("/api/user/identities", middleware.AuthMiddleware(config), middleware.BlockChildMode())
+	identities.Get("/", oidcController.ListIdentities)
+	identities.Post("/:provider/link", middleware.BlockImpersonation(), oidcController.StartLink)
+	identities.Post("/:provider/callback", middleware.BlockImpersonation(), oidcController.LinkCallback)
+	identities.Delete("/:provider", middleware.BlockImpersonation(), oidcController.Unlink)
+}
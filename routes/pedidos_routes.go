@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/WBianchi/maiscrianca/policy"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupPedidosRoutes configura a consulta aos pedidos da loja, disponível também para chaves de API
+// com o escopo pedidos:read. Os pedidos são da loja inteira, então a permissão vem da role global.
+func SetupPedidosRoutes(app *fiber.App, config *configs.Config) {
+	pedidos := app.Group("/api/pedidos", middleware.AllowAPIKey(), middleware.AuthMiddleware(config), middleware.BlockChildMode(), middleware.RequirePermission(policy.PedidosRead))
+	pedidos.Get("/", controllers.GetPedidos)
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupPrivacyRoutes configura as rotas de direitos do titular (LGPD) do usuário autenticado.
+// Só o próprio titular pode usá-las: personificação, chaves de API e perfis infantis são recusados.
+func SetupPrivacyRoutes(app *fiber.App, privacyController *controllers.PrivacyController, config *configs.Config) {
+	app.Get("/api/user/data-export", middleware.AuthMiddleware(config), middleware.BlockImpersonation(), middleware.BlockChildMode(), privacyController.ExportData)
+
+	consents := app.Group("/api/user/consents", middleware.AuthMiddleware(config), middleware.BlockImpersonation(), middleware.BlockChildMode())
+	consents.Get("/", privacyController.GetConsents)
+	consents.Put("/marketing", privacyController.UpdateMarketingConsent)
+
+	deletion := app.Group("/api/user/data-deletion", middleware.AuthMiddleware(config), middleware.BlockImpersonation(), middleware.BlockChildMode())
+	deletion.Get("/", privacyController.GetDeletionRequest)
+	deletion.Post("/", privacyController.RequestDeletion)
+	deletion.Delete("/", privacyController.CancelDeletion)
+}
@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/WBianchi/maiscrianca/configs"
+	"github.com/WBianchi/maiscrianca/controllers"
+	"github.com/WBianchi/maiscrianca/middleware"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SetupSessionRoutes configura as rotas de sessões do usuário autenticado
+func SetupSessionRoutes(app *fiber.App, sessionController *controllers.SessionController, config *configs.Config) {
+	sessions := app.Group("/api/user/sessions", middleware.AuthMiddleware(config), middleware.BlockChildMode())
+
+	sessions.Get("/", sessionController.ListSessions)
+	sessions.Delete("/", middleware.BlockImpersonation(), sessionController.RevokeOtherSessions)
+	sessions.Delete("/:id", middleware.BlockImpersonation(), sessionController.RevokeSession)
+}
@@ -17,31 +17,27 @@ func SetupUserRoutes(app *fiber.App, userController *controllers.UserController,
 	
 	// Rotas para todos os usuários autenticados
 	user.Get("/profile", userController.GetUserProfile)
-	user.Put("/profile", userController.UpdateUserProfile)
+	user.Put("/profile", middleware.BlockChildMode(), userController.UpdateUserProfile)
+	user.Put("/password", middleware.BlockImpersonation(), middleware.BlockChildMode(), userController.ChangePassword)
+	user.Post("/avatar", middleware.BlockChildMode(), userController.UploadAvatar)
+	user.Delete("/avatar", middleware.BlockChildMode(), userController.DeleteAvatar)
 	
-	// Rotas protegidas por role
-	admin := api.Group("/admin", middleware.AuthMiddleware(config), middleware.RoleGuard(models.ADMIN))
-	admin.Get("/dashboard-data", func(c *fiber.Ctx) error {
-		return c.JSON(fiber.Map{
-			"message": "Dados do dashboard administrativo",
-		})
-	})
-	
-	employee := api.Group("/employee", middleware.AuthMiddleware(config), middleware.RoleGuard(models.EMPLOYEE))
+	// Rotas protegidas por role (o dashboard administrativo fica nas rotas de admin)
+	employee := api.Group("/employee", middleware.AuthMiddleware(config), middleware.BlockChildMode(), middleware.RoleGuard(models.EMPLOYEE))
 	employee.Get("/dashboard-data", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"message": "Dados do dashboard de funcionário",
 		})
 	})
 	
-	client := api.Group("/client", middleware.AuthMiddleware(config), middleware.RoleGuard(models.CLIENT))
+	client := api.Group("/client", middleware.AuthMiddleware(config), middleware.BlockChildMode(), middleware.RoleGuard(models.CLIENT))
 	client.Get("/dashboard-data", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"message": "Dados do dashboard de cliente",
 		})
 	})
 	
-	affiliate := api.Group("/affiliate", middleware.AuthMiddleware(config), middleware.RoleGuard(models.AFFILIATE))
+	affiliate := api.Group("/affiliate", middleware.AuthMiddleware(config), middleware.BlockChildMode(), middleware.RoleGuard(models.AFFILIATE))
 	affiliate.Get("/dashboard-data", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"message": "Dados do dashboard de afiliado",
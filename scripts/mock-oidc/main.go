@@ -0,0 +1,40 @@
+// Provedor OpenID Connect falso para testar o login social localmente.
+//
+// Uso:
+//
+//	go run ./scripts/mock-oidc -addr :9000 -email responsavel@example.com
+//
+// E no .env do backend:
+//
+//	OIDC_PROVIDERS=mock
+//	OIDC_MOCK_ISSUER=http://localhost:9000
+//	OIDC_MOCK_CLIENT_ID=maiscrianca
+//
+// A página /authorize aprova o login automaticamente e redireciona para o redirect_uri
+// com code e state. O email pode ser trocado por requisição com ?login_hint=outro@example.com.
+// O provedor fica no pacote oidc/oidctest, também usado pelos testes do cliente OIDC.
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/WBianchi/maiscrianca/oidc/oidctest"
+)
+
+func main() {
+	addr := flag.String("addr", ":9000", "endereço do servidor")
+	issuer := flag.String("issuer", "http://localhost:9000", "issuer publicado na descoberta")
+	clientID := flag.String("client-id", "maiscrianca", "client_id aceito")
+	email := flag.String("email", "responsavel@example.com", "email padrão do usuário autenticado")
+	flag.Parse()
+
+	provider, err := oidctest.NewProvider(*issuer, *clientID, *email)
+	if err != nil {
+		log.Fatalf("Erro ao gerar chave: %v", err)
+	}
+
+	log.Printf("Provedor OIDC falso em %s (issuer %s)", *addr, *issuer)
+	log.Fatal(http.ListenAndServe(*addr, provider.Handler()))
+}
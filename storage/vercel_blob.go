@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const vercelBlobAPIURL = "https://blob.vercel-storage.com"
+
+// UploadResult representa o arquivo armazenado no Vercel Blob
+type UploadResult struct {
+	URL         string `json:"url"`
+	DownloadURL string `json:"downloadUrl"`
+	Pathname    string `json:"pathname"`
+	ContentType string `json:"contentType"`
+}
+
+// VercelBlob envia arquivos para o Vercel Blob usando a API HTTP
+type VercelBlob struct {
+	Token      string
+	APIURL     string
+	HTTPClient *http.Client
+}
+
+// NewVercelBlob cria um cliente do Vercel Blob com o token de leitura e escrita
+func NewVercelBlob(token string) *VercelBlob {
+	return &VercelBlob{
+		Token:      token,
+		APIURL:     vercelBlobAPIURL,
+		HTTPClient: http.DefaultClient,
+	}
+}
+
+// Upload grava o conteúdo em pathname, adicionando um sufixo aleatório ao nome
+func (b *VercelBlob) Upload(ctx context.Context, pathname string, data io.Reader, contentType string) (*UploadResult, error) {
+	endpoint := strings.TrimRight(b.APIURL, "/") + "/" + (&url.URL{Path: strings.TrimLeft(pathname, "/")}).EscapedPath()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, endpoint, data)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	req.Header.Set("x-api-version", "7")
+	req.Header.Set("x-add-random-suffix", "1")
+	if contentType != "" {
+		req.Header.Set("x-content-type", contentType)
+	}
+
+	resp, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vercel blob respondeu %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result UploadResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Delete remove os arquivos pelas URLs retornadas no upload
+func (b *VercelBlob) Delete(ctx context.Context, urls ...string) error {
+	if len(urls) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string][]string{"urls": urls})
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimRight(b.APIURL, "/") + "/delete"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.Token)
+	req.Header.Set("x-api-version", "7")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("vercel blob respondeu %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
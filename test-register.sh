@@ -1,16 +1,8 @@
 #!/bin/bash
 
-# Registrar usuário ADMIN
-echo "Registrando usuário ADMIN..."
-curl -X POST http://localhost:8080/api/auth/register \
-  -H "Content-Type: application/json" \
-  -d '{
-    "name": "Admin Teste",
-    "email": "admin@exemplo.com",
-    "password": "senha123",
-    "role": "ADMIN"
-  }'
-echo -e "\n\n"
+# O cadastro público cria sempre contas CLIENT.
+# EMPLOYEE e AFFILIATE exigem um código de convite emitido por um ADMIN em
+# POST /api/admin/invite-codes; ADMIN só é concedido por PUT /api/admin/users/:id/role.
 
 # Registrar usuário CLIENT
 echo "Registrando usuário CLIENT..."
@@ -19,31 +11,36 @@ curl -X POST http://localhost:8080/api/auth/register \
   -d '{
     "name": "Cliente Teste",
     "email": "cliente@exemplo.com",
-    "password": "senha123",
-    "role": "CLIENT"
+    "password": "senha123"
   }'
 echo -e "\n\n"
 
-# Registrar usuário EMPLOYEE
-echo "Registrando usuário EMPLOYEE..."
-curl -X POST http://localhost:8080/api/auth/register \
-  -H "Content-Type: application/json" \
-  -d '{
-    "name": "Funcionario Teste",
-    "email": "funcionario@exemplo.com",
-    "password": "senha123",
-    "role": "EMPLOYEE"
-  }'
-echo -e "\n\n"
+# Registrar usuário EMPLOYEE com código de convite
+# Uso: INVITE_CODE_EMPLOYEE=<código> ./test-register.sh
+if [ -n "$INVITE_CODE_EMPLOYEE" ]; then
+  echo "Registrando usuário EMPLOYEE com código de convite..."
+  curl -X POST http://localhost:8080/api/auth/register \
+    -H "Content-Type: application/json" \
+    -d '{
+      "name": "Funcionario Teste",
+      "email": "funcionario@exemplo.com",
+      "password": "senha123",
+      "inviteCode": "'"$INVITE_CODE_EMPLOYEE"'"
+    }'
+  echo -e "\n\n"
+fi
 
-# Registrar usuário AFFILIATE
-echo "Registrando usuário AFFILIATE..."
-curl -X POST http://localhost:8080/api/auth/register \
-  -H "Content-Type: application/json" \
-  -d '{
-    "name": "Afiliado Teste",
-    "email": "afiliado@exemplo.com",
-    "password": "senha123",
-    "role": "AFFILIATE"
-  }'
-echo -e "\n\n"
+# Registrar usuário AFFILIATE com código de convite
+# Uso: INVITE_CODE_AFFILIATE=<código> ./test-register.sh
+if [ -n "$INVITE_CODE_AFFILIATE" ]; then
+  echo "Registrando usuário AFFILIATE com código de convite..."
+  curl -X POST http://localhost:8080/api/auth/register \
+    -H "Content-Type: application/json" \
+    -d '{
+      "name": "Afiliado Teste",
+      "email": "afiliado@exemplo.com",
+      "password": "senha123",
+      "inviteCode": "'"$INVITE_CODE_AFFILIATE"'"
+    }'
+  echo -e "\n\n"
+fi